@@ -3,6 +3,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 
 	"github.com/spf13/cobra"
 	"go.keploy.io/server/v2/config"
@@ -20,3 +21,15 @@ func Register(name string, f HookFunc) {
 	}
 	Registered[name] = f
 }
+
+// ErrCommandFailed is returned by the commands which report their failure through the exit code, once the
+// failure is logged. keploy exits with code 1 after its cleanup.
+var ErrCommandFailed = errors.New("command failed")
+
+// commandFailed returns ErrCommandFailed without the error and the usage printed by cobra, as the failure is
+// already logged.
+func commandFailed(cmd *cobra.Command) error {
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	return ErrCommandFailed
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	coverageSvc "go.keploy.io/server/v2/pkg/service/coverage"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("coverage", Coverage)
+}
+
+// Coverage retrieves the command to inspect the code covered by the test cases
+func Coverage(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var coverageCmd = &cobra.Command{
+		Use:     "coverage",
+		Short:   "Inspect the code covered by each test case, captured by keploy test --goCoverage",
+		Example: "keploy coverage report --view test\nkeploy coverage report --view file --test-run test-run-3",
+	}
+	if err := cmdConfigurator.AddFlags(coverageCmd); err != nil {
+		utils.LogError(logger, err, "failed to add coverage cmd flags")
+		return nil
+	}
+
+	if reportCmd := CoverageReport(ctx, logger, cfg, serviceFactory, cmdConfigurator, coverageCmd); reportCmd == nil {
+		return nil
+	}
+	return coverageCmd
+}
+
+// CoverageReport retrieves the command to report the coverage of a test run per test case or per file, it is
+// added to the coverage command before its flags as they depend on the parent command.
+func CoverageReport(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator, coverageCmd *cobra.Command) *cobra.Command {
+	var reportCmd = &cobra.Command{
+		Use:     "report",
+		Short:   "Report the code covered by the test cases of a test run and the redundant test cases",
+		Example: "keploy coverage report --view test\nkeploy coverage report --testcase test-1\nkeploy coverage report --view file",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, "coverage-report")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return commandFailed(cmd)
+			}
+			coverage, ok := svc.(coverageSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy coverage service interface")
+				return commandFailed(cmd)
+			}
+			report, err := coverage.Report(ctx, cfg.CoverageReport.TestRun)
+			if err != nil {
+				utils.LogError(logger, err, "failed to get the coverage of the test cases")
+				return commandFailed(cmd)
+			}
+			if cfg.CoverageReport.View == coverageSvc.ViewFile {
+				err = report.WriteFiles(os.Stdout)
+			} else {
+				err = report.WriteTests(os.Stdout, cfg.CoverageReport.TestCase)
+			}
+			if err != nil {
+				utils.LogError(logger, err, "failed to print the coverage of the test cases")
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	coverageCmd.AddCommand(reportCmd)
+	if err := cmdConfigurator.AddFlags(reportCmd); err != nil {
+		utils.LogError(logger, err, "failed to add coverage report cmd flags")
+		return nil
+	}
+	return reportCmd
+}
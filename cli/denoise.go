@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	replaySvc "go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("denoise", Denoise)
+}
+
+// Denoise retrieves the command to propose the noise of the testcases by replaying them, and to apply it once reviewed
+func Denoise(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var denoiseCmd = &cobra.Command{
+		Use:     "denoise",
+		Short:   "replay the recorded testcases and propose the fields which differ from the recorded responses as noise",
+		Example: `keploy denoise -c "/path/to/user/app" --delay 6 && keploy denoise --apply`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.Validate(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var replay replaySvc.Service
+			var ok bool
+			if replay, ok = svc.(replaySvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy replay service interface")
+				return nil
+			}
+			if err := replay.Denoise(ctx); err != nil {
+				utils.LogError(logger, err, "failed to denoise the test cases")
+				return nil
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(denoiseCmd); err != nil {
+		utils.LogError(logger, err, "failed to add denoise cmd flags")
+		return nil
+	}
+	return denoiseCmd
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	lintSvc "go.keploy.io/server/v2/pkg/service/lint"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("lint", Lint)
+}
+
+// Lint retrieves the command to validate the recorded testcases and mocks
+func Lint(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var lintCmd = &cobra.Command{
+		Use:     "lint",
+		Short:   "Validate the yaml testcases and mocks of the test sets and report the problems as file:line diagnostics",
+		Example: "keploy lint -p ./",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return commandFailed(cmd)
+			}
+			var lint lintSvc.Service
+			var ok bool
+			if lint, ok = svc.(lintSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy lint service interface")
+				return commandFailed(cmd)
+			}
+			res, err := lint.Lint(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to lint the testcases and mocks")
+				return commandFailed(cmd)
+			}
+			if err := res.Write(os.Stdout); err != nil {
+				utils.LogError(logger, err, "failed to print the diagnostics")
+				return commandFailed(cmd)
+			}
+			// errors break the replay, so they fail the command for the CI pipelines
+			if res.Errors() > 0 {
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(lintCmd); err != nil {
+		utils.LogError(logger, err, "failed to add lint cmd flags")
+		return nil
+	}
+	return lintCmd
+}
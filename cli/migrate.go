@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	migrateSvc "go.keploy.io/server/v2/pkg/service/migrate"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("migrate", Migrate)
+}
+
+// Migrate retrieves the command to upgrade the recorded testcases and mocks to the latest schema version
+func Migrate(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var migrateCmd = &cobra.Command{
+		Use:     "migrate",
+		Short:   "Upgrade the yaml testcases and mocks of the test sets in place to the latest schema version",
+		Example: "keploy migrate -p ./ --dry-run",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get dry-run flag")
+				return err
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return commandFailed(cmd)
+			}
+			var migrate migrateSvc.Service
+			var ok bool
+			if migrate, ok = svc.(migrateSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy migrate service interface")
+				return commandFailed(cmd)
+			}
+			if err := migrate.Migrate(ctx, dryRun); err != nil {
+				utils.LogError(logger, err, "failed to migrate the testcases and mocks")
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(migrateCmd); err != nil {
+		utils.LogError(logger, err, "failed to add migrate cmd flags")
+		return nil
+	}
+	return migrateCmd
+}
@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	dedupeSvc "go.keploy.io/server/v2/pkg/service/dedupe"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("mocks", Mocks)
+}
+
+// Mocks retrieves the command to manage the recorded mocks of the test sets
+func Mocks(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var mocksCmd = &cobra.Command{
+		Use:     "mocks",
+		Short:   "Manage the recorded mocks of the test sets",
+		Example: "keploy mocks dedupe -p ./",
+	}
+	if err := cmdConfigurator.AddFlags(mocksCmd); err != nil {
+		utils.LogError(logger, err, "failed to add mocks cmd flags")
+		return nil
+	}
+
+	dedupeCmd := Dedupe(ctx, logger, cfg, serviceFactory, cmdConfigurator)
+	if dedupeCmd == nil {
+		return nil
+	}
+	mocksCmd.AddCommand(dedupeCmd)
+	return mocksCmd
+}
+
+// Dedupe retrieves the command to move the mocks shared by the test sets into the shared mock pool
+func Dedupe(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var dedupeCmd = &cobra.Command{
+		Use:     "dedupe",
+		Short:   "Move the identical mocks of the test sets into the shared mock pool and replace them with references",
+		Example: "keploy mocks dedupe -p ./ --dry-run",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get dry-run flag")
+				return err
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var dedupe dedupeSvc.Service
+			var ok bool
+			if dedupe, ok = svc.(dedupeSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy dedupe service interface")
+				return nil
+			}
+			if err := dedupe.Dedupe(ctx, dryRun); err != nil {
+				utils.LogError(logger, err, "failed to dedupe the mocks")
+				return nil
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(dedupeCmd); err != nil {
+		utils.LogError(logger, err, "failed to add dedupe cmd flags")
+		return nil
+	}
+	return dedupeCmd
+}
@@ -15,6 +15,8 @@ import (
 	"github.com/spf13/viper"
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/coverage"
+	"go.keploy.io/server/v2/pkg/service/report"
 	"go.keploy.io/server/v2/utils"
 	"go.keploy.io/server/v2/utils/log"
 	"go.uber.org/zap"
@@ -165,7 +167,7 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 
 	//add flags
 	var err error
-	switch cmd.Name() {
+	switch cmdName(cmd) {
 
 	case "update":
 		return nil
@@ -173,6 +175,48 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks/reports are stored")
 		cmd.Flags().String("test-run", "", "Test Run to be normalized")
 		cmd.Flags().String("tests", "", "Test Sets to be normalized")
+	case "storage", "report", "secrets", "mocks", "coverage":
+		return nil
+	case "coverage-report":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks/reports are stored")
+		cmd.Flags().String("test-run", "", "Test run whose coverage is reported, defaults to the latest test run with coverage data")
+		cmd.Flags().String("view", coverage.ViewTest, "View of the coverage: test lists the test cases with the lines they cover and the redundant ones, file lists the files with the test cases covering them")
+		cmd.Flags().String("testcase", "", "List only the test cases with this name in the test view, along with the lines they cover")
+	case "export":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks/reports are stored")
+		cmd.Flags().String("storage", c.cfg.Storage, "Storage used for the testcases/mocks/reports (yaml/sqlite)")
+		cmd.Flags().String("test-run", "", "Test run to be exported, defaults to the latest test run")
+		cmd.Flags().StringSlice("format", []string{"junit", "json"}, "Formats in which the report is exported (junit/json/html)")
+	case "html":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks/reports are stored")
+		cmd.Flags().String("storage", c.cfg.Storage, "Storage used for the testcases/mocks/reports (yaml/sqlite)")
+		cmd.Flags().String("test-run", "", "Test run for which the html report is generated, defaults to the latest test run")
+	case "diff":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks/reports are stored")
+		cmd.Flags().String("storage", c.cfg.Storage, "Storage used for the testcases/mocks/reports (yaml/sqlite)")
+	case "flaky":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks/reports are stored")
+		cmd.Flags().String("storage", c.cfg.Storage, "Storage used for the testcases/mocks/reports (yaml/sqlite)")
+		cmd.Flags().Int("runs", 0, "Number of latest test runs to inspect, all the test runs are inspected when it is 0")
+	case "migrate":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
+		cmd.Flags().Bool("dry-run", false, "List the testcases and mocks to be upgraded without changing them")
+	case "lint":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
+	case "dedupe":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
+		cmd.Flags().Bool("dry-run", false, "Report the mocks to be moved to the shared mock pool without changing them")
+	case "rotate":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
+		cmd.Flags().String("new-key-file", "", "File holding the new encryption key, a new key is generated into it when the file does not exist")
+	case "convert":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks/reports are stored")
+		cmd.Flags().String("from", config.StorageYaml, "Storage to convert the testcases/mocks/reports from (yaml/sqlite)")
+		cmd.Flags().String("to", config.StorageSQLite, "Storage to convert the testcases/mocks/reports to (yaml/sqlite)")
 	case "config":
 		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated config is stored")
 		cmd.Flags().Bool("generate", false, "Generate a new keploy configuration file")
@@ -206,7 +250,7 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 			utils.LogError(c.logger, err, errMsg)
 			return errors.New(errMsg)
 		}
-	case "record", "test":
+	case "record", "test", "denoise":
 		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
 		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
 		cmd.Flags().Uint32("port", c.cfg.Port, "GraphQL server port used for executing testcases in unit test library integration")
@@ -220,13 +264,14 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 		cmd.Flags().UintSlice("passThroughPorts", config.GetByPassPorts(c.cfg), "Ports to bypass the proxy server and ignore the traffic")
 		cmd.Flags().StringP("appId", "a", c.cfg.AppID, "A unique name for the user's application")
 		cmd.Flags().Bool("generateGithubActions", c.cfg.GenerateGithubActions, "Generate Github Actions workflow file")
+		cmd.Flags().String("storage", c.cfg.Storage, "Storage used for the testcases/mocks/reports (yaml/sqlite)")
 		err = cmd.Flags().MarkHidden("port")
 		if err != nil {
 			errMsg := "failed to mark port as hidden flag"
 			utils.LogError(c.logger, err, errMsg)
 			return errors.New(errMsg)
 		}
-		if cmd.Name() == "test" {
+		if cmd.Name() == "test" || cmd.Name() == "denoise" {
 			cmd.Flags().StringSliceP("testsets", "t", utils.Keys(c.cfg.Test.SelectedTests), "Testsets to run e.g. --testsets \"test-set-1, test-set-2\"")
 			cmd.Flags().Uint64P("delay", "d", 5, "User provided time to run its application")
 			cmd.Flags().Uint64("apiTimeout", c.cfg.Test.APITimeout, "User provided timeout for calling its application")
@@ -234,15 +279,28 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 			cmd.Flags().String("coverageReportPath", c.cfg.Test.CoverageReportPath, "Write a go coverage profile to the file in the given directory.")
 			cmd.Flags().StringP("language", "l", c.cfg.Test.Language, "application programming language")
 			cmd.Flags().Bool("ignoreOrdering", c.cfg.Test.IgnoreOrdering, "Ignore ordering of array in response")
+			cmd.Flags().Bool("schemaMatch", c.cfg.Test.SchemaMatch, "Compare only the structure, keys and value types of the json responses instead of their values")
 			cmd.Flags().Bool("coverage", c.cfg.Test.Coverage, "Enable coverage reporting for the testcases. for golang please set language flag to golang, ref https://keploy.io/docs/server/sdk-installation/go/")
 			cmd.Flags().Bool("removeUnusedMocks", c.cfg.Test.RemoveUnusedMocks, "Clear the unused mocks for the passed test-sets")
 			cmd.Flags().Bool("goCoverage", c.cfg.Test.GoCoverage, "Enable go coverage reporting for the testcases")
 			cmd.Flags().Bool("fallBackOnMiss", c.cfg.Test.FallBackOnMiss, "Enable connecting to actual service if mock not found during test mode")
 			cmd.Flags().String("basePath", c.cfg.Test.BasePath, "Custom api basePath/origin to replace the actual basePath/origin in the testcases; App flag is ignored and app will not be started & instrumented when this is set since the application running on a different machine")
 			cmd.Flags().Bool("mocking", true, "enable/disable mocking for the testcases")
+			cmd.Flags().StringSlice("report-format", c.cfg.Test.ReportFormats, "Export the test run report in the given formats along with the yaml report e.g. --report-format junit,json")
+			cmd.Flags().Int("retries", c.cfg.Test.Retries, "Number of times a failing test case is re-run against the same mocks, the test cases passing on a retry are reported as flaky")
+			cmd.Flags().String("depStrictness", c.cfg.Test.DepStrictness, "Outgoing call differences which fail a test case: none reports them only, unexpected fails on the calls matching no mock, all also fails on the recorded mocks which are not consumed")
+			cmd.Flags().Bool("strictMocks", c.cfg.Test.StrictMocks, "Fail the outgoing calls which match no mock instead of falling back to the actual service, and the test cases making them with an unmocked dependency call in the report")
+			cmd.Flags().Float64("latency-factor", c.cfg.Test.Latency.Factor, "Delay the responses of the mocks by their recorded latency scaled by the factor, e.g. 1 replays the recorded latency and 0.5 half of it")
+			cmd.Flags().String("changed-since", c.cfg.Test.ChangedSince, "Run only the test cases covering the go code changed since the git ref, and the ones without coverage data. The coverage of the test cases is captured by keploy test --goCoverage on a clean checkout of the ref")
+			cmd.Flags().Int("parallel", c.cfg.Test.Parallel, "Number of test sets to run at once, each with its own instance of the app. Instance N gets the KEPLOY_WORKER_ID=N env variable and is sent the requests on the recorded port + N")
+			if cmd.Name() == "denoise" {
+				cmd.Flags().Bool("apply", c.cfg.Denoise.Apply, "Add the reviewed noise in the denoise section of the test set configs to the testcases, the app is not run")
+			}
 		} else {
 			cmd.Flags().Uint64("recordTimer", 0, "User provided time to record its application")
 			cmd.Flags().StringP("rerecord", "r", c.cfg.Record.ReRecord, "Rerecord the testcases/mocks for the given testset(s)")
+			cmd.Flags().Bool("denoise", c.cfg.Record.Denoise, "Replay the captured GET, HEAD and OPTIONS requests a second time and propose the fields which differ between the two responses as noise, for review")
+			cmd.Flags().StringSlice("denoiseMethods", c.cfg.Record.DenoiseMethods, "Methods of the captured requests replayed by --denoise besides GET, HEAD and OPTIONS e.g. --denoiseMethods PUT. Their side effects, like a second insert, are repeated on the services of the app")
 		}
 	case "keploy":
 		cmd.PersistentFlags().Bool("debug", c.cfg.Debug, "Run in debug mode")
@@ -304,7 +362,17 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		utils.LogError(c.logger, err, errMsg)
 		return errors.New(errMsg)
 	}
-	if cmd.Name() == "test" || cmd.Name() == "record" {
+	// the denoise command replays the testcases like the test command, its flags configure the test run
+	if cmd.Name() == "denoise" {
+		err = utils.BindFlagsToViper(c.logger, cmd, "test")
+		if err != nil {
+			errMsg := "failed to bind denoise flags to the test config"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	// the commands rewriting the testcases and mocks read the config file for the encryption settings
+	if cmd.Name() == "test" || cmd.Name() == "denoise" || cmd.Name() == "record" || cmd.Name() == "migrate" || cmd.Name() == "rotate" || cmd.Name() == "dedupe" || cmd.Name() == "lint" {
 		configPath, err := cmd.Flags().GetString("configPath")
 		if err != nil {
 			utils.LogError(c.logger, nil, "failed to read the config path")
@@ -363,8 +431,21 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 
 	c.logger.Debug("config has been initialised", zap.Any("for cmd", cmd.Name()), zap.Any("config", c.cfg))
 
-	switch cmd.Name() {
-	case "record", "test":
+	switch cmdName(cmd) {
+	case "record", "test", "denoise":
+
+		if cmd.Name() == "denoise" && c.cfg.Denoise.Apply {
+			// the reviewed noise is added to the testcases without running the app
+			c.cfg.Path = c.keployPath(c.cfg.Path)
+			testSets, err := cmd.Flags().GetStringSlice("testsets")
+			if err != nil {
+				errMsg := "failed to get the testsets"
+				utils.LogError(c.logger, err, errMsg)
+				return errors.New(errMsg)
+			}
+			config.SetSelectedTests(c.cfg, testSets)
+			break
+		}
 
 		// handle the app command
 		if c.cfg.Command == "" {
@@ -441,7 +522,7 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		}
 		config.SetByPassPorts(c.cfg, bypassPorts)
 
-		if cmd.Name() == "test" {
+		if cmd.Name() == "test" || cmd.Name() == "denoise" {
 			//check if the keploy folder exists
 			if _, err := os.Stat(c.cfg.Path); os.IsNotExist(err) {
 				recordCmd := models.HighlightGrayString("keploy record")
@@ -458,6 +539,73 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 			}
 			config.SetSelectedTests(c.cfg, testSets)
 
+			reportFormats, err := cmd.Flags().GetStringSlice("report-format")
+			if err != nil {
+				errMsg := "failed to get the report formats"
+				utils.LogError(c.logger, err, errMsg)
+				return errors.New(errMsg)
+			}
+			if cmd.Flags().Changed("report-format") {
+				c.cfg.Test.ReportFormats = reportFormats
+			}
+			if err := report.ValidateFormats(c.cfg.Test.ReportFormats); err != nil {
+				utils.LogError(c.logger, err, "invalid report format")
+				return err
+			}
+
+			if c.cfg.Test.Retries < 0 {
+				errMsg := "the number of retries can't be negative"
+				utils.LogError(c.logger, nil, errMsg, zap.Int("retries", c.cfg.Test.Retries))
+				return errors.New(errMsg)
+			}
+
+			latencyFactor, err := cmd.Flags().GetFloat64("latency-factor")
+			if err != nil {
+				errMsg := "failed to get the latency factor"
+				utils.LogError(c.logger, err, errMsg)
+				return errors.New(errMsg)
+			}
+			if cmd.Flags().Changed("latency-factor") {
+				c.cfg.Test.Latency.Enable = true
+				c.cfg.Test.Latency.Factor = latencyFactor
+			}
+			for kind, factor := range c.cfg.Test.Latency.Kinds {
+				if factor < 0 {
+					errMsg := "the latency factors can't be negative"
+					utils.LogError(c.logger, nil, errMsg, zap.String("kind", kind), zap.Float64("factor", factor))
+					return errors.New(errMsg)
+				}
+			}
+			if c.cfg.Test.Latency.Factor < 0 {
+				errMsg := "the latency factors can't be negative"
+				utils.LogError(c.logger, nil, errMsg, zap.Float64("factor", c.cfg.Test.Latency.Factor))
+				return errors.New(errMsg)
+			}
+
+			switch c.cfg.Test.DepStrictness {
+			case "", config.DepStrictnessNone, config.DepStrictnessUnexpected, config.DepStrictnessAll:
+			default:
+				errMsg := "the dependency strictness must be one of none, unexpected and all"
+				utils.LogError(c.logger, nil, errMsg, zap.String("depStrictness", c.cfg.Test.DepStrictness))
+				return errors.New(errMsg)
+			}
+
+			changedSince, err := cmd.Flags().GetString("changed-since")
+			if err != nil {
+				errMsg := "failed to get the git ref of the changes"
+				utils.LogError(c.logger, err, errMsg)
+				return errors.New(errMsg)
+			}
+			if cmd.Flags().Changed("changed-since") {
+				c.cfg.Test.ChangedSince = changedSince
+			}
+
+			if c.cfg.Test.Parallel < 1 {
+				errMsg := "the number of parallel test sets must be at least 1"
+				utils.LogError(c.logger, nil, errMsg, zap.Int("parallel", c.cfg.Test.Parallel))
+				return errors.New(errMsg)
+			}
+
 			if utils.CmdType(c.cfg.CommandType) == utils.Native && c.cfg.Test.GoCoverage {
 				goCovPath, err := utils.SetCoveragePath(c.logger, c.cfg.Test.CoverageReportPath)
 				if err != nil {
@@ -477,23 +625,7 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 			}
 		}
 	case "normalize":
-		path := c.cfg.Path
-		//if user provides relative path
-		if len(path) > 0 && path[0] != '/' {
-			absPath, err := filepath.Abs(path)
-			if err != nil {
-				utils.LogError(c.logger, err, "failed to get the absolute path from relative path")
-			}
-			path = absPath
-		} else if len(path) == 0 { // if user doesn't provide any path
-			cdirPath, err := os.Getwd()
-			if err != nil {
-				utils.LogError(c.logger, err, "failed to get the path of current directory")
-			}
-			path = cdirPath
-		}
-		path += "/keploy"
-		c.cfg.Path = path
+		c.cfg.Path = c.keployPath(c.cfg.Path)
 		tests, err := cmd.Flags().GetString("tests")
 		if err != nil {
 			errMsg := "failed to read tests to be normalized"
@@ -506,6 +638,70 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 			utils.LogError(c.logger, err, errMsg)
 			return errors.New(errMsg)
 		}
+	case "export":
+		c.cfg.Path = c.keployPath(c.cfg.Path)
+		testRun, err := cmd.Flags().GetString("test-run")
+		if err != nil {
+			errMsg := "failed to read the test run to be exported"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		c.cfg.Report.TestRun = testRun
+		formats, err := cmd.Flags().GetStringSlice("format")
+		if err != nil {
+			errMsg := "failed to read the report formats"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		if err := report.ValidateFormats(formats); err != nil {
+			utils.LogError(c.logger, err, "invalid report format")
+			return err
+		}
+		c.cfg.Report.Formats = formats
+	case "html":
+		c.cfg.Path = c.keployPath(c.cfg.Path)
+		testRun, err := cmd.Flags().GetString("test-run")
+		if err != nil {
+			errMsg := "failed to read the test run of the html report"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		c.cfg.Report.TestRun = testRun
+	case "diff", "flaky", "migrate", "rotate", "dedupe", "lint":
+		c.cfg.Path = c.keployPath(c.cfg.Path)
+	case "coverage-report":
+		c.cfg.Path = c.keployPath(c.cfg.Path)
+		testRun, err := cmd.Flags().GetString("test-run")
+		if err != nil {
+			errMsg := "failed to read the test run of the coverage report"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		view, err := cmd.Flags().GetString("view")
+		if err != nil {
+			errMsg := "failed to read the view of the coverage report"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		if view != coverage.ViewTest && view != coverage.ViewFile {
+			errMsg := fmt.Sprintf("invalid view %q of the coverage report, it must be %s or %s", view, coverage.ViewTest, coverage.ViewFile)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+		testCase, err := cmd.Flags().GetString("testcase")
+		if err != nil {
+			errMsg := "failed to read the test case of the coverage report"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		c.cfg.CoverageReport = config.Coverage{TestRun: testRun, View: view, TestCase: testCase}
+	case "convert":
+		c.cfg.Path = c.keployPath(c.cfg.Path)
+		if c.cfg.Convert.From == c.cfg.Convert.To {
+			errMsg := "source and destination storage of the conversion must be different"
+			utils.LogError(c.logger, nil, errMsg, zap.String("from", c.cfg.Convert.From), zap.String("to", c.cfg.Convert.To))
+			return errors.New(errMsg)
+		}
 	case "gen":
 		if os.Getenv("API_KEY") == "" {
 			utils.LogError(c.logger, nil, "API_KEY is not set")
@@ -523,3 +719,31 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 	}
 	return nil
 }
+
+// cmdName returns the name with which the flags of the command are added and validated, the report subcommand
+// of the coverage command is told apart from the report command.
+func cmdName(cmd *cobra.Command) string {
+	if cmd.HasParent() && cmd.Parent().Name() == "coverage" {
+		return "coverage-" + cmd.Name()
+	}
+	return cmd.Name()
+}
+
+// keployPath returns the absolute path of the keploy directory inside the given path
+func (c *CmdConfigurator) keployPath(path string) string {
+	//if user provides relative path
+	if len(path) > 0 && path[0] != '/' {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to get the absolute path from relative path")
+		}
+		path = absPath
+	} else if len(path) == 0 { // if user doesn't provide any path
+		cdirPath, err := os.Getwd()
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to get the path of current directory")
+		}
+		path = cdirPath
+	}
+	return path + "/keploy"
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/docker/docker/api/types"
 	"go.keploy.io/server/v2/config"
@@ -15,35 +16,101 @@ import (
 	"go.keploy.io/server/v2/pkg/core/tester"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/pkg/platform/docker"
+	"go.keploy.io/server/v2/pkg/platform/sqlite"
+	sqliteMockdb "go.keploy.io/server/v2/pkg/platform/sqlite/mockdb"
+	sqliteReportdb "go.keploy.io/server/v2/pkg/platform/sqlite/reportdb"
+	sqliteTestdb "go.keploy.io/server/v2/pkg/platform/sqlite/testdb"
 	"go.keploy.io/server/v2/pkg/platform/telemetry"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
 	"go.keploy.io/server/v2/pkg/platform/yaml/configdb/testset"
+	"go.keploy.io/server/v2/pkg/platform/yaml/coveragedb"
 	mockdb "go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
 	reportdb "go.keploy.io/server/v2/pkg/platform/yaml/reportdb"
 	testdb "go.keploy.io/server/v2/pkg/platform/yaml/testdb"
+	"go.keploy.io/server/v2/pkg/service/coverage"
+	"go.keploy.io/server/v2/pkg/service/dedupe"
+	"go.keploy.io/server/v2/pkg/service/lint"
+	"go.keploy.io/server/v2/pkg/service/migrate"
 	"go.keploy.io/server/v2/pkg/service/record"
 	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/pkg/service/report"
+	"go.keploy.io/server/v2/pkg/service/secrets"
+	"go.keploy.io/server/v2/pkg/service/storage"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
 
+// TestDB, MockDB and ReportDB are implemented by every storage backend (yaml and sqlite)
+type TestDB interface {
+	record.TestDB
+	replay.TestDB
+	storage.TestDB
+}
+
+type MockDB interface {
+	record.MockDB
+	replay.MockDB
+	storage.MockDB
+}
+
+type ReportDB interface {
+	replay.ReportDB
+	report.ReportDB
+	storage.ReportDB
+}
+
 type CommonInternalService struct {
-	YamlTestDB      *testdb.TestYaml
-	YamlMockDb      *mockdb.MockYaml
-	YamlReportDb    *reportdb.TestReport
-	YamlTestSetDB   *testset.Db[*models.TestSet]
+	TestDB        TestDB
+	MockDB        MockDB
+	ReportDB      ReportDB
+	YamlTestSetDB *testset.Db[*models.TestSet]
+	// CoverageDB is a yaml storage whatever the storage backend, the coverage is written along with the reports
+	CoverageDB      *coveragedb.CoverageDB
 	Instrumentation *core.Core
 }
 
 func Get(ctx context.Context, cmd string, cfg *config.Config, logger *zap.Logger, tel *telemetry.Telemetry) (interface{}, error) {
+	if cmd == "convert" {
+		return getStorageService(ctx, cfg, logger)
+	}
+	if cmd == "export" || cmd == "html" || cmd == "diff" || cmd == "flaky" {
+		store, err := getStore(ctx, cfg, cfg.Storage, logger)
+		if err != nil {
+			return nil, err
+		}
+		return report.New(logger, store.ReportDB, cfg.Path+"/reports"), nil
+	}
+	if cmd == "coverage-report" {
+		return coverage.New(logger, coveragedb.New(logger, cfg.Path+"/reports")), nil
+	}
+	if cmd == "migrate" || cmd == "rotate" || cmd == "dedupe" || cmd == "lint" {
+		cipher, err := getCipher(cfg)
+		if err != nil {
+			return nil, err
+		}
+		// the schema migration, the key rotation, the mock deduplication and the linter work on the yaml files, the
+		// sqlite storage upgrades the documents when reading them and has no encryption or shared mock pool
+		testDB, mockDB := testdb.New(logger, cfg.Path, cipher), mockdb.New(logger, cfg.Path, "", cipher)
+		switch cmd {
+		case "lint":
+			// the decode errors are reported as diagnostics instead of being logged
+			return lint.New(logger, testdb.New(zap.NewNop(), cfg.Path, cipher), mockdb.New(zap.NewNop(), cfg.Path, "", cipher)), nil
+		case "rotate":
+			return secrets.New(logger, testDB, mockDB), nil
+		case "dedupe":
+			return dedupe.New(logger, testDB, mockDB), nil
+		}
+		return migrate.New(logger, testDB, mockDB), nil
+	}
 	commonServices, err := GetCommonServices(ctx, cfg, logger)
 	if err != nil {
 		return nil, err
 	}
 	if cmd == "record" {
-		return record.New(logger, commonServices.YamlTestDB, commonServices.YamlMockDb, tel, commonServices.Instrumentation, cfg), nil
+		return record.New(logger, commonServices.TestDB, commonServices.MockDB, commonServices.YamlTestSetDB, tel, commonServices.Instrumentation, cfg), nil
 	}
-	if cmd == "test" || cmd == "normalize" {
-		return replay.NewReplayer(logger, commonServices.YamlTestDB, commonServices.YamlMockDb, commonServices.YamlReportDb, commonServices.YamlTestSetDB, tel, commonServices.Instrumentation, cfg), nil
+	if cmd == "test" || cmd == "normalize" || cmd == "denoise" {
+		return replay.NewReplayer(logger, commonServices.TestDB, commonServices.MockDB, commonServices.ReportDB, commonServices.CoverageDB, commonServices.YamlTestSetDB, tel, commonServices.Instrumentation, cfg), nil
 	}
 	return nil, errors.New("invalid command")
 }
@@ -91,19 +158,90 @@ func GetCommonServices(ctx context.Context, c *config.Config, logger *zap.Logger
 	}
 
 	instrumentation := core.New(logger, h, p, t, client)
-	testDB := testdb.New(logger, c.Path)
-	mockDB := mockdb.New(logger, c.Path, "")
-	reportDB := reportdb.New(logger, c.Path+"/reports")
+	store, err := getStore(ctx, c, c.Storage, logger)
+	if err != nil {
+		return nil, err
+	}
 	testSetDb := testset.New[*models.TestSet](logger, c.Path)
 	return &CommonInternalService{
 		Instrumentation: instrumentation,
-		YamlTestDB:      testDB,
-		YamlMockDb:      mockDB,
-		YamlReportDb:    reportDB,
+		TestDB:          store.TestDB,
+		MockDB:          store.MockDB,
+		ReportDB:        store.ReportDB,
 		YamlTestSetDB:   testSetDb,
+		CoverageDB:      coveragedb.New(logger, c.Path+"/reports"),
 	}, nil
 }
 
+type commonStore struct {
+	TestDB   TestDB
+	MockDB   MockDB
+	ReportDB ReportDB
+}
+
+// getStore returns the databases of the given storage backend
+func getStore(ctx context.Context, cfg *config.Config, storageType string, logger *zap.Logger) (*commonStore, error) {
+	path := cfg.Path
+	switch storageType {
+	case "", config.StorageYaml:
+		cipher, err := getCipher(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &commonStore{
+			TestDB:   testdb.New(logger, path, cipher),
+			MockDB:   mockdb.New(logger, path, "", cipher),
+			ReportDB: reportdb.New(logger, path+"/reports"),
+		}, nil
+	case config.StorageSQLite:
+		if cipher, _ := getCipher(cfg); cipher != nil {
+			logger.Warn("encryption at rest is supported by the yaml storage only, the sqlite storage is not encrypted")
+		}
+		db, err := sqlite.Open(ctx, logger, path)
+		if err != nil {
+			utils.LogError(logger, err, "failed to open the sqlite storage")
+			return nil, err
+		}
+		return &commonStore{
+			TestDB:   sqliteTestdb.New(logger, db),
+			MockDB:   sqliteMockdb.New(logger, db),
+			ReportDB: sqliteReportdb.New(logger, db),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage: %s, supported storages are %s and %s", storageType, config.StorageYaml, config.StorageSQLite)
+	}
+}
+
+// getCipher returns the cipher of the yaml storage, it is nil when the encryption is disabled. Setting the
+// key env variable enables the encryption as well, so that it can be used without a config file.
+func getCipher(cfg *config.Config) (*yaml.Cipher, error) {
+	enc := cfg.Encryption
+	if !enc.Enable && (enc.KeyEnv == "" || os.Getenv(enc.KeyEnv) == "") {
+		return nil, nil
+	}
+	key, err := yaml.LoadKey(enc.KeyEnv, enc.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.NewCipher(key)
+}
+
+func getStorageService(ctx context.Context, cfg *config.Config, logger *zap.Logger) (storage.Service, error) {
+	stores := make(map[string]storage.Store)
+	for _, storageType := range []string{config.StorageYaml, config.StorageSQLite} {
+		store, err := getStore(ctx, cfg, storageType, logger)
+		if err != nil {
+			return nil, err
+		}
+		stores[storageType] = storage.Store{
+			TestDB:   store.TestDB,
+			MockDB:   store.MockDB,
+			ReportDB: store.ReportDB,
+		}
+	}
+	return storage.New(logger, stores), nil
+}
+
 func addKeployNetwork(ctx context.Context, logger *zap.Logger, client docker.Client) {
 
 	// Check if the 'keploy-network' network exists
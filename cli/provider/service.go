@@ -53,7 +53,7 @@ func (n *ServiceProvider) GetService(ctx context.Context, cmd string) (interface
 		return tools.NewTools(n.logger, tel), nil
 	case "gen":
 		return utgen.NewUnitTestGenerator(n.cfg.Gen.SourceFilePath, n.cfg.Gen.TestFilePath, n.cfg.Gen.CoverageReportPath, n.cfg.Gen.TestCommand, n.cfg.Gen.TestDir, n.cfg.Gen.CoverageFormat, n.cfg.Gen.DesiredCoverage, n.cfg.Gen.MaxIterations, n.cfg.Gen.Model, n.cfg.Gen.APIBaseURL, n.cfg.Gen.APIVersion, n.cfg, tel, n.logger)
-	case "record", "test", "mock", "normalize":
+	case "record", "test", "denoise", "mock", "normalize", "convert", "export", "html", "diff", "flaky", "migrate", "rotate", "dedupe", "lint", "coverage-report":
 		return Get(ctx, cmd, n.cfg, n.logger, tel)
 	default:
 		return nil, errors.New("invalid command")
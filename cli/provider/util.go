@@ -18,5 +18,5 @@ func (c *CmdConfigurator) noCommandError() error {
 
 // alreadyRunning checks that during test mode, if user provides the basePath, then it implies that the application is already running somewhere.
 func alreadyRunning(cmd, basePath string) bool {
-	return (cmd == "test" && basePath != "")
+	return ((cmd == "test" || cmd == "denoise") && basePath != "")
 }
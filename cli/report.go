@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	reportSvc "go.keploy.io/server/v2/pkg/service/report"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("report", Report)
+}
+
+// Report retrieves the command to work with the reports of the test runs
+func Report(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var reportCmd = &cobra.Command{
+		Use:     "report",
+		Short:   "Export and inspect the reports of the test runs",
+		Example: "keploy report export --test-run test-run-1 --format junit,json\nkeploy report html --test-run test-run-1\nkeploy report diff test-run-12 test-run-13\nkeploy report flaky --runs 10",
+	}
+	if err := cmdConfigurator.AddFlags(reportCmd); err != nil {
+		utils.LogError(logger, err, "failed to add report cmd flags")
+		return nil
+	}
+
+	for _, sub := range []HookFunc{Export, HTML, Diff, Flaky} {
+		subCmd := sub(ctx, logger, cfg, serviceFactory, cmdConfigurator)
+		if subCmd == nil {
+			return nil
+		}
+		reportCmd.AddCommand(subCmd)
+	}
+	return reportCmd
+}
+
+// Export retrieves the command to export the report of a test run
+func Export(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var exportCmd = &cobra.Command{
+		Use:     "export",
+		Short:   "Export the report of a test run as JUnit XML or JSON",
+		Example: "keploy report export --test-run test-run-1 --format junit,json",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			report, err := getReportService(ctx, logger, serviceFactory, cmd.Name())
+			if err != nil {
+				return commandFailed(cmd)
+			}
+			if err := report.Export(ctx, cfg.Report.TestRun, cfg.Report.Formats); err != nil {
+				utils.LogError(logger, err, "failed to export the report")
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(exportCmd); err != nil {
+		utils.LogError(logger, err, "failed to add export cmd flags")
+		return nil
+	}
+	return exportCmd
+}
+
+// HTML retrieves the command to generate the html report of a test run
+func HTML(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var htmlCmd = &cobra.Command{
+		Use:     "html",
+		Short:   "Generate a self-contained html report of a test run which can be viewed offline",
+		Example: "keploy report html --test-run test-run-1",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			report, err := getReportService(ctx, logger, serviceFactory, cmd.Name())
+			if err != nil {
+				return commandFailed(cmd)
+			}
+			if err := report.Export(ctx, cfg.Report.TestRun, []string{"html"}); err != nil {
+				utils.LogError(logger, err, "failed to generate the html report")
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(htmlCmd); err != nil {
+		utils.LogError(logger, err, "failed to add html cmd flags")
+		return nil
+	}
+	return htmlCmd
+}
+
+// Diff retrieves the command to compare two test runs
+func Diff(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var diffCmd = &cobra.Command{
+		Use:     "diff <base-test-run> <head-test-run>",
+		Short:   "Compare two test runs and list the newly failing, newly passing, changed, added and removed test cases",
+		Example: "keploy report diff test-run-12 test-run-13",
+		Args:    cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report, err := getReportService(ctx, logger, serviceFactory, cmd.Name())
+			if err != nil {
+				return commandFailed(cmd)
+			}
+			diff, err := report.Diff(ctx, args[0], args[1])
+			if err != nil {
+				utils.LogError(logger, err, "failed to compare the test runs")
+				return commandFailed(cmd)
+			}
+			if err := diff.WriteSummary(os.Stdout); err != nil {
+				utils.LogError(logger, err, "failed to print the comparison of the test runs")
+				return commandFailed(cmd)
+			}
+			if diff.HasRegressions() {
+				logger.Error("found regressions in the head test run", zap.String("base", args[0]), zap.String("head", args[1]), zap.Int("newlyFailing", diff.Summary.NewlyFailing))
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(diffCmd); err != nil {
+		utils.LogError(logger, err, "failed to add diff cmd flags")
+		return nil
+	}
+	return diffCmd
+}
+
+// Flaky retrieves the command to list the flaky test cases of the historical test runs
+func Flaky(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var flakyCmd = &cobra.Command{
+		Use:     "flaky",
+		Short:   "List the test cases whose result flips between the test runs or which passed only on a retry",
+		Example: "keploy report flaky --runs 10",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			runs, err := cmd.Flags().GetInt("runs")
+			if err != nil {
+				utils.LogError(logger, err, "failed to read the number of test runs")
+				return commandFailed(cmd)
+			}
+			report, err := getReportService(ctx, logger, serviceFactory, cmd.Name())
+			if err != nil {
+				return commandFailed(cmd)
+			}
+			flaky, err := report.Flaky(ctx, runs)
+			if err != nil {
+				utils.LogError(logger, err, "failed to find the flaky test cases")
+				return commandFailed(cmd)
+			}
+			if err := flaky.WriteSummary(os.Stdout); err != nil {
+				utils.LogError(logger, err, "failed to print the flaky test cases")
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(flakyCmd); err != nil {
+		utils.LogError(logger, err, "failed to add flaky cmd flags")
+		return nil
+	}
+	return flakyCmd
+}
+
+func getReportService(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory, cmd string) (reportSvc.Service, error) {
+	svc, err := serviceFactory.GetService(ctx, cmd)
+	if err != nil {
+		utils.LogError(logger, err, "failed to get service")
+		return nil, err
+	}
+	report, ok := svc.(reportSvc.Service)
+	if !ok {
+		utils.LogError(logger, nil, "service doesn't satisfy report service interface")
+		return nil, errors.New("invalid report service")
+	}
+	return report, nil
+}
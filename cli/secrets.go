@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	secretsSvc "go.keploy.io/server/v2/pkg/service/secrets"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("secrets", Secrets)
+}
+
+// Secrets retrieves the command to manage the key which encrypts the recorded testcases and mocks
+func Secrets(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var secretsCmd = &cobra.Command{
+		Use:     "secrets",
+		Short:   "Manage the key which encrypts the recorded testcases and mocks",
+		Example: "keploy secrets rotate --new-key-file ./keploy.key",
+	}
+	if err := cmdConfigurator.AddFlags(secretsCmd); err != nil {
+		utils.LogError(logger, err, "failed to add secrets cmd flags")
+		return nil
+	}
+
+	rotateCmd := Rotate(ctx, logger, cfg, serviceFactory, cmdConfigurator)
+	if rotateCmd == nil {
+		return nil
+	}
+	secretsCmd.AddCommand(rotateCmd)
+	return secretsCmd
+}
+
+// Rotate retrieves the command to re-encrypt the recorded testcases and mocks with a new key
+func Rotate(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var rotateCmd = &cobra.Command{
+		Use:     "rotate",
+		Short:   "Re-encrypt the yaml testcases and mocks with a new key, the ones in clear text are encrypted as well",
+		Example: "keploy secrets rotate -p ./ --new-key-file ./keploy-new.key",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			newKeyFile, err := cmd.Flags().GetString("new-key-file")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get new-key-file flag")
+				return err
+			}
+			if newKeyFile == "" {
+				err := errors.New("the file of the new key is required")
+				utils.LogError(logger, err, "missing --new-key-file flag")
+				return err
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return commandFailed(cmd)
+			}
+			var secrets secretsSvc.Service
+			var ok bool
+			if secrets, ok = svc.(secretsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy secrets service interface")
+				return commandFailed(cmd)
+			}
+			if err := secrets.Rotate(ctx, newKeyFile); err != nil {
+				utils.LogError(logger, err, "failed to rotate the encryption key")
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(rotateCmd); err != nil {
+		utils.LogError(logger, err, "failed to add rotate cmd flags")
+		return nil
+	}
+	return rotateCmd
+}
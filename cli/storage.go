@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	storageSvc "go.keploy.io/server/v2/pkg/service/storage"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("storage", Storage)
+}
+
+// Storage retrieves the command to manage the storage of the recorded testcases/mocks/reports
+func Storage(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var storageCmd = &cobra.Command{
+		Use:     "storage",
+		Short:   "Manage the storage of the recorded testcases, mocks and reports",
+		Example: "keploy storage convert --from yaml --to sqlite",
+	}
+	if err := cmdConfigurator.AddFlags(storageCmd); err != nil {
+		utils.LogError(logger, err, "failed to add storage cmd flags")
+		return nil
+	}
+
+	convertCmd := Convert(ctx, logger, cfg, serviceFactory, cmdConfigurator)
+	if convertCmd == nil {
+		return nil
+	}
+	storageCmd.AddCommand(convertCmd)
+	return storageCmd
+}
+
+// Convert retrieves the command to convert the recorded data from one storage to the other
+func Convert(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var convertCmd = &cobra.Command{
+		Use:     "convert",
+		Short:   "Convert the testcases, mocks and reports from one storage to the other",
+		Example: "keploy storage convert --from yaml --to sqlite -p ./",
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return commandFailed(cmd)
+			}
+			var storage storageSvc.Service
+			var ok bool
+			if storage, ok = svc.(storageSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy storage service interface")
+				return commandFailed(cmd)
+			}
+			if err := storage.Convert(ctx, cfg.Convert.From, cfg.Convert.To); err != nil {
+				utils.LogError(logger, err, "failed to convert the storage")
+				return commandFailed(cmd)
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(convertCmd); err != nil {
+		utils.LogError(logger, err, "failed to add convert cmd flags")
+		return nil
+	}
+	return convertCmd
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/graph"
+	"go.keploy.io/server/v2/pkg/service/coverage/agent"
 	replaySvc "go.keploy.io/server/v2/pkg/service/replay"
 	"go.uber.org/zap"
 )
@@ -53,6 +54,14 @@ func Test(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFa
 					utils.LogError(logger, err, "failed to set GOCOVERDIR")
 					return nil
 				}
+				// the coverage agent started by the app lets keploy capture the coverage of every test case
+				if os.Getenv(agent.AddrEnv) == "" {
+					err = os.Setenv(agent.AddrEnv, agent.DefaultAddr)
+					if err != nil {
+						utils.LogError(logger, err, "failed to set the address of the coverage agent")
+						return nil
+					}
+				}
 			}
 
 			err = replay.Start(ctx)
@@ -25,6 +25,11 @@ type Config struct {
 	Record                Record       `json:"record" yaml:"record" mapstructure:"record"`
 	Gen                   UtGen        `json:"gen" yaml:"gen" mapstructure:"gen"`
 	Normalize             Normalize    `json:"normalize" yaml:"normalize" mapstructure:"normalize"`
+	Denoise               Denoise      `json:"denoise" yaml:"denoise" mapstructure:"denoise"`
+	Convert               Convert      `json:"convert" yaml:"convert" mapstructure:"convert"`
+	Report                Report       `json:"report" yaml:"report" mapstructure:"report"`
+	CoverageReport        Coverage     `json:"coverageReport" yaml:"coverageReport" mapstructure:"coverageReport"`
+	Encryption            Encryption   `json:"encryption" yaml:"encryption" mapstructure:"encryption"`
 	ConfigPath            string       `json:"configPath" yaml:"configPath" mapstructure:"configPath"`
 	BypassRules           []BypassRule `json:"bypassRules" yaml:"bypassRules" mapstructure:"bypassRules"`
 	EnableTesting         bool         `json:"enableTesting" yaml:"enableTesting" mapstructure:"enableTesting"`
@@ -32,8 +37,25 @@ type Config struct {
 	KeployContainer       string       `json:"keployContainer" yaml:"keployContainer" mapstructure:"keployContainer"`
 	KeployNetwork         string       `json:"keployNetwork" yaml:"keployNetwork" mapstructure:"keployNetwork"`
 	CommandType           string       `json:"cmdType" yaml:"cmdType" mapstructure:"cmdType"`
+	Storage               string       `json:"storage" yaml:"storage" mapstructure:"storage"` // backend used to store testcases, mocks and reports (yaml/sqlite)
 }
 
+// constants for the storage backends
+const (
+	StorageYaml   = "yaml"
+	StorageSQLite = "sqlite"
+)
+
+// strictness of the check of the outgoing calls of the test cases against their mocks
+const (
+	// DepStrictnessNone reports the differences of the outgoing calls without failing the test cases
+	DepStrictnessNone = "none"
+	// DepStrictnessUnexpected fails the test cases making outgoing calls which don't match any mock
+	DepStrictnessUnexpected = "unexpected"
+	// DepStrictnessAll also fails the test cases which don't consume all the mocks recorded along with them
+	DepStrictnessAll = "all"
+)
+
 type UtGen struct {
 	SourceFilePath     string  `json:"sourceFilePath" yaml:"sourceFilePath" mapstructure:"sourceFilePath"`
 	TestFilePath       string  `json:"testFilePath" yaml:"testFilePath" mapstructure:"testFilePath"`
@@ -52,6 +74,50 @@ type Record struct {
 	Filters     []Filter      `json:"filters" yaml:"filters" mapstructure:"filters"`
 	RecordTimer time.Duration `json:"recordTimer" yaml:"recordTimer" mapstructure:"recordTimer"`
 	ReRecord    string        `json:"rerecord" yaml:"rerecord" mapstructure:"rerecord"`
+	Redact      Redact        `json:"redact" yaml:"redact" mapstructure:"redact"`
+	Denoise     bool          `json:"denoise" yaml:"denoise" mapstructure:"denoise"` // replay the captured requests a second time to propose the fields which differ as noise
+	// DenoiseMethods are the methods of the captured requests replayed to find the noise besides GET, HEAD and
+	// OPTIONS, e.g. POST. The replay repeats the side effects of the request on the services of the app, like a
+	// second insert or payment, and the differences it causes, like the 404 of a second DELETE, are proposed as
+	// noise. Opt in only the methods of the endpoints which are idempotent.
+	DenoiseMethods []string `json:"denoiseMethods" yaml:"denoiseMethods" mapstructure:"denoiseMethods"`
+}
+
+// Redact holds the rules to replace the secrets and PII in the recorded testcases and mocks with placeholders.
+// The redacted request headers of the testcases are sent to the app with the values of their
+// KEPLOY_SECRET_<HEADER> env variables at replay, e.g. KEPLOY_SECRET_AUTHORIZATION.
+type Redact struct {
+	Enable      bool     `json:"enable" yaml:"enable" mapstructure:"enable"`
+	Headers     []string `json:"headers" yaml:"headers" mapstructure:"headers"`             // names of the headers whose values are redacted
+	JSONPaths   []string `json:"jsonPaths" yaml:"jsonPaths" mapstructure:"jsonPaths"`       // dot separated paths of the json body fields, * matches any key or index
+	Regexes     []string `json:"regexes" yaml:"regexes" mapstructure:"regexes"`             // patterns whose matches are redacted from the urls, headers and bodies
+	DBPasswords bool     `json:"dbPasswords" yaml:"dbPasswords" mapstructure:"dbPasswords"` // redact the passwords of the MySQL/Postgres handshakes
+}
+
+// Encryption configures the envelope encryption of the specs of the recorded testcases and mocks. It is
+// enabled as well when the key env variable is set.
+type Encryption struct {
+	Enable  bool   `json:"enable" yaml:"enable" mapstructure:"enable"`
+	KeyEnv  string `json:"keyEnv" yaml:"keyEnv" mapstructure:"keyEnv"`    // env variable holding the base64 or hex encoded 256 bit master key
+	KeyFile string `json:"keyFile" yaml:"keyFile" mapstructure:"keyFile"` // file holding the master key, read when the env variable is not set
+}
+
+type Convert struct {
+	From string `json:"from" yaml:"from" mapstructure:"from"`
+	To   string `json:"to" yaml:"to" mapstructure:"to"`
+}
+
+type Report struct {
+	TestRun string   `json:"testRun" yaml:"testRun" mapstructure:"testRun"`
+	Formats []string `json:"formats" yaml:"formats" mapstructure:"formats"`
+}
+
+type Coverage struct {
+	TestRun string `json:"testRun" yaml:"testRun" mapstructure:"testRun"`
+	// View is the view of the coverage report, per test case or per file
+	View string `json:"view" yaml:"view" mapstructure:"view"`
+	// TestCase limits the per test case view to the test cases with this name, along with the lines they cover
+	TestCase string `json:"testCase" yaml:"testCase" mapstructure:"testCase"`
 }
 
 type Normalize struct {
@@ -59,6 +125,11 @@ type Normalize struct {
 	TestRun       string          `json:"testReport" yaml:"testReport" mapstructure:"testReport"`
 }
 
+// Denoise holds the options of the noise proposed by replaying the test cases twice
+type Denoise struct {
+	Apply bool `json:"apply" yaml:"apply" mapstructure:"apply"` // add the reviewed noise to the test cases instead of replaying them
+}
+
 type BypassRule struct {
 	Path string `json:"path" yaml:"path" mapstructure:"path"`
 	Host string `json:"host" yaml:"host" mapstructure:"host"`
@@ -86,11 +157,60 @@ type Test struct {
 	FallBackOnMiss     bool                `json:"fallBackOnMiss" yaml:"fallBackOnMiss" mapstructure:"fallBackOnMiss"`
 	BasePath           string              `json:"basePath" yaml:"basePath" mapstructure:"basePath"`
 	Mocking            bool                `json:"mocking" yaml:"mocking" mapstructure:"mocking"`
+	ReportFormats      []string            `json:"reportFormats" yaml:"reportFormats" mapstructure:"reportFormats"`          // formats in which the test run report is exported (junit/json)
+	Parallel           int                 `json:"parallel" yaml:"parallel" mapstructure:"parallel"`                         // number of test sets run at once, each on its own app instance
+	Retries            int                 `json:"retries" yaml:"retries" mapstructure:"retries"`                            // number of times a failing test case is re-run, the ones passing on a retry are flaky
+	SchemaMatch        bool                `json:"schemaMatch" yaml:"schemaMatch" mapstructure:"schemaMatch"`                // compare only the structure and value types of the json response bodies
+	SchemaMatchPaths   []string            `json:"schemaMatchPaths" yaml:"schemaMatchPaths" mapstructure:"schemaMatchPaths"` // url path prefixes of the endpoints whose json responses are compared by schema
+	DepStrictness      string              `json:"depStrictness" yaml:"depStrictness" mapstructure:"depStrictness"`          // outgoing call differences which fail a test case (none/unexpected/all)
+	StrictMocks        bool                `json:"strictMocks" yaml:"strictMocks" mapstructure:"strictMocks"`                // fail the outgoing calls matching no mock and the test cases making them, instead of falling back
+	Latency            Latency             `json:"latency" yaml:"latency" mapstructure:"latency"`
+	ChangedSince       string              `json:"changedSince" yaml:"changedSince" mapstructure:"changedSince"`                   // git ref, only the test cases covering the go code changed since it are run
+	ChangedSinceIgnore []string            `json:"changedSinceIgnore" yaml:"changedSinceIgnore" mapstructure:"changedSinceIgnore"` // patterns of the changed files which affect no test case, the other changed files which are not go code run all the test cases when they are in a go module of the app
+}
+
+// Latency delays the responses of the mocks by the duration recorded between their request and response, so
+// that the timeouts, retries and slow paths of the app are exercised
+type Latency struct {
+	Enable bool               `json:"enable" yaml:"enable" mapstructure:"enable"`
+	Factor float64            `json:"factor" yaml:"factor" mapstructure:"factor"` // scales the recorded durations, e.g. 0.5 replays them twice as fast
+	Kinds  map[string]float64 `json:"kinds" yaml:"kinds" mapstructure:"kinds"`    // factors of the mock kinds (Http, gRPC, Mongo, Postgres, SQL, Redis, Generic) replacing the factor, 0 doesn't delay the kind
 }
 
+// KindFactor returns the factor scaling the recorded latency of the mocks of the kind.
+func (l Latency) KindFactor(kind string) float64 {
+	for k, factor := range l.Kinds {
+		// the keys of the config are lower cased
+		if strings.EqualFold(k, kind) {
+			return factor
+		}
+	}
+	return l.Factor
+}
+
+// Globalnoise maps the noisy fields of the responses, for all the test sets and per test set, to the regexes
+// matching the expected values to ignore. The fields with tolerances are still compared, within them.
 type Globalnoise struct {
-	Global   GlobalNoise  `json:"global" yaml:"global" mapstructure:"global"`
-	Testsets TestsetNoise `json:"test-sets" yaml:"test-sets" mapstructure:"test-sets"`
+	Global     GlobalNoise  `json:"global" yaml:"global" mapstructure:"global"`
+	Testsets   TestsetNoise `json:"test-sets" yaml:"test-sets" mapstructure:"test-sets"`
+	Tolerances Tolerances   `json:"tolerances" yaml:"tolerances" mapstructure:"tolerances"`
+}
+
+// Tolerances map the fields of the responses, for all the test sets and per test set, to their tolerances in
+// the same way as the noise, e.g. body: {price: [{delta: 0.01}]} or header: {date: [{window: 30s}]}. A test case
+// sets its own in the tolerances of its assertions, e.g. body.price: [{percent: 5}], which override these.
+type Tolerances struct {
+	Global   GlobalTolerances  `json:"global" yaml:"global" mapstructure:"global"`
+	Testsets TestsetTolerances `json:"test-sets" yaml:"test-sets" mapstructure:"test-sets"`
+}
+
+// Tolerance is a tolerance of the actual value of a field around its expected value. A field matches when its
+// actual value is within any of its tolerances.
+type Tolerance struct {
+	Delta   float64       `json:"delta,omitempty" yaml:"delta,omitempty" mapstructure:"delta"`       // numbers within ±delta
+	Percent float64       `json:"percent,omitempty" yaml:"percent,omitempty" mapstructure:"percent"` // numbers within the percentage of the expected value
+	Window  time.Duration `json:"window,omitempty" yaml:"window,omitempty" mapstructure:"window"`    // timestamps within the window of the expected value
+	FromNow bool          `json:"fromNow,omitempty" yaml:"fromNow,omitempty" mapstructure:"fromNow"` // the window is around the time of the comparison instead
 }
 
 type SelectedTests struct {
@@ -102,6 +222,9 @@ type (
 	Noise        map[string][]string
 	GlobalNoise  map[string]map[string][]string
 	TestsetNoise map[string]map[string]map[string][]string
+
+	GlobalTolerances  map[string]map[string][]Tolerance
+	TestsetTolerances map[string]map[string]map[string][]Tolerance
 )
 
 func SetByPassPorts(conf *Config, ports []uint) {
@@ -23,11 +23,15 @@ generateGithubActions: true
 containerName: ""
 networkName: ""
 buildDelay: 30
+storage: "yaml"
 test:
   selectedTests: {}
   globalNoise:
     global: {}
     test-sets: {}
+    tolerances:
+      global: {}
+      test-sets: {}
   delay: 5
   apiTimeout: 5
   coverage: false
@@ -39,9 +43,34 @@ test:
   removeUnusedMocks: false
   basePath: ""
   mocking: true
+  reportFormats: []
+  parallel: 1
+  retries: 0
+  schemaMatch: false
+  schemaMatchPaths: []
+  depStrictness: "none"
+  strictMocks: false
+  latency:
+    enable: false
+    factor: 1
+    kinds: {}
+  changedSince: ""
+  changedSinceIgnore: ["*.md", "keploy"]
 record:
   recordTimer: 0s
   filters: []
+  denoise: false
+  denoiseMethods: []
+  redact:
+    enable: false
+    headers: ["Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"]
+    jsonPaths: []
+    regexes: []
+    dbPasswords: true
+encryption:
+  enable: false
+  keyEnv: "KEPLOY_ENCRYPTION_KEY"
+  keyFile: ""
 configPath: ""
 bypassRules: []
 `
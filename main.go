@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -50,7 +51,10 @@ func main() {
 
 	printLogo()
 	ctx := utils.NewCtx()
-	start(ctx)
+	// the exit code is set once the deferred cleanup of start is done
+	if code := start(ctx); code != 0 {
+		os.Exit(code)
+	}
 }
 
 func printLogo() {
@@ -64,11 +68,12 @@ func printLogo() {
 	}
 }
 
-func start(ctx context.Context) {
+// start runs the keploy command and returns the exit code of keploy.
+func start(ctx context.Context) int {
 	logger, err := log.New()
 	if err != nil {
 		fmt.Println("Failed to start the logger for the CLI", err)
-		return
+		return 0
 	}
 	defer utils.DeleteLogs(logger)
 	defer utils.Recover(logger)
@@ -94,10 +99,14 @@ func start(ctx context.Context) {
 	cmdConfigurator := provider.NewCmdConfigurator(logger, conf)
 	rootCmd := cli.Root(ctx, logger, svcProvider, cmdConfigurator)
 	if err := rootCmd.Execute(); err != nil {
+		if errors.Is(err, cli.ErrCommandFailed) {
+			return 1
+		}
 		if strings.HasPrefix(err.Error(), "unknown command") || strings.HasPrefix(err.Error(), "unknown shorthand") {
 			fmt.Println("Error: ", err.Error())
 			fmt.Println("Run 'keploy --help' for usage.")
-			os.Exit(1)
+			return 1
 		}
 	}
+	return 0
 }
@@ -35,6 +35,7 @@ func NewApp(logger *zap.Logger, id uint64, cmd string, client docker.Client, opt
 		containerDelay:   opts.DockerDelay,
 		containerNetwork: opts.DockerNetwork,
 		containerIPv4:    make(chan string, 1),
+		env:              opts.Env,
 	}
 	return app
 }
@@ -53,6 +54,7 @@ type App struct {
 	keployContainer  string
 	keployIPv4       string
 	inodeChan        chan uint64
+	env              []string
 	EnableTesting    bool
 	Mode             models.Mode
 }
@@ -63,6 +65,8 @@ type Options struct {
 	Container     string
 	DockerDelay   uint64
 	DockerNetwork string
+	// Env holds the KEY=value variables exported to the command of a native app
+	Env []string
 }
 
 func (a *App) Setup(_ context.Context) error {
@@ -456,6 +460,10 @@ func (a *App) run(ctx context.Context) models.AppError {
 		userCmd = utils.EnsureRmBeforeName(userCmd)
 	}
 
+	if !utils.IsDockerKind(a.kind) && len(a.env) > 0 {
+		userCmd = exportEnv(a.env) + userCmd
+	}
+
 	// Define the function to cancel the command
 	cmdCancel := func(cmd *exec.Cmd) func() error {
 		return func() error {
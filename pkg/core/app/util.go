@@ -92,3 +92,13 @@ func isDetachMode(logger *zap.Logger, command string, kind utils.CmdType) bool {
 
 	return false
 }
+
+// exportEnv returns the shell statements exporting the KEY=value variables, the values are single quoted.
+func exportEnv(env []string) string {
+	var sb strings.Builder
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		sb.WriteString("export " + key + "='" + strings.ReplaceAll(value, "'", `'\''`) + "'; ")
+	}
+	return sb.String()
+}
@@ -46,6 +46,8 @@ func (c *Core) Setup(ctx context.Context, cmd string, opts models.SetupOptions)
 		DockerNetwork: opts.DockerNetwork,
 		Container:     opts.Container,
 		DockerDelay:   opts.DockerDelay,
+		// the hooks find the app of an outgoing connection from the environment of its process
+		Env: append([]string{fmt.Sprintf("%s=%d", models.AppIDEnv, id)}, opts.Env...),
 	})
 	c.apps.Store(id, a)
 
@@ -56,6 +56,15 @@ func (factory *Factory) ProcessActiveTrackers(ctx context.Context, t chan *model
 					continue
 				}
 
+				// the frames of the grpc calls are decoded along with the ones of the earlier turns of the conn
+				if tracker.grpc == nil && isHTTP2(requestBuf) {
+					tracker.grpc = newGrpcConn(factory.logger)
+				}
+				if tracker.grpc != nil {
+					tracker.grpc.process(ctx, t, requestBuf, responseBuf, reqTimestampTest, resTimestampTest, opts)
+					continue
+				}
+
 				parsedHTTPReq, err := pkg.ParseHTTPRequest(requestBuf)
 				if err != nil {
 					utils.LogError(factory.logger, err, "failed to parse the http request from byte array", zap.Any("requestBuf", requestBuf))
@@ -0,0 +1,262 @@
+//go:build linux
+
+package conn
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/grpc"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+)
+
+// isHTTP2 tells whether the first request of a conn is the http2 client preface, which the grpc clients
+// send before their calls.
+func isHTTP2(requestBuf []byte) bool {
+	return bytes.HasPrefix(requestBuf, []byte(http2.ClientPreface))
+}
+
+// grpcConn reassembles the grpc calls of an http2 conn. The frames of a call can be split across the
+// request and response turns of the tracker, so the frames and the header tables are kept for the whole conn.
+type grpcConn struct {
+	logger  *zap.Logger
+	streams *grpc.StreamInfoCollection
+	req     *frameReader
+	resp    *frameReader
+	// reqTimestamps are the timestamps of the turns in which the requests of the streams started
+	reqTimestamps map[uint32]time.Time
+	// the messages of a stream can span several DATA frames
+	reqData  map[uint32][]byte
+	respData map[uint32][]byte
+	// failed is set when the frames can't be decoded, the rest of the conn is not recorded
+	failed bool
+}
+
+func newGrpcConn(logger *zap.Logger) *grpcConn {
+	return &grpcConn{
+		logger:        logger,
+		streams:       grpc.NewStreamInfoCollection(),
+		req:           newFrameReader(),
+		resp:          newFrameReader(),
+		reqTimestamps: map[uint32]time.Time{},
+		reqData:       map[uint32][]byte{},
+		respData:      map[uint32][]byte{},
+	}
+}
+
+// frameReader decodes the frames of one direction of the conn. The data is only handed to the framer once
+// the frames are complete, along with the CONTINUATION frames of their header blocks.
+type frameReader struct {
+	pending  []byte
+	complete *bytes.Buffer
+	framer   *http2.Framer
+}
+
+func newFrameReader() *frameReader {
+	complete := &bytes.Buffer{}
+	framer := http2.NewFramer(io.Discard, complete)
+	framer.ReadMetaHeaders = grpc.NewDecoder()
+	framer.MaxHeaderListSize = 1 << 20
+	framer.SetMaxReadFrameSize(1<<24 - 1)
+	return &frameReader{complete: complete, framer: framer}
+}
+
+// readFrames passes the complete frames of the data received so far to handle. A frame is only valid until
+// the next one is read.
+func (fr *frameReader) readFrames(data []byte, handle func(http2.Frame)) error {
+	fr.pending = append(fr.pending, data...)
+	n := completeFrames(fr.pending)
+	fr.complete.Write(fr.pending[:n])
+	fr.pending = fr.pending[n:]
+
+	for fr.complete.Len() > 0 {
+		frame, err := fr.framer.ReadFrame()
+		if err != nil {
+			return err
+		}
+		handle(frame)
+	}
+	return nil
+}
+
+// completeFrames returns the length of the frames at the start of data which are complete, a header block
+// is complete with its last CONTINUATION frame.
+func completeFrames(data []byte) int {
+	const frameHeaderLen = 9
+	complete, offset := 0, 0
+	for len(data)-offset >= frameHeaderLen {
+		length := int(binary.BigEndian.Uint32(append([]byte{0}, data[offset:offset+3]...)))
+		if len(data)-offset < frameHeaderLen+length {
+			break
+		}
+		frameType, flags := http2.FrameType(data[offset+3]), http2.Flags(data[offset+4])
+		offset += frameHeaderLen + length
+		switch frameType {
+		case http2.FrameHeaders, http2.FramePushPromise, http2.FrameContinuation:
+			if !flags.Has(http2.FlagHeadersEndHeaders) {
+				continue
+			}
+		}
+		complete = offset
+	}
+	return complete
+}
+
+// process decodes the frames of a request and response turn of the conn, and captures a test case for every
+// grpc call whose response ended.
+func (g *grpcConn) process(ctx context.Context, t chan *models.TestCase, requestBuf, responseBuf []byte, reqTimeTest, resTimeTest time.Time, opts models.IncomingOptions) {
+	if g.failed {
+		return
+	}
+	requestBuf = bytes.TrimPrefix(requestBuf, []byte(http2.ClientPreface))
+
+	err := g.req.readFrames(requestBuf, func(frame http2.Frame) {
+		g.addRequestFrame(frame, reqTimeTest)
+	})
+	if err == nil {
+		err = g.resp.readFrames(responseBuf, func(frame http2.Frame) {
+			if streamID, ended := g.addResponseFrame(frame); ended {
+				g.capture(ctx, t, streamID, resTimeTest, opts)
+			}
+		})
+	}
+	if err != nil {
+		utils.LogError(g.logger, err, "failed to decode the http2 frames of the grpc conn, the rest of the conn will not be recorded")
+		g.failed = true
+	}
+}
+
+func (g *grpcConn) addRequestFrame(frame http2.Frame, reqTimeTest time.Time) {
+	switch frame := frame.(type) {
+	case *http2.MetaHeadersFrame:
+		pseudoHeaders, ordinaryHeaders := splitHeaders(frame)
+		g.streams.AddHeadersForRequest(frame.StreamID, pseudoHeaders, true)
+		g.streams.AddHeadersForRequest(frame.StreamID, ordinaryHeaders, false)
+		if _, ok := g.reqTimestamps[frame.StreamID]; !ok {
+			g.reqTimestamps[frame.StreamID] = reqTimeTest
+		}
+	case *http2.DataFrame:
+		g.reqData[frame.StreamID] = append(g.reqData[frame.StreamID], frame.Data()...)
+	case *http2.RSTStreamFrame:
+		g.reset(frame.StreamID)
+	}
+}
+
+// addResponseFrame adds the frame to its stream, it returns true when the frame ends the stream.
+func (g *grpcConn) addResponseFrame(frame http2.Frame) (uint32, bool) {
+	switch frame := frame.(type) {
+	case *http2.MetaHeadersFrame:
+		pseudoHeaders, ordinaryHeaders := splitHeaders(frame)
+		// the headers which end the stream are the trailers
+		isTrailer := frame.StreamEnded()
+		g.streams.AddHeadersForResponse(frame.StreamID, pseudoHeaders, true, isTrailer)
+		g.streams.AddHeadersForResponse(frame.StreamID, ordinaryHeaders, false, isTrailer)
+		return frame.StreamID, isTrailer
+	case *http2.DataFrame:
+		g.respData[frame.StreamID] = append(g.respData[frame.StreamID], frame.Data()...)
+	case *http2.RSTStreamFrame:
+		g.reset(frame.StreamID)
+	}
+	return 0, false
+}
+
+func (g *grpcConn) reset(streamID uint32) {
+	g.streams.ResetStream(streamID)
+	delete(g.reqTimestamps, streamID)
+	delete(g.reqData, streamID)
+	delete(g.respData, streamID)
+}
+
+// capture sends the test case of the ended stream. The test cases hold a single message per direction, the
+// client and server streaming calls are not recorded.
+func (g *grpcConn) capture(_ context.Context, t chan *models.TestCase, streamID uint32, resTimeTest time.Time, opts models.IncomingOptions) {
+	reqMessages, respMessages := countMessages(g.reqData[streamID]), countMessages(g.respData[streamID])
+	if reqMessages > 1 || respMessages > 1 {
+		path := g.streams.StreamInfo[streamID].GrpcReq.Headers.PseudoHeaders[grpc.KLabelForPath]
+		g.logger.Warn("skipping the streaming grpc call, only the unary calls are recorded as test cases", zap.String("method", path), zap.Int("request messages", reqMessages), zap.Int("response messages", respMessages))
+		g.reset(streamID)
+		return
+	}
+	g.streams.AddPayloadForRequest(streamID, g.reqData[streamID])
+	g.streams.AddPayloadForResponse(streamID, g.respData[streamID])
+	stream := g.streams.StreamInfo[streamID]
+	reqTimeTest := g.reqTimestamps[streamID]
+	g.reset(streamID)
+
+	grpcReq, grpcResp := stream.GrpcReq, stream.GrpcResp
+	grpcReq.Timestamp, grpcResp.Timestamp = reqTimeTest, resTimeTest
+
+	if req, err := grpcHTTPRequest(grpcReq); err != nil {
+		utils.LogError(g.logger, err, "failed to check the filters of the grpc request")
+	} else if isFiltered(g.logger, req, opts) {
+		g.logger.Debug("The request is a filtered request")
+		return
+	}
+
+	t <- &models.TestCase{
+		Version:  models.GetVersion(),
+		Name:     grpcReq.Headers.OrdinaryHeaders["keploy-test-name"],
+		Kind:     models.GRPC_EXPORT,
+		Created:  time.Now().Unix(),
+		GrpcReq:  grpcReq,
+		GrpcResp: grpcResp,
+		Noise:    map[string][]string{},
+	}
+}
+
+// countMessages returns the number of length prefixed grpc messages of the data of a stream, a truncated
+// message is counted as well.
+func countMessages(data []byte) int {
+	const prefixLen = 5
+	count := 0
+	for offset := 0; offset < len(data); count++ {
+		if len(data)-offset < prefixLen {
+			return count + 1
+		}
+		offset += prefixLen + int(binary.BigEndian.Uint32(data[offset+1:offset+prefixLen]))
+	}
+	return count
+}
+
+func splitHeaders(frame *http2.MetaHeadersFrame) (pseudoHeaders, ordinaryHeaders map[string]string) {
+	pseudoHeaders, ordinaryHeaders = map[string]string{}, map[string]string{}
+	for _, field := range frame.PseudoFields() {
+		pseudoHeaders[field.Name] = field.Value
+	}
+	for _, field := range frame.RegularFields() {
+		ordinaryHeaders[field.Name] = field.Value
+	}
+	return pseudoHeaders, ordinaryHeaders
+}
+
+// grpcHTTPRequest returns the grpc request as an http request, to match it against the filters of the
+// incoming requests.
+func grpcHTTPRequest(grpcReq models.GrpcReq) (*http.Request, error) {
+	authority := grpcReq.Headers.PseudoHeaders[grpc.KLabelForAuthority]
+	if authority == "" {
+		return nil, errors.New("the grpc request has no authority")
+	}
+	if _, _, err := net.SplitHostPort(authority); err != nil {
+		// the filters are matched against the port of the app
+		authority = net.JoinHostPort(authority, "80")
+	}
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("http://%s%s", authority, grpcReq.Headers.PseudoHeaders[grpc.KLabelForPath]), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range grpcReq.Headers.OrdinaryHeaders {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
@@ -66,6 +66,9 @@ type Tracker struct {
 
 	reqTimestamps []time.Time
 	isNewRequest  bool
+
+	// grpc reassembles the grpc calls when the conn is an http2 one
+	grpc *grpcConn
 }
 
 func NewTracker(connID ID, logger *zap.Logger) *Tracker {
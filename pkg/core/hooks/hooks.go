@@ -42,6 +42,9 @@ type Hooks struct {
 	proxyIP   string
 	proxyPort uint32
 	dnsPort   uint32
+	// loaded is set once the eBPF programs are loaded, the apps hooked afterwards share them. It is guarded
+	// by m
+	loaded bool
 
 	m sync.Mutex
 	// eBPF C shared maps
@@ -101,6 +104,14 @@ func (h *Hooks) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
 		ID: id,
 	})
 
+	// the apps hooked at once in parallel mode load the hooks only once
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.loaded {
+		h.logger.Debug("hooks already loaded", zap.Uint64("appID", id))
+		return nil
+	}
+
 	err := h.load(ctx, opts)
 	if err != nil {
 		return err
@@ -114,7 +125,10 @@ func (h *Hooks) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
 	g.Go(func() error {
 		defer utils.Recover(h.logger)
 		<-ctx.Done()
+		h.m.Lock()
+		defer h.m.Unlock()
 		h.unLoad(ctx)
+		h.loaded = false
 		return nil
 	})
 
@@ -145,6 +159,7 @@ func (h *Hooks) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
 		return err
 	}
 
+	h.loaded = true
 	return nil
 }
 
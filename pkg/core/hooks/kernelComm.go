@@ -22,8 +22,11 @@ func (h *Hooks) Get(_ context.Context, srcPort uint16) (*core.NetworkAddress, er
 	if err != nil {
 		return nil, err
 	}
-	// TODO : need to implement eBPF code to differentiate between different apps
-	s, ok := h.sess.Get(0)
+	// the eBPF code doesn't differentiate between the apps, the app is found from the process of the connection
+	s, ok := h.sess.Get(appIDOfProcess(d.KernelPid))
+	if !ok {
+		s, ok = h.sess.Get(0)
+	}
 	if !ok {
 		return nil, fmt.Errorf("session not found")
 	}
@@ -9,9 +9,11 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
+	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
@@ -69,3 +71,22 @@ func getSelfInodeNumber() (uint64, error) {
 	}
 	return 0, nil
 }
+
+// appIDOfProcess returns the id of the app the process belongs to, read from the environment exported to
+// the command of the app. It returns 0 (the first app) when the process has no app id.
+func appIDOfProcess(pid uint32) uint64 {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.FormatUint(uint64(pid), 10), "environ"))
+	if err != nil {
+		return 0
+	}
+	for _, kv := range strings.Split(string(data), "\x00") {
+		if value, ok := strings.CutPrefix(kv, models.AppIDEnv+"="); ok {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return 0
+			}
+			return id
+		}
+	}
+	return 0
+}
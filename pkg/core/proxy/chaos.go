@@ -0,0 +1,252 @@
+//go:build linux
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgproto3/v2"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// errFaultInjected is returned by the writes to the conns which are reset or truncated by a fault
+var errFaultInjected = errors.New("the conn was aborted by an injected fault")
+
+// chaosFault is a fault of the chaos config of the test set along with its compiled url pattern
+type chaosFault struct {
+	models.Fault
+	url *regexp.Regexp
+}
+
+func compileFaults(faults []models.Fault) ([]*chaosFault, error) {
+	var compiled []*chaosFault
+	for _, fault := range faults {
+		switch fault.Type {
+		case models.FaultError, models.FaultReset, models.FaultTruncate, models.FaultLatency:
+		default:
+			return nil, fmt.Errorf("unknown type %q of the fault %s, it must be one of error, reset, truncate and latency", fault.Type, fault.Name)
+		}
+		if fault.SQLState != "" && len(fault.SQLState) != 5 {
+			return nil, fmt.Errorf("invalid sql state %q of the fault %s, it must have 5 characters", fault.SQLState, fault.Name)
+		}
+		cf := &chaosFault{Fault: fault}
+		if fault.URL != "" {
+			re, err := regexp.Compile(fault.URL)
+			if err != nil {
+				return nil, fmt.Errorf("invalid url pattern of the fault %s: %w", fault.Name, err)
+			}
+			cf.url = re
+		}
+		compiled = append(compiled, cf)
+	}
+	return compiled, nil
+}
+
+// matches tells whether the fault is injected into the mock served on a conn to the destination. The config
+// mocks, like the handshakes of the databases, are left out.
+func (f *chaosFault) matches(mock *models.Mock, destination string) bool {
+	if mock.Spec.Metadata["type"] == "config" {
+		return false
+	}
+	if f.Kind != "" && !strings.EqualFold(f.Kind, string(mock.Kind)) {
+		return false
+	}
+	host, port, err := net.SplitHostPort(destination)
+	if err != nil {
+		host = destination
+	}
+	if f.Port != 0 && port != strconv.FormatUint(uint64(f.Port), 10) {
+		return false
+	}
+	mockHost, mockURL := mockAddress(mock)
+	if f.Host != "" && !strings.EqualFold(f.Host, host) && !strings.EqualFold(f.Host, mockHost) {
+		return false
+	}
+	if f.url != nil && (mockURL == "" || !f.url.MatchString(mockURL)) {
+		return false
+	}
+	return true
+}
+
+// mockAddress returns the host and the url of the http mocks, and the authority and the path of the grpc ones.
+func mockAddress(mock *models.Mock) (string, string) {
+	switch {
+	case mock.Kind == models.HTTP && mock.Spec.HTTPReq != nil:
+		parsedURL, err := url.Parse(mock.Spec.HTTPReq.URL)
+		if err != nil {
+			return "", mock.Spec.HTTPReq.URL
+		}
+		return parsedURL.Hostname(), mock.Spec.HTTPReq.URL
+	case mock.Kind == models.GRPC_EXPORT && mock.Spec.GRPCReq != nil:
+		authority := mock.Spec.GRPCReq.Headers.PseudoHeaders[":authority"]
+		if host, _, err := net.SplitHostPort(authority); err == nil {
+			authority = host
+		}
+		return authority, mock.Spec.GRPCReq.Headers.PseudoHeaders[":path"]
+	}
+	return "", ""
+}
+
+func (f *chaosFault) message() string {
+	if f.Message != "" {
+		return f.Message
+	}
+	return fmt.Sprintf("keploy: injected fault %s", f.Name)
+}
+
+// hasErrorResponse tells whether the error faults are injected into the mocks of the kind as error responses,
+// the conns of the other kinds are reset instead.
+func hasErrorResponse(kind models.Kind) bool {
+	switch kind {
+	case models.HTTP, models.GRPC_EXPORT, models.Postgres, models.SQL, models.REDIS:
+		return true
+	}
+	return false
+}
+
+// injectError replaces the response of the mock with the error of the fault, in the protocol of the mock.
+func injectError(mock *models.Mock, f *chaosFault) {
+	msg := f.message()
+	switch mock.Kind {
+	case models.HTTP:
+		if mock.Spec.HTTPResp == nil {
+			return
+		}
+		status := f.Status
+		if status == 0 {
+			status = http.StatusServiceUnavailable
+		}
+		resp := *mock.Spec.HTTPResp
+		resp.StatusCode = status
+		resp.StatusMessage = http.StatusText(status)
+		resp.Header = map[string]string{"Content-Type": "text/plain; charset=utf-8", "Content-Length": strconv.Itoa(len(msg))}
+		resp.Body = msg
+		mock.Spec.HTTPResp = &resp
+	case models.GRPC_EXPORT:
+		if mock.Spec.GRPCResp == nil {
+			return
+		}
+		status := f.Status
+		if status == 0 {
+			// codes.Unavailable
+			status = 14
+		}
+		resp := *mock.Spec.GRPCResp
+		resp.Body = models.GrpcLengthPrefixedMessage{}
+		resp.Trailers = models.GrpcHeaders{
+			PseudoHeaders:   map[string]string{},
+			OrdinaryHeaders: map[string]string{"grpc-status": strconv.Itoa(status), "grpc-message": msg},
+		}
+		mock.Spec.GRPCResp = &resp
+	case models.Postgres:
+		sqlState := f.SQLState
+		if sqlState == "" {
+			// connection_failure
+			sqlState = "08006"
+		}
+		mock.Spec.PostgresResponses = []models.Frontend{{
+			PacketTypes:   []string{"E", "Z"},
+			ErrorResponse: pgproto3.ErrorResponse{Severity: "ERROR", Code: sqlState, Message: msg},
+			ReadyForQuery: pgproto3.ReadyForQuery{TxStatus: 'I'},
+		}}
+	case models.SQL:
+		code := f.Status
+		if code == 0 {
+			// ER_UNKNOWN_ERROR
+			code = 1105
+		}
+		sqlState := f.SQLState
+		if sqlState == "" {
+			sqlState = "HY000"
+		}
+		// every request of the mock gets the error
+		responses := make([]models.MySQLResponse, len(mock.Spec.MySQLResponses))
+		for i := range responses {
+			responses[i] = models.MySQLResponse{
+				Header: &models.MySQLPacketHeader{PacketType: "MySQLErr"},
+				Message: &models.MySQLERRPacket{
+					Header:         0xff,
+					ErrorCode:      uint16(code),
+					SQLStateMarker: "#",
+					SQLState:       sqlState,
+					ErrorMessage:   msg,
+				},
+			}
+		}
+		mock.Spec.MySQLResponses = responses
+	case models.REDIS:
+		mock.Spec.RedisResponses = []models.Payload{{
+			Origin:  models.FromServer,
+			Message: []models.OutputBinary{{Type: models.String, Data: "-ERR " + msg + "\r\n"}},
+		}}
+	}
+}
+
+// faultHooks injects the faults matching the mocks served on a conn to the destination. The mocks matching
+// the error faults are served with the error responses, and the faults are injected into the response of the
+// mocks consumed by the integration.
+func faultHooks(hooks *mockHooks, m *MockManager, faults []*chaosFault, destination string) {
+	f := &faultInjector{manager: m, faults: faults, destination: destination}
+	hooks.served = append(hooks.served, f.injectErrors)
+	hooks.consumed = append(hooks.consumed, f.consumed)
+}
+
+type faultInjector struct {
+	manager     *MockManager
+	faults      []*chaosFault
+	destination string
+}
+
+// injectErrors replaces the responses of the mocks matching an error fault.
+func (fi *faultInjector) injectErrors(mocks []*models.Mock) []*models.Mock {
+	for _, mock := range mocks {
+		for _, f := range fi.faults {
+			if f.Type == models.FaultError && f.matches(mock, fi.destination) {
+				injectError(mock, f)
+				break
+			}
+		}
+	}
+	return mocks
+}
+
+// consumed returns the faults matching the mock to inject into its response and records them.
+func (fi *faultInjector) consumed(mock *models.Mock) responseEffect {
+	var effect responseEffect
+	errorInjected := false
+	for _, f := range fi.faults {
+		if !f.matches(mock, fi.destination) {
+			continue
+		}
+		switch f.Type {
+		case models.FaultError:
+			if errorInjected {
+				continue
+			}
+			errorInjected = true
+			// the response was replaced when the mock was served
+			if !hasErrorResponse(mock.Kind) {
+				effect.faults = append(effect.faults, models.FaultReset)
+			}
+		case models.FaultLatency:
+			effect.delay += f.Latency
+		default:
+			effect.faults = append(effect.faults, f.Type)
+		}
+		fi.manager.FlagFaultAsInjected(models.InjectedFault{
+			Fault:       f.Name,
+			Type:        f.Type,
+			Mock:        mock.Name,
+			Kind:        string(mock.Kind),
+			Destination: fi.destination,
+		})
+	}
+	return effect
+}
@@ -0,0 +1,165 @@
+//go:build linux
+
+package proxy
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// responseEffect is applied to the response written to the app after the consumption of a mock.
+type responseEffect struct {
+	delay time.Duration
+	// faults are the reset and truncate faults aborting the response
+	faults []models.FaultType
+}
+
+// mockHooks are the callbacks of the integrations serving the mocks, like the replay of the recorded latency
+// and the injection of the faults.
+type mockHooks struct {
+	// served rewrite the mocks served to the integration, they are copies of the ones of the mock manager
+	served []func(mocks []*models.Mock) []*models.Mock
+	// consumed return the effect applied to the response of the mock consumed by the integration. The mocks
+	// which are served without being consumed, like the handshakes of the config mocks, have no effect.
+	consumed []func(mock *models.Mock) responseEffect
+}
+
+func (h *mockHooks) empty() bool {
+	return len(h.served) == 0 && len(h.consumed) == 0
+}
+
+// withMockHooks wraps the client conn and the mock db of an integration so that the hooks are called on the
+// mocks served and consumed by the integration.
+func withMockHooks(ctx context.Context, conn net.Conn, mockDb integrations.MockMemDb, hooks mockHooks) (net.Conn, integrations.MockMemDb) {
+	rConn := &responseConn{Conn: conn, ctx: ctx}
+	return rConn, &hookedMockDb{MockMemDb: mockDb, conn: rConn, hooks: hooks}
+}
+
+// responseConn applies the effects of the consumed mocks to the next response written to the app.
+type responseConn struct {
+	net.Conn
+	ctx     context.Context
+	mu      sync.Mutex
+	pending responseEffect
+}
+
+func (c *responseConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	pending := c.pending
+	// the responses spanning several writes are affected once
+	c.pending = responseEffect{}
+	c.mu.Unlock()
+
+	if pending.delay > 0 {
+		timer := time.NewTimer(pending.delay)
+		select {
+		case <-c.ctx.Done():
+			timer.Stop()
+			return 0, c.ctx.Err()
+		case <-timer.C:
+		}
+	}
+	for _, faultType := range pending.faults {
+		switch faultType {
+		case models.FaultReset:
+			c.reset()
+			return 0, errFaultInjected
+		case models.FaultTruncate:
+			n, err := c.Conn.Write(p[:len(p)/2])
+			if err != nil {
+				return n, err
+			}
+			c.reset()
+			return n, errFaultInjected
+		}
+	}
+	return c.Conn.Write(p)
+}
+
+// reset closes the conn, the peer gets a TCP reset when the conn is the one of the app.
+func (c *responseConn) reset() {
+	if tcpConn, ok := c.Conn.(*net.TCPConn); ok {
+		_ = tcpConn.SetLinger(0)
+	}
+	_ = c.Conn.Close()
+}
+
+func (c *responseConn) add(effect responseEffect) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending.delay += effect.delay
+	c.pending.faults = append(c.pending.faults, effect.faults...)
+}
+
+// hookedMockDb calls the hooks on the mocks served to and consumed by the integration.
+type hookedMockDb struct {
+	integrations.MockMemDb
+	conn  *responseConn
+	hooks mockHooks
+}
+
+func (db *hookedMockDb) GetFilteredMocks() ([]*models.Mock, error) {
+	mocks, err := db.MockMemDb.GetFilteredMocks()
+	if err != nil {
+		return nil, err
+	}
+	return db.served(mocks), nil
+}
+
+func (db *hookedMockDb) GetUnFilteredMocks() ([]*models.Mock, error) {
+	mocks, err := db.MockMemDb.GetUnFilteredMocks()
+	if err != nil {
+		return nil, err
+	}
+	return db.served(mocks), nil
+}
+
+func (db *hookedMockDb) served(mocks []*models.Mock) []*models.Mock {
+	for _, served := range db.hooks.served {
+		mocks = served(mocks)
+	}
+	return mocks
+}
+
+func (db *hookedMockDb) UpdateUnFilteredMock(old *models.Mock, new *models.Mock) bool {
+	updated := db.MockMemDb.UpdateUnFilteredMock(old, new)
+	if updated {
+		db.consumed(new)
+	}
+	return updated
+}
+
+func (db *hookedMockDb) DeleteFilteredMock(mock models.Mock) bool {
+	deleted := db.MockMemDb.DeleteFilteredMock(mock)
+	if deleted {
+		db.consumed(&mock)
+	}
+	return deleted
+}
+
+func (db *hookedMockDb) DeleteUnFilteredMock(mock models.Mock) bool {
+	deleted := db.MockMemDb.DeleteUnFilteredMock(mock)
+	if deleted {
+		db.consumed(&mock)
+	}
+	return deleted
+}
+
+func (db *hookedMockDb) FlagMockAsUsed(mock models.Mock) error {
+	err := db.MockMemDb.FlagMockAsUsed(mock)
+	if err == nil {
+		db.consumed(&mock)
+	}
+	return err
+}
+
+func (db *hookedMockDb) consumed(mock *models.Mock) {
+	for _, consumed := range db.hooks.consumed {
+		db.conn.add(consumed(mock))
+	}
+}
@@ -17,7 +17,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	genericRequests := [][]byte{reqBuf}
 	logger.Debug("Into the generic parser in test mode")
 	errCh := make(chan error, 1)
@@ -60,7 +60,18 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 			}
 
 			if !matched {
-				err := clientConn.SetReadDeadline(time.Time{})
+				err := integrations.UnmockedCall(ctx, logger, mockDb, models.UnmatchedCall{
+					Kind:        string(integrations.GENERIC),
+					Destination: dstCfg.Addr,
+					Payload:     integrations.RequestPayload(genericRequests),
+					ClosestMock: integrations.ClosestMock(mockDb, models.GENERIC, genericRequests, closestMatch),
+				}, opts)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				err = clientConn.SetReadDeadline(time.Time{})
 				if err != nil {
 					utils.LogError(logger, err, "failed to set the read deadline for the client conn")
 					return
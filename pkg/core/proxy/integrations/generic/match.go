@@ -150,3 +150,8 @@ func findExactMatch(tcsMocks []*models.Mock, reqBuffs [][]byte) int {
 	}
 	return -1
 }
+
+// closestMatch returns the index of the mock which is the closest to the requests none of the mocks matched.
+func closestMatch(mocks []*models.Mock, reqBuffs [][]byte) int {
+	return findBinaryMatch(mocks, reqBuffs, -1)
+}
@@ -14,9 +14,9 @@ import (
 	"golang.org/x/net/http2"
 )
 
-func decodeGrpc(ctx context.Context, logger *zap.Logger, _ []byte, clientConn net.Conn, _ *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodeGrpc(ctx context.Context, logger *zap.Logger, _ []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	framer := http2.NewFramer(clientConn, clientConn)
-	srv := NewTranscoder(logger, framer, mockDb)
+	srv := NewTranscoder(logger, framer, mockDb, dstCfg, opts)
 	// fake server in the test mode
 	err := srv.ListenAndServe(ctx)
 	if err != nil {
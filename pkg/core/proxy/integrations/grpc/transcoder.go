@@ -7,7 +7,9 @@ import (
 	"context"
 	"fmt"
 
+	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 
 	"go.uber.org/zap"
@@ -21,15 +23,20 @@ type Transcoder struct {
 	logger  *zap.Logger
 	framer  *http2.Framer
 	decoder *hpack.Decoder
+	// dstCfg and opts are used to report the calls which none of the mocks match
+	dstCfg *integrations.ConditionalDstCfg
+	opts   models.OutgoingOptions
 }
 
-func NewTranscoder(logger *zap.Logger, framer *http2.Framer, mockDb integrations.MockMemDb) *Transcoder {
+func NewTranscoder(logger *zap.Logger, framer *http2.Framer, mockDb integrations.MockMemDb, dstCfg *integrations.ConditionalDstCfg, opts models.OutgoingOptions) *Transcoder {
 	return &Transcoder{
 		logger:  logger,
 		framer:  framer,
 		mockDb:  mockDb,
 		sic:     NewStreamInfoCollection(),
 		decoder: NewDecoder(),
+		dstCfg:  dstCfg,
+		opts:    opts,
 	}
 }
 
@@ -85,6 +92,14 @@ func (srv *Transcoder) ProcessDataFrame(ctx context.Context, dataFrame *http2.Da
 		return fmt.Errorf("failed match mocks: %v", err)
 	}
 	if mock == nil {
+		err := integrations.UnmockedCall(ctx, srv.logger, srv.mockDb, models.UnmatchedCall{
+			Kind:        string(integrations.GRPC),
+			Destination: srv.dstCfg.Addr,
+			Payload:     grpcReq.Headers.PseudoHeaders[KLabelForPath] + "\n" + grpcReq.Body.DecodedData,
+		}, srv.opts)
+		if err != nil {
+			return err
+		}
 		return fmt.Errorf("failed to mock the output for unrecorded outgoing grpc call")
 	}
 
@@ -129,7 +144,7 @@ func (srv *Transcoder) ProcessDataFrame(ctx context.Context, dataFrame *http2.Da
 		return err
 	}
 
-	payload, err := createPayloadFromLengthPrefixedMessage(grpcMockResp.Body)
+	payload, err := pkg.CreatePayloadFromLengthPrefixedMessage(grpcMockResp.Body)
 	if err != nil {
 		utils.LogError(srv.logger, err, "could not create grpc payload from mocks")
 		return err
@@ -98,6 +98,16 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 			if !ok {
 				if !IsPassThrough(logger, request, dstCfg.Port, opts) {
 					utils.LogError(logger, nil, "Didn't match any preExisting http mock", zap.Any("metadata", getReqMeta(request)))
+					err = integrations.UnmockedCall(ctx, logger, mockDb, models.UnmatchedCall{
+						Kind:        string(integrations.HTTP),
+						Destination: dstCfg.Addr,
+						Payload:     string(reqBuf),
+						ClosestMock: closestMock(mockDb, input),
+					}, opts)
+					if err != nil {
+						errCh <- err
+						return
+					}
 				}
 				if opts.FallBackOnMiss {
 					_, err = pUtil.PassThrough(ctx, logger, clientConn, dstCfg, [][]byte{reqBuf})
@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"github.com/agnivade/levenshtein"
+	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	"go.keploy.io/server/v2/pkg/models"
@@ -81,8 +82,8 @@ func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrati
 				continue
 			}
 
-			//Check if the path matches
-			if parsedURL.Path != input.url.Path {
+			//Check if the path matches, the values redacted at record time match any value
+			if parsedURL.Path != input.url.Path && !pkg.MatchesRedacted(parsedURL.Path, input.url.Path) {
 				//If it is not the same, continue
 				logger.Debug("The url path of mock and request aren't the same")
 				continue
@@ -116,7 +117,7 @@ func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrati
 		}
 
 		// do exact body match
-		ok, bestMatch := exactBodyMatch(input.body, schemaMatched)
+		ok, bestMatch := exactBodyMatch(input, schemaMatched)
 		if ok {
 			if !updateMock(ctx, logger, bestMatch, mockDb) {
 				continue
@@ -179,15 +180,65 @@ func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrati
 
 }
 
-func exactBodyMatch(body []byte, schemaMatched []*models.Mock) (bool, *models.Mock) {
+// closestMock returns the name of the http mock which is the closest to the request none of the mocks matched.
+// The mocks with the same path and method come first, then the ones with the most similar body.
+func closestMock(mockDb integrations.MockMemDb, input *req) string {
+	filtered, err := mockDb.GetFilteredMocks()
+	if err != nil {
+		return ""
+	}
+	unfiltered, err := mockDb.GetUnFilteredMocks()
+	if err != nil {
+		return ""
+	}
+	closest, maxScore := "", -1.0
+	for _, mock := range append(filtered, unfiltered...) {
+		if mock.Kind != models.HTTP {
+			continue
+		}
+		score := 0.0
+		if parsedURL, err := url.Parse(mock.Spec.HTTPReq.URL); err == nil && parsedURL.Path == input.url.Path {
+			score += 2
+		}
+		if mock.Spec.HTTPReq.Method == models.Method(input.method) {
+			score++
+		}
+		k := util.AdaptiveK(len(input.body), 3, 8, 5)
+		score += util.JaccardSimilarity(util.CreateShingles([]byte(mock.Spec.HTTPReq.Body), k), util.CreateShingles(input.body, k))
+		if score > maxScore {
+			closest, maxScore = mock.Name, score
+		}
+	}
+	return closest
+}
+
+func exactBodyMatch(input *req, schemaMatched []*models.Mock) (bool, *models.Mock) {
+	body := string(input.body)
 	for _, mock := range schemaMatched {
-		if mock.Spec.HTTPReq.Body == string(body) {
+		// values redacted at record time match any value of the request
+		if mock.Spec.HTTPReq.Body != body && !pkg.MatchesRedacted(mock.Spec.HTTPReq.Body, body) {
+			continue
+		}
+		if urlParamsMatch(mock.Spec.HTTPReq.URLParams, input.url.Query()) {
 			return true, mock
 		}
 	}
 	return false, nil
 }
 
+// urlParamsMatch tells whether the query params of the request have the values of the mock, the values
+// redacted at record time match any value.
+func urlParamsMatch(mockParams map[string]string, query url.Values) bool {
+	for k, v := range mockParams {
+		// the values of a param are recorded joined, see pkg.URLParams
+		actual := strings.Join(query[k], ", ")
+		if v != actual && !pkg.MatchesRedacted(v, actual) {
+			return false
+		}
+	}
+	return true
+}
+
 func bodyMatch(logger *zap.Logger, mockBody, reqBody []byte) (bool, error) {
 
 	var mockData map[string]interface{}
@@ -6,9 +6,13 @@ package integrations
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"net"
+	"strings"
 
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
 
@@ -54,4 +58,57 @@ type MockMemDb interface {
 	DeleteUnFilteredMock(mock models.Mock) bool
 	// Flag the mock as used which matches the external request from application in test mode
 	FlagMockAsUsed(mock models.Mock) error
+	// Flag the external request from application which none of the mocks matched in test mode
+	FlagCallAsUnmatched(call models.UnmatchedCall)
+}
+
+// ErrUnmockedCall is returned by MockOutgoing in the strict mocks mode when none of the mocks match an outgoing
+// call, the call is already recorded as unmatched.
+var ErrUnmockedCall = errors.New("unmocked dependency call")
+
+// UnmockedCall records the outgoing call which none of the mocks matched. It returns ErrUnmockedCall in the
+// strict mocks mode, the call must then fail instead of falling back to the actual server.
+func UnmockedCall(ctx context.Context, logger *zap.Logger, mockDb MockMemDb, call models.UnmatchedCall, opts models.OutgoingOptions) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	mockDb.FlagCallAsUnmatched(call)
+	if !opts.StrictMocks {
+		return nil
+	}
+	utils.LogError(logger, ErrUnmockedCall, "none of the mocks matched the outgoing call, failing it in the strict mocks mode",
+		zap.String("kind", call.Kind), zap.String("destination", call.Destination), zap.String("closest mock", call.ClosestMock))
+	return ErrUnmockedCall
+}
+
+// ClosestMock returns the name of the mock of the kind which is the closest to the requests none of the mocks
+// matched. The match function returns the index of the closest of the mocks, or -1 when none is close.
+func ClosestMock(mockDb MockMemDb, kind models.Kind, reqBuffs [][]byte, match func(mocks []*models.Mock, reqBuffs [][]byte) int) string {
+	mocks, err := mockDb.GetUnFilteredMocks()
+	if err != nil {
+		return ""
+	}
+	var kindMocks []*models.Mock
+	for _, mock := range mocks {
+		if mock.Kind == kind {
+			kindMocks = append(kindMocks, mock)
+		}
+	}
+	index := match(kindMocks, reqBuffs)
+	if index == -1 {
+		return ""
+	}
+	return kindMocks[index].Name
+}
+
+// RequestPayload returns the requests as they are recorded in the mocks, the binary ones are base64 encoded.
+func RequestPayload(reqBuffs [][]byte) string {
+	payload := make([]string, len(reqBuffs))
+	for i, reqBuff := range reqBuffs {
+		payload[i] = string(reqBuff)
+		if !util.IsASCII(string(reqBuff)) {
+			payload[i] = util.EncodeBase64(reqBuff)
+		}
+	}
+	return strings.Join(payload, "\n")
 }
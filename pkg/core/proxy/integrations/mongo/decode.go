@@ -248,6 +248,15 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 				}
 				if !matched {
 					logger.Debug("mongo request not matched with any tcsMocks", zap.Any("request", mongoRequests))
+					err := integrations.UnmockedCall(ctx, logger, mockDb, models.UnmatchedCall{
+						Kind:        string(integrations.MONGO),
+						Destination: dstCfg.Addr,
+						Payload:     integrations.RequestPayload(requestBuffers),
+					}, opts)
+					if err != nil {
+						errCh <- err
+						return
+					}
 					reqBuf, err = util.PassThrough(ctx, logger, clientConn, dstCfg, requestBuffers)
 					if err != nil {
 						utils.LogError(logger, err, "failed to passthrough the mongo request to the actual database server")
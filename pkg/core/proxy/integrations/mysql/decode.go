@@ -4,6 +4,7 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"net"
 	"time"
 
@@ -74,15 +75,8 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 					errCh <- err
 					return
 				}
-				_, err = clientConn.Write(binaryPacket)
-				if err != nil {
-					if ctx.Err() != nil {
-						return
-					}
-					utils.LogError(logger, err, "Failed to write binary packet")
-					errCh <- err
-					return
-				}
+				// the mock is consumed before its response is written, so that the effects of its consumption
+				// like the replay of its latency apply to the handshake
 				matchedReqIndex := 0
 				configMocks[matchedIndex].Spec.MySQLResponses = append(configMocks[matchedIndex].Spec.MySQLResponses[:matchedReqIndex], configMocks[matchedIndex].Spec.MySQLResponses[matchedReqIndex+1:]...)
 				if len(configMocks[matchedIndex].Spec.MySQLResponses) == 0 {
@@ -95,6 +89,15 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 					//}
 					mockDb.DeleteUnFilteredMock(*configMocks[matchedIndex])
 				}
+				_, err = clientConn.Write(binaryPacket)
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					utils.LogError(logger, err, "Failed to write binary packet")
+					errCh <- err
+					return
+				}
 				//h.SetConfigMocks(configMocks)
 				firstLoop = false
 				doHandshakeAgain = false
@@ -183,6 +186,16 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 				//TODO: both in case of no match or some other error, we are receiving the error.
 				// Due to this, there will be no passthrough in case of no match.
 				matchedResponse, matchedIndex, _, err := matchRequestWithMock(ctx, mysqlRequest, configMocks, tcsMocks, mockDb)
+				if errors.Is(err, errNoMatch) {
+					unmockedErr := integrations.UnmockedCall(ctx, logger, mockDb, models.UnmatchedCall{
+						Kind:        string(integrations.MYSQL),
+						Destination: dstCfg.Addr,
+						Payload:     integrations.RequestPayload(requestBuffers),
+					}, opts)
+					if unmockedErr != nil {
+						err = unmockedErr
+					}
+				}
 				if err != nil {
 					utils.LogError(logger, err, "Failed to match request with mock")
 					errCh <- err
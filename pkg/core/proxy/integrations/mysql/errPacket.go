@@ -5,6 +5,8 @@ package mysql
 import (
 	"encoding/binary"
 	"fmt"
+
+	"go.keploy.io/server/v2/pkg/models"
 )
 
 type ERRPacket struct {
@@ -33,3 +35,15 @@ func decodeMySQLErr(data []byte) (*ERRPacket, error) {
 	packet.ErrorMessage = string(data[9:])
 	return packet, nil
 }
+
+func encodeMySQLErr(packet *models.MySQLERRPacket) ([]byte, error) {
+	if len(packet.SQLState) != 5 {
+		return nil, fmt.Errorf("invalid SQL state: %q", packet.SQLState)
+	}
+	data := []byte{0xff}
+	data = binary.LittleEndian.AppendUint16(data, packet.ErrorCode)
+	data = append(data, '#')
+	data = append(data, packet.SQLState...)
+	data = append(data, packet.ErrorMessage...)
+	return data, nil
+}
@@ -4,12 +4,16 @@ package mysql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/models"
 )
 
+// errNoMatch is returned when none of the mocks match the mysql request
+var errNoMatch = errors.New("no matching mock found")
+
 func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest, configMocks, tcsMocks []*models.Mock, mockDb integrations.MockMemDb) (*models.MySQLResponse, int, string, error) {
 	//TODO: any reason to write the similar code twice?
 	allMocks := append([]*models.Mock(nil), configMocks...)
@@ -47,7 +51,7 @@ func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest,
 	}
 
 	if bestMatch == nil {
-		return nil, -1, "", fmt.Errorf("no matching mock found")
+		return nil, -1, "", errNoMatch
 	}
 
 	if mockType == "config" {
@@ -108,6 +108,12 @@ func encodeToBinary(packet interface{}, header *models.MySQLPacketHeader, operat
 		}
 		data, err = encodeStmtPrepareOk(p)
 		bypassHeader = true
+	case "MySQLErr":
+		p, ok := packet.(*models.MySQLERRPacket)
+		if !ok {
+			return nil, fmt.Errorf("invalid packet type for MySQLErr: expected *MySQLERRPacket, got %T", packet)
+		}
+		data, err = encodeMySQLErr(p)
 	case "RESULT_SET_PACKET":
 		p, ok := packet.(*models.MySQLResultSet)
 		if !ok {
@@ -20,7 +20,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	pgRequests := [][]byte{reqBuf}
 	errCh := make(chan error, 1)
 
@@ -68,6 +68,15 @@ func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 
 			if !matched {
 				logger.Debug("MISMATCHED REQ is" + string(pgRequests[0]))
+				err := integrations.UnmockedCall(ctx, logger, mockDb, models.UnmatchedCall{
+					Kind:        string(integrations.POSTGRES_V1),
+					Destination: dstCfg.Addr,
+					Payload:     integrations.RequestPayload(pgRequests),
+				}, opts)
+				if err != nil {
+					errCh <- err
+					return
+				}
 				_, err = pUtil.PassThrough(ctx, logger, clientConn, dstCfg, pgRequests)
 				if err != nil {
 					utils.LogError(logger, err, "failed to pass the request", zap.Any("request packets", len(pgRequests)))
@@ -17,7 +17,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func decodeRedis(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodeRedis(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	redisRequests := [][]byte{reqBuf}
 	logger.Debug("Into the redis parser in test mode")
 	errCh := make(chan error, 1)
@@ -64,7 +64,18 @@ func decodeRedis(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 			}
 
 			if !matched {
-				err := clientConn.SetReadDeadline(time.Time{})
+				err := integrations.UnmockedCall(ctx, logger, mockDb, models.UnmatchedCall{
+					Kind:        string(integrations.REDIS),
+					Destination: dstCfg.Addr,
+					Payload:     integrations.RequestPayload(redisRequests),
+					ClosestMock: integrations.ClosestMock(mockDb, models.REDIS, redisRequests, closestMatch),
+				}, opts)
+				if err != nil {
+					errCh <- err
+					return
+				}
+
+				err = clientConn.SetReadDeadline(time.Time{})
 				if err != nil {
 					utils.LogError(logger, err, "failed to set the read deadline for the client conn")
 					return
@@ -0,0 +1,30 @@
+//go:build linux
+
+package proxy
+
+import (
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// latencyHook delays the responses of the mocks consumed by the integrations by their recorded latency.
+func latencyHook(cfg config.Latency) func(mock *models.Mock) responseEffect {
+	return func(mock *models.Mock) responseEffect {
+		return responseEffect{delay: mockLatency(mock, cfg)}
+	}
+}
+
+// mockLatency returns the recorded latency of the mock scaled by the factor of its kind, the mocks recorded
+// without their timestamps are not delayed.
+func mockLatency(mock *models.Mock, cfg config.Latency) time.Duration {
+	if mock.Spec.ReqTimestampMock.IsZero() || mock.Spec.ResTimestampMock.IsZero() {
+		return 0
+	}
+	latency := mock.Spec.ResTimestampMock.Sub(mock.Spec.ReqTimestampMock)
+	if latency <= 0 {
+		return 0
+	}
+	return time.Duration(float64(latency) * cfg.KindFactor(string(mock.Kind)))
+}
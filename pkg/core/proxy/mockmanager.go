@@ -18,6 +18,13 @@ type MockManager struct {
 	unfiltered    *TreeDb
 	logger        *zap.Logger
 	consumedMocks sync.Map
+	// unmatchedCalls are the outgoing calls which couldn't be served from the mocks
+	unmatchedCalls []models.UnmatchedCall
+	unmatchedMu    sync.Mutex
+	// faults are injected into the mocks matching them, injectedFaults records them
+	faults         []*chaosFault
+	injectedFaults []models.InjectedFault
+	faultsMu       sync.Mutex
 }
 
 func NewMockManager(filtered, unfiltered *TreeDb, logger *zap.Logger) *MockManager {
@@ -79,11 +86,9 @@ func (m *MockManager) UpdateUnFilteredMock(old *models.Mock, new *models.Mock) b
 	updated := m.unfiltered.update(old.TestModeInfo, new.TestModeInfo, new)
 	if updated {
 		// mark the unfiltered mock as used for the current simulated test-case
-		go func() {
-			if err := m.FlagMockAsUsed(*old); err != nil {
-				m.logger.Error("failed to flag mock as used", zap.Error(err))
-			}
-		}()
+		if err := m.FlagMockAsUsed(*old); err != nil {
+			m.logger.Error("failed to flag mock as used", zap.Error(err))
+		}
 	}
 	return updated
 }
@@ -99,11 +104,10 @@ func (m *MockManager) FlagMockAsUsed(mock models.Mock) error {
 func (m *MockManager) DeleteFilteredMock(mock models.Mock) bool {
 	isDeleted := m.filtered.delete(mock.TestModeInfo)
 	if isDeleted {
-		go func() {
-			if err := m.FlagMockAsUsed(mock); err != nil {
-				m.logger.Error("failed to flag mock as used", zap.Error(err))
-			}
-		}()
+		// the mock is flagged before the response is sent, so that it is counted for the current test-case
+		if err := m.FlagMockAsUsed(mock); err != nil {
+			m.logger.Error("failed to flag mock as used", zap.Error(err))
+		}
 	}
 	return isDeleted
 }
@@ -111,11 +115,10 @@ func (m *MockManager) DeleteFilteredMock(mock models.Mock) bool {
 func (m *MockManager) DeleteUnFilteredMock(mock models.Mock) bool {
 	isDeleted := m.unfiltered.delete(mock.TestModeInfo)
 	if isDeleted {
-		go func() {
-			if err := m.FlagMockAsUsed(mock); err != nil {
-				m.logger.Error("failed to flag mock as used", zap.Error(err))
-			}
-		}()
+		// the mock is flagged before the response is sent, so that it is counted for the current test-case
+		if err := m.FlagMockAsUsed(mock); err != nil {
+			m.logger.Error("failed to flag mock as used", zap.Error(err))
+		}
 	}
 	return isDeleted
 }
@@ -139,3 +142,54 @@ func (m *MockManager) GetConsumedMocks() []string {
 	}
 	return keys
 }
+
+// FlagCallAsUnmatched records the outgoing call which couldn't be served from the mocks
+func (m *MockManager) FlagCallAsUnmatched(call models.UnmatchedCall) {
+	m.unmatchedMu.Lock()
+	defer m.unmatchedMu.Unlock()
+	m.unmatchedCalls = append(m.unmatchedCalls, call)
+}
+
+// GetUnmatchedCalls returns the outgoing calls which couldn't be served from the mocks since the last call
+func (m *MockManager) GetUnmatchedCalls() []models.UnmatchedCall {
+	m.unmatchedMu.Lock()
+	defer m.unmatchedMu.Unlock()
+	calls := m.unmatchedCalls
+	m.unmatchedCalls = nil
+	return calls
+}
+
+// SetFaults sets the faults injected into the mocks served to the app
+func (m *MockManager) SetFaults(faults []models.Fault) error {
+	compiled, err := compileFaults(faults)
+	if err != nil {
+		return err
+	}
+	m.faultsMu.Lock()
+	defer m.faultsMu.Unlock()
+	m.faults = compiled
+	m.injectedFaults = nil
+	return nil
+}
+
+func (m *MockManager) getFaults() []*chaosFault {
+	m.faultsMu.Lock()
+	defer m.faultsMu.Unlock()
+	return m.faults
+}
+
+// FlagFaultAsInjected records the fault injected into a mock served to the app
+func (m *MockManager) FlagFaultAsInjected(fault models.InjectedFault) {
+	m.faultsMu.Lock()
+	defer m.faultsMu.Unlock()
+	m.injectedFaults = append(m.injectedFaults, fault)
+}
+
+// GetInjectedFaults returns the faults injected into the mocks since the last call
+func (m *MockManager) GetInjectedFaults() []models.InjectedFault {
+	m.faultsMu.Lock()
+	defer m.faultsMu.Unlock()
+	faults := m.injectedFaults
+	m.injectedFaults = nil
+	return faults
+}
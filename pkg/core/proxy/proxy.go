@@ -386,8 +386,13 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 			return err
 		}
 
+		var mockDb integrations.MockMemDb = m.(*MockManager)
+		if hooks := mockDbHooks(m.(*MockManager), rule.OutgoingOptions, dstAddr); !hooks.empty() {
+			srcConn, mockDb = withMockHooks(parserCtx, srcConn, mockDb, hooks)
+		}
+
 		//mock the outgoing message
-		err := p.Integrations["mysql"].MockOutgoing(parserCtx, srcConn, &integrations.ConditionalDstCfg{Addr: dstAddr}, m.(*MockManager), rule.OutgoingOptions)
+		err := p.Integrations["mysql"].MockOutgoing(parserCtx, srcConn, &integrations.ConditionalDstCfg{Addr: dstAddr}, mockDb, rule.OutgoingOptions)
 		if err != nil {
 			utils.LogError(p.logger, err, "failed to mock the outgoing message")
 			return err
@@ -482,6 +487,13 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 		return err
 	}
 
+	var mockDb integrations.MockMemDb = m.(*MockManager)
+	if rule.Mode != models.MODE_RECORD {
+		if hooks := mockDbHooks(m.(*MockManager), rule.OutgoingOptions, dstCfg.Addr); !hooks.empty() {
+			srcConn, mockDb = withMockHooks(parserCtx, srcConn, mockDb, hooks)
+		}
+	}
+
 	generic := true
 
 	//Checking for all the parsers.
@@ -494,7 +506,7 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 					return err
 				}
 			} else {
-				err := parser.MockOutgoing(parserCtx, srcConn, dstCfg, m.(*MockManager), rule.OutgoingOptions)
+				err := parser.MockOutgoing(parserCtx, srcConn, dstCfg, mockDb, rule.OutgoingOptions)
 				if err != nil && err != io.EOF {
 					utils.LogError(logger, err, "failed to mock the outgoing message")
 					return err
@@ -513,7 +525,7 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 				return err
 			}
 		} else {
-			err := p.Integrations["generic"].MockOutgoing(parserCtx, srcConn, dstCfg, m.(*MockManager), rule.OutgoingOptions)
+			err := p.Integrations["generic"].MockOutgoing(parserCtx, srcConn, dstCfg, mockDb, rule.OutgoingOptions)
 			if err != nil {
 				utils.LogError(logger, err, "failed to mock the outgoing message")
 				return err
@@ -617,6 +629,48 @@ func (p *Proxy) SetMocks(_ context.Context, id uint64, filtered []*models.Mock,
 	return nil
 }
 
+// mockDbHooks returns the hooks of the mocks served on a conn to the destination, for the replay of their
+// latency and the faults of the chaos config.
+func mockDbHooks(m *MockManager, opts models.OutgoingOptions, destination string) mockHooks {
+	var hooks mockHooks
+	if opts.Latency.Enable {
+		hooks.consumed = append(hooks.consumed, latencyHook(opts.Latency))
+	}
+	if faults := m.getFaults(); len(faults) > 0 {
+		faultHooks(&hooks, m, faults, destination)
+	}
+	return hooks
+}
+
+// GetUnmatchedCalls returns the outgoing calls of the app which couldn't be served from the mocks since the
+// last call, for a given app id
+func (p *Proxy) GetUnmatchedCalls(_ context.Context, id uint64) ([]models.UnmatchedCall, error) {
+	m, ok := p.MockManagers.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("mock manager not found to get the unmatched calls")
+	}
+	return m.(*MockManager).GetUnmatchedCalls(), nil
+}
+
+// SetFaults sets the faults injected into the mocks served to the app, for a given app id
+func (p *Proxy) SetFaults(_ context.Context, id uint64, faults []models.Fault) error {
+	m, ok := p.MockManagers.Load(id)
+	if !ok {
+		return fmt.Errorf("mock manager not found to set the faults")
+	}
+	return m.(*MockManager).SetFaults(faults)
+}
+
+// GetInjectedFaults returns the faults injected into the mocks served to the app since the last call, for a
+// given app id
+func (p *Proxy) GetInjectedFaults(_ context.Context, id uint64) ([]models.InjectedFault, error) {
+	m, ok := p.MockManagers.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("mock manager not found to get the injected faults")
+	}
+	return m.(*MockManager).GetInjectedFaults(), nil
+}
+
 // GetConsumedMocks returns the consumed filtered mocks for a given app id
 func (p *Proxy) GetConsumedMocks(_ context.Context, id uint64) ([]string, error) {
 	m, ok := p.MockManagers.Load(id)
@@ -43,6 +43,9 @@ type Proxy interface {
 	Mock(ctx context.Context, id uint64, opts models.OutgoingOptions) error
 	SetMocks(ctx context.Context, id uint64, filtered []*models.Mock, unFiltered []*models.Mock) error
 	GetConsumedMocks(ctx context.Context, id uint64) ([]string, error)
+	GetUnmatchedCalls(ctx context.Context, id uint64) ([]models.UnmatchedCall, error)
+	SetFaults(ctx context.Context, id uint64, faults []models.Fault) error
+	GetInjectedFaults(ctx context.Context, id uint64) ([]models.InjectedFault, error)
 }
 
 type ProxyOptions struct {
@@ -0,0 +1,136 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocolbuffers/protoscope"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+)
+
+// SimulateGRPC sends the grpc request of the testcase to the app over a cleartext http2 connection and returns
+// the headers, the message and the trailers of the response.
+func SimulateGRPC(ctx context.Context, tc models.TestCase, testSet string, logger *zap.Logger, apiTimeout uint64) (*models.GrpcResp, error) {
+	logger.Info("starting test for of", zap.Any("test case", models.HighlightString(tc.Name)), zap.Any("test set", models.HighlightString(testSet)))
+
+	payload, err := CreatePayloadFromLengthPrefixedMessage(tc.GrpcReq.Body)
+	if err != nil {
+		utils.LogError(logger, err, "failed to create the grpc message from the yaml document")
+		return nil, err
+	}
+
+	url := fmt.Sprintf("http://%s%s", tc.GrpcReq.Headers.PseudoHeaders[":authority"], tc.GrpcReq.Headers.PseudoHeaders[":path"])
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		utils.LogError(logger, err, "failed to create a grpc request from the yaml document")
+		return nil, err
+	}
+	for k, v := range tc.GrpcReq.Headers.OrdinaryHeaders {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("KEPLOY-TEST-ID", tc.Name)
+	logger.Debug(fmt.Sprintf("Sending grpc request to user app:%v", req))
+
+	client := &http.Client{
+		Timeout: time.Second * time.Duration(apiTimeout),
+		Transport: &http2.Transport{
+			// the grpc servers of the apps are plain text (h2c), there is no tls handshake
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		utils.LogError(logger, err, "failed to send testcase request to app")
+		return nil, err
+	}
+	defer func() {
+		if err := httpResp.Body.Close(); err != nil {
+			utils.LogError(logger, err, "failed to close the grpc response body")
+		}
+	}()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		utils.LogError(logger, err, "failed reading response body")
+		return nil, err
+	}
+
+	resp := models.NewGrpcStream(0).GrpcResp
+	resp.Headers.PseudoHeaders[":status"] = strconv.Itoa(httpResp.StatusCode)
+	for k, v := range httpResp.Header {
+		resp.Headers.OrdinaryHeaders[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+	// the trailers are only read along with the body
+	for k, v := range httpResp.Trailer {
+		resp.Trailers.OrdinaryHeaders[strings.ToLower(k)] = strings.Join(v, ",")
+	}
+	if len(respBody) == 0 && len(httpResp.Trailer) == 0 {
+		// the errors are sent as a single headers frame which ends the stream, it is recorded as the trailers
+		resp.Headers, resp.Trailers = resp.Trailers, resp.Headers
+	}
+	resp.Body = CreateLengthPrefixedMessageFromPayload(respBody)
+
+	return &resp, nil
+}
+
+// CreateLengthPrefixedMessageFromPayload decodes the length prefixed grpc message of a DATA frame, the
+// message is decoded into the protoscope text format.
+func CreateLengthPrefixedMessageFromPayload(data []byte) models.GrpcLengthPrefixedMessage {
+	msg := models.GrpcLengthPrefixedMessage{}
+
+	// If the body is not length prefixed, we return the default value.
+	if len(data) < 5 {
+		return msg
+	}
+
+	// The first byte is the compression flag.
+	msg.CompressionFlag = uint(data[0])
+
+	// The next 4 bytes are message length.
+	msg.MessageLength = binary.BigEndian.Uint32(data[1:5])
+
+	// The payload could be empty. We only parse it if it is present.
+	if len(data) >= 5 {
+		// Use protoscope to decode the message.
+		msg.DecodedData = protoscope.Write(data[5:], protoscope.WriterOptions{})
+	}
+
+	return msg
+}
+
+// CreatePayloadFromLengthPrefixedMessage encodes the grpc message back into the payload of a DATA frame.
+func CreatePayloadFromLengthPrefixedMessage(msg models.GrpcLengthPrefixedMessage) ([]byte, error) {
+	scanner := protoscope.NewScanner(msg.DecodedData)
+	encodedData, err := scanner.Exec()
+	if err != nil {
+		return nil, fmt.Errorf("could not encode grpc msg using protoscope: %v", err)
+	}
+
+	// Note that the encoded length is present in the msg, but it is also equal to the len of encodedData.
+	// We should give the preference to the length of encodedData, since the mocks might have been altered.
+
+	// Reserve 1 byte for compression flag, 4 bytes for length capture.
+	payload := make([]byte, 1+4)
+	payload[0] = uint8(msg.CompressionFlag)
+	binary.BigEndian.PutUint32(payload[1:5], uint32(len(encodedData)))
+	payload = append(payload, encodedData...)
+
+	return payload, nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// FaultType is the failure injected into the mocks matching a fault.
+type FaultType string
+
+// constants for the fault types
+const (
+	// FaultError replaces the response of the mock with an error of its protocol: an http 5xx, a grpc status,
+	// a postgres ErrorResponse, a mysql ERR packet or a redis error. The conns of the other kinds are reset.
+	FaultError FaultType = "error"
+	// FaultReset closes the conn of the app instead of writing the response of the mock
+	FaultReset FaultType = "reset"
+	// FaultTruncate writes the first half of the response of the mock and closes the conn
+	FaultTruncate FaultType = "truncate"
+	// FaultLatency delays the response of the mock
+	FaultLatency FaultType = "latency"
+)
+
+// Fault is injected by the proxy into the mocks it matches while the test cases of the test set are run, to
+// test the resilience of the app. The matchers left empty match any mock.
+type Fault struct {
+	// Name identifies the fault in the report
+	Name string    `json:"name" bson:"name" yaml:"name"`
+	Type FaultType `json:"type" bson:"type" yaml:"type"`
+	// Host is the host of the destination, or of the url of the http and grpc mocks
+	Host string `json:"host" bson:"host" yaml:"host,omitempty"`
+	Port uint   `json:"port" bson:"port" yaml:"port,omitempty"`
+	// Kind is the kind of the mocks, e.g. Http or Postgres
+	Kind string `json:"kind" bson:"kind" yaml:"kind,omitempty"`
+	// URL is a regex matching the urls of the http mocks and the paths of the grpc ones
+	URL string `json:"url" bson:"url" yaml:"url,omitempty"`
+	// Status is the http status, the grpc status code or the mysql error code of the error faults
+	Status int `json:"status" bson:"status" yaml:"status,omitempty"`
+	// SQLState is the SQLSTATE of the postgres and mysql error faults
+	SQLState string `json:"sql_state" bson:"sql_state" yaml:"sql_state,omitempty"`
+	// Message is the message of the error faults
+	Message string `json:"message" bson:"message" yaml:"message,omitempty"`
+	// Latency is the delay added by the latency faults, e.g. 2s
+	Latency time.Duration `json:"latency" bson:"latency" yaml:"latency,omitempty"`
+}
+
+// InjectedFault is a fault which was injected into a mock served to a test case.
+type InjectedFault struct {
+	Fault       string    `json:"fault" yaml:"fault"`
+	Type        FaultType `json:"type" yaml:"type"`
+	Mock        string    `json:"mock" yaml:"mock"`
+	Kind        string    `json:"kind" yaml:"kind"`
+	Destination string    `json:"destination" yaml:"destination"`
+}
@@ -5,4 +5,24 @@ type TestSet struct {
 	PreScript  string            `json:"pre_script" bson:"pre_script" yaml:"pre_script"`
 	PostScript string            `json:"post_script" bson:"post_script" yaml:"post_script"`
 	Template   map[string]string `json:"template" bson:"template" yaml:"template"`
+	// Extract declares the values captured from the responses of the test cases, which are used by the
+	// {{ }} placeholders in the requests of the following test cases
+	Extract []Extraction `json:"extract" bson:"extract" yaml:"extract,omitempty"`
+	// Denoise holds the noise proposed for the test cases by replaying them twice, it is added to the test
+	// cases once reviewed, with keploy denoise --apply
+	Denoise []NoiseParams `json:"denoise" bson:"denoise" yaml:"denoise,omitempty"`
+	// Chaos lists the faults injected into the mocks served to the test cases of the test set
+	Chaos []Fault `json:"chaos" bson:"chaos" yaml:"chaos,omitempty"`
+}
+
+// Extraction captures a value of the actual response of a test case into a template variable.
+type Extraction struct {
+	// Name is the template variable which is set
+	Name string `json:"name" bson:"name" yaml:"name"`
+	// TestCase is the test case whose response is read, every test case is read when it is empty
+	TestCase string `json:"test_case" bson:"test_case" yaml:"test_case,omitempty"`
+	// Path locates the value in the response: body.<json path>, header.<name> or status
+	Path string `json:"path" bson:"path" yaml:"path"`
+	// Regex optionally narrows the value down to its first capture group (or to its match)
+	Regex string `json:"regex" bson:"regex" yaml:"regex,omitempty"`
 }
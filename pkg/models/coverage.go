@@ -0,0 +1,21 @@
+package models
+
+// TestCaseCoverage is the code of the app executed by a test case. It is captured by flushing the coverage
+// counters of the app after the request of the test case, so it holds the lines run for this request only.
+// Commit is the git commit checked out when it was captured, and Dirty tells whether the checkout had
+// uncommitted changes, the lines match the ones of the commit only when it is clean.
+type TestCaseCoverage struct {
+	TestSet  string         `json:"testSet" yaml:"test_set"`
+	TestCase string         `json:"testCase" yaml:"test_case"`
+	Commit   string         `json:"commit,omitempty" yaml:"commit,omitempty"`
+	Dirty    bool           `json:"dirty,omitempty" yaml:"dirty,omitempty"`
+	Files    []FileCoverage `json:"files" yaml:"files"`
+}
+
+// FileCoverage is the lines of a source file covered by a test case. Path is the one of the coverage profile,
+// the import path of the package followed by the file name, and Lines is a comma separated list of line
+// ranges, e.g. 12-18,20.
+type FileCoverage struct {
+	Path  string `json:"path" yaml:"path"`
+	Lines string `json:"lines" yaml:"lines"`
+}
@@ -9,6 +9,9 @@ type GrpcSpec struct {
 	GrpcResp         GrpcResp  `json:"grpcResp" yaml:"grpcResp"`
 	ReqTimestampMock time.Time `json:"reqTimestampMock" yaml:"reqTimestampMock,omitempty"`
 	ResTimestampMock time.Time `json:"resTimestampMock" yaml:"resTimestampMock,omitempty"`
+	// Created and Assertions are only set for the grpc testcases
+	Created    int64                  `json:"created" yaml:"created,omitempty"`
+	Assertions map[string]interface{} `json:"assertions" yaml:"assertions,omitempty"`
 }
 
 type GrpcHeaders struct {
@@ -25,12 +28,16 @@ type GrpcLengthPrefixedMessage struct {
 type GrpcReq struct {
 	Headers GrpcHeaders               `json:"headers" yaml:"headers"`
 	Body    GrpcLengthPrefixedMessage `json:"body" yaml:"body"`
+	// Timestamp is captured for the requests of the grpc testcases
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp,omitempty"`
 }
 
 type GrpcResp struct {
 	Headers  GrpcHeaders               `json:"headers" yaml:"headers"`
 	Body     GrpcLengthPrefixedMessage `json:"body" yaml:"body"`
 	Trailers GrpcHeaders               `json:"trailers" yaml:"trailers"`
+	// Timestamp is captured for the responses of the grpc testcases
+	Timestamp time.Time `json:"timestamp" yaml:"timestamp,omitempty"`
 }
 
 // GrpcStream is a helper function to combine the request-response model in a single struct.
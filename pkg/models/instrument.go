@@ -15,9 +15,11 @@ type OutgoingOptions struct {
 	Rules         []config.BypassRule
 	MongoPassword string
 	// TODO: role of SQLDelay should be mentioned in the comments.
-	SQLDelay       time.Duration // This is the same as Application delay.
-	FallBackOnMiss bool          // this enables to pass the request to the actual server if no mock is found during test mode.
-	Mocking        bool          // used to enable/disable mocking
+	SQLDelay       time.Duration  // This is the same as Application delay.
+	FallBackOnMiss bool           // this enables to pass the request to the actual server if no mock is found during test mode.
+	Mocking        bool           // used to enable/disable mocking
+	StrictMocks    bool           // this fails the outgoing calls which no mock matches during test mode, instead of falling back.
+	Latency        config.Latency // this delays the responses of the mocks by their recorded latency during test mode.
 }
 
 type IncomingOptions struct {
@@ -28,8 +30,14 @@ type SetupOptions struct {
 	Container     string
 	DockerNetwork string
 	DockerDelay   uint64
+	// Env holds the KEY=value variables exported to the command of a native application
+	Env []string
 }
 
+// AppIDEnv is exported to the native applications to tell which app the outgoing connections of their
+// processes belong to when several apps are hooked at once.
+const AppIDEnv = "KEPLOY_APP_ID"
+
 type RunOptions struct {
 	//IgnoreErrors bool
 }
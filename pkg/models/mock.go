@@ -17,6 +17,18 @@ type TestModeInfo struct {
 	SortOrder  int  `json:"sortOrder,omitempty" bson:"SortOrder,omitempty"`
 }
 
+// UnmatchedCall is an outgoing call of the app, in the test mode, which couldn't be served from the mocks.
+type UnmatchedCall struct {
+	// Kind is the integration which parsed the call, e.g. http or mongo
+	Kind string `json:"kind" yaml:"kind"`
+	// Destination is the address the call was sent to
+	Destination string `json:"destination" yaml:"destination"`
+	// Payload is the decoded request of the call, when the integration could decode it
+	Payload string `json:"payload,omitempty" yaml:"payload,omitempty"`
+	// ClosestMock is the name of the mock of the same kind which is the closest to the call
+	ClosestMock string `json:"closestMock,omitempty" yaml:"closestMock,omitempty"`
+}
+
 func (m *Mock) GetKind() string {
 	return string(m.Kind)
 }
@@ -1,5 +1,7 @@
 package models
 
+import "go.keploy.io/server/v2/config"
+
 type Kind string
 type BodyType string
 type Version string
@@ -34,6 +36,13 @@ const (
 	BodyTypePlain  BodyType = "PLAIN"
 	BodyTypeJSON   BodyType = "JSON"
 	BodyTypeError  BodyType = "ERROR"
+	BodyTypeXML    BodyType = "XML"
+	BodyTypeHTML   BodyType = "HTML"
+	// BodyTypeForm is the type of the application/x-www-form-urlencoded bodies
+	BodyTypeForm      BodyType = "FORM"
+	BodyTypeMultipart BodyType = "MULTIPART"
+	// BodyTypeProtoscope is the type of the grpc messages, decoded into the protoscope text format
+	BodyTypeProtoscope BodyType = "PROTOSCOPE"
 )
 
 type TestCase struct {
@@ -53,6 +62,31 @@ type TestCase struct {
 	Mocks    []*Mock             `json:"mocks" bson:"mocks"`
 	Type     string              `json:"type" bson:"type"`
 	Curl     string              `json:"curl" bson:"curl"`
+	// Assertions are the checks on the response declared in the assertions of the test case
+	Assertions []Assertion `json:"assertions" bson:"assertions"`
+	// AssertionsOnly skips the comparison of the response body, the response is checked by the assertions
+	AssertionsOnly bool `json:"assertions_only" bson:"assertions_only"`
+	// SchemaMatch compares only the structure and the value types of the json response body
+	SchemaMatch bool `json:"schema_match" bson:"schema_match"`
+	// Tolerances map the fields of the response, like the noise, to the tolerances within which they are compared
+	Tolerances map[string][]config.Tolerance `json:"tolerances" bson:"tolerances"`
+}
+
+// Assertion is a declarative check on the actual response of a test case. Every check which is set is
+// evaluated on the value at the path.
+type Assertion struct {
+	// Path locates the value: status, header.<name>, response_time (in milliseconds), body, body.<path>
+	// or a JSONPath into the body like $.items[0].id
+	Path   string      `json:"path" yaml:"path"`
+	Equals interface{} `json:"equals,omitempty" yaml:"equals,omitempty"`
+	Regex  string      `json:"regex,omitempty" yaml:"regex,omitempty"`
+	// Type is one of string, number, boolean, array, object or null
+	Type string   `json:"type,omitempty" yaml:"type,omitempty"`
+	Min  *float64 `json:"min,omitempty" yaml:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty" yaml:"max,omitempty"`
+	// Length is the number of elements of an array or object, or the number of characters of a string
+	Length *int  `json:"length,omitempty" yaml:"length,omitempty"`
+	Exists *bool `json:"exists,omitempty" yaml:"exists,omitempty"`
 }
 
 func (tc *TestCase) GetKind() string {
@@ -60,10 +94,10 @@ func (tc *TestCase) GetKind() string {
 }
 
 type NoiseParams struct {
-	TestCaseIDs string              `json:"testCaseIDs"`
-	EditedBy    string              `json:"editedBy"`
-	Assertion   map[string][]string `json:"assertion"`
-	Ops         string              `json:"ops"`
+	TestCaseIDs string              `json:"testCaseIDs" yaml:"testCaseIDs"`
+	EditedBy    string              `json:"editedBy" yaml:"editedBy"`
+	Assertion   map[string][]string `json:"assertion" yaml:"assertion"`
+	Ops         string              `json:"ops" yaml:"ops"`
 }
 
 // enum for ops
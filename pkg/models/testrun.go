@@ -13,6 +13,8 @@ type TestReport struct {
 	Total   int          `json:"total" yaml:"total"`
 	Tests   []TestResult `json:"tests" yaml:"tests,omitempty"`
 	TestSet string       `json:"testSet" yaml:"test_set"`
+	// Flaky lists the test cases which passed only on a retry, they are counted in Success as well
+	Flaky []string `json:"flaky" yaml:"flaky,omitempty"`
 }
 
 func (tr *TestReport) GetKind() string {
@@ -32,6 +34,15 @@ type TestResult struct {
 	Res          HTTPResp   `json:"resp" yaml:"resp,omitempty"`
 	Noise        Noise      `json:"noise" yaml:"noise,omitempty"`
 	Result       Result     `json:"result" yaml:"result"`
+	// ConsumedMocks are the names of the mocks used by the failed test case
+	ConsumedMocks []string `json:"consumedMocks" yaml:"consumed_mocks,omitempty"`
+	// Retries is the number of times the test case was re-run after failing
+	Retries int `json:"retries" yaml:"retries,omitempty"`
+	// GrpcReq and GrpcRes are set instead of Req and Res for the grpc test cases
+	GrpcReq GrpcReq  `json:"grpcReq" yaml:"grpc_req,omitempty"`
+	GrpcRes GrpcResp `json:"grpcResp" yaml:"grpc_resp,omitempty"`
+	// Faults are the faults of the chaos config of the test set injected into the mocks of the test case
+	Faults []InjectedFault `json:"faults" yaml:"faults,omitempty"`
 }
 
 func (tr *TestResult) GetKind() string {
@@ -78,6 +89,17 @@ type Result struct {
 	HeadersResult []HeaderResult `json:"headers_result" bson:"headers_result" yaml:"headers_result"`
 	BodyResult    []BodyResult   `json:"body_result" bson:"body_result" yaml:"body_result"`
 	DepResult     []DepResult    `json:"dep_result" bson:"dep_result" yaml:"dep_result"`
+	// AssertionResult has a result for every check of the assertions of the test case
+	AssertionResult []AssertionResult `json:"assertion_result" bson:"assertion_result" yaml:"assertion_result,omitempty"`
+}
+
+type AssertionResult struct {
+	Normal bool   `json:"normal" bson:"normal" yaml:"normal"`
+	Path   string `json:"path" bson:"path" yaml:"path"`
+	// Check is the evaluated check of the assertion: equals, regex, type, min, max, length or exists
+	Check    string `json:"check" bson:"check" yaml:"check"`
+	Expected string `json:"expected" bson:"expected" yaml:"expected"`
+	Actual   string `json:"actual" bson:"actual" yaml:"actual"`
 }
 
 type DepResult struct {
@@ -86,6 +108,14 @@ type DepResult struct {
 	Meta []DepMetaResult `json:"meta" bson:"meta" yaml:"meta"`
 }
 
+// keys of the DepMetaResult of the outgoing calls of a test case
+const (
+	// DepConsumed checks that a mock recorded along with the test case is consumed by its replay
+	DepConsumed = "consumed"
+	// DepUnmatched is an outgoing call of the replay which didn't match any mock
+	DepUnmatched = "unmatched"
+)
+
 type DepMetaResult struct {
 	Normal   bool   `json:"normal" bson:"normal" yaml:"normal"`
 	Key      string `json:"key" bson:"key" yaml:"key"`
@@ -125,6 +155,8 @@ const (
 	TestStatusRunning TestStatus = "RUNNING"
 	TestStatusFailed  TestStatus = "FAILED"
 	TestStatusPassed  TestStatus = "PASSED"
+	// TestStatusFlaky is the status of a test case which failed but passed on a retry
+	TestStatusFlaky TestStatus = "FLAKY"
 )
 
 type (
@@ -0,0 +1,195 @@
+//go:build linux
+
+// Package mockdb provides a sqlite backed storage for the mocks.
+package mockdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	yamlMockdb "go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+type MockSQLite struct {
+	db        *sql.DB
+	logger    *zap.Logger
+	idCounter int64
+}
+
+func New(logger *zap.Logger, db *sql.DB) *MockSQLite {
+	return &MockSQLite{
+		db:        db,
+		logger:    logger,
+		idCounter: -1,
+	}
+}
+
+// UpdateMocks deletes the mocks of the test set which are not present in mockNames
+//
+// mockNames is a map which contains the name of the mocks as key and a isConfig boolean as value
+func (ms *MockSQLite) UpdateMocks(ctx context.Context, testSetID string, mockNames map[string]bool) error {
+	ms.logger.Debug("logging the names of the unused mocks to be removed", zap.Any("mockNames", mockNames), zap.Any("for testset", testSetID))
+
+	mocks, err := ms.GetAllMocks(ctx, testSetID)
+	if err != nil {
+		return err
+	}
+	var newMocks []*models.Mock
+	for _, mock := range mocks {
+		if _, ok := mockNames[mock.Name]; ok {
+			newMocks = append(newMocks, mock)
+		}
+	}
+	ms.logger.Debug("logging the names of the used mocks", zap.Any("mockNames", newMocks), zap.Any("for testset", testSetID))
+
+	return ms.ReplaceMocks(ctx, testSetID, newMocks)
+}
+
+func (ms *MockSQLite) InsertMock(ctx context.Context, mock *models.Mock, testSetID string) error {
+	mock.Name = fmt.Sprint("mock-", ms.getNextID())
+	return ms.insert(ctx, ms.db, mock, testSetID)
+}
+
+// GetAllMocks returns every mock of the test set in the order in which they were inserted.
+func (ms *MockSQLite) GetAllMocks(ctx context.Context, testSetID string) ([]*models.Mock, error) {
+	return ms.queryMocks(ctx, testSetID, `SELECT doc FROM mocks WHERE test_set_id = ? ORDER BY seq`, testSetID)
+}
+
+// ReplaceMocks replaces all the mocks of the test set with the given mocks. Names of the mocks are kept as they are.
+func (ms *MockSQLite) ReplaceMocks(ctx context.Context, testSetID string, mocks []*models.Mock) error {
+	tx, err := ms.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM mocks WHERE test_set_id = ?`, testSetID)
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to delete the mocks from sqlite", zap.String("testset", testSetID))
+		return err
+	}
+	for _, mock := range mocks {
+		err = ms.insert(ctx, tx, mock, testSetID)
+		if err != nil {
+			utils.LogError(ms.logger, err, "failed to write the mock to sqlite", zap.Any("mock", mock.Name), zap.Any("for testset", testSetID))
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetFilteredMocks returns the mocks recorded for the test cases between afterTime and beforeTime,
+// only the mocks inside the time window are read from the database.
+func (ms *MockSQLite) GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
+	query := `SELECT doc FROM mocks WHERE test_set_id = ? AND unfiltered = 0`
+	args := []any{testSetID}
+	if afterTime != (time.Time{}) && beforeTime != (time.Time{}) {
+		query += ` AND (req_timestamp = 0 OR res_timestamp = 0 OR (req_timestamp > ? AND res_timestamp < ?))`
+		args = append(args, afterTime.UnixNano(), beforeTime.UnixNano())
+	}
+	tcsMocks, err := ms.queryMocks(ctx, testSetID, query+` ORDER BY seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	filteredTcsMocks, _ := yamlMockdb.FilterByTimeStamp(tcsMocks, afterTime, beforeTime, ms.logger)
+
+	sort.SliceStable(filteredTcsMocks, func(i, j int) bool {
+		return filteredTcsMocks[i].Spec.ReqTimestampMock.Before(filteredTcsMocks[j].Spec.ReqTimestampMock)
+	})
+
+	return filteredTcsMocks, nil
+}
+
+func (ms *MockSQLite) GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
+	configMocks, err := ms.queryMocks(ctx, testSetID, `SELECT doc FROM mocks WHERE test_set_id = ? AND unfiltered = 1 ORDER BY seq`, testSetID)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredMocks, unfilteredMocks := yamlMockdb.FilterByTimeStamp(configMocks, afterTime, beforeTime, ms.logger)
+
+	sort.SliceStable(filteredMocks, func(i, j int) bool {
+		return filteredMocks[i].Spec.ReqTimestampMock.Before(filteredMocks[j].Spec.ReqTimestampMock)
+	})
+
+	sort.SliceStable(unfilteredMocks, func(i, j int) bool {
+		return unfilteredMocks[i].Spec.ReqTimestampMock.Before(unfilteredMocks[j].Spec.ReqTimestampMock)
+	})
+
+	return append(filteredMocks, unfilteredMocks...), nil
+}
+
+func (ms *MockSQLite) queryMocks(ctx context.Context, testSetID string, query string, args ...any) ([]*models.Mock, error) {
+	rows, err := ms.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to query the mocks from sqlite", zap.String("testset", testSetID))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mockYamls []*yaml.NetworkTrafficDoc
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var mockYaml *yaml.NetworkTrafficDoc
+		err = yamlLib.Unmarshal([]byte(doc), &mockYaml)
+		if err != nil {
+			utils.LogError(ms.logger, err, "failed to unmarshal the mock yaml document", zap.String("testset", testSetID))
+			return nil, err
+		}
+		mockYamls = append(mockYamls, mockYaml)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	mocks, err := yamlMockdb.DecodeMocks(mockYamls, ms.logger)
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to decode the mocks from yaml docs", zap.Any("session", testSetID))
+		return nil, err
+	}
+	return mocks, nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func (ms *MockSQLite) insert(ctx context.Context, db execer, mock *models.Mock, testSetID string) error {
+	mockYaml, err := yamlMockdb.EncodeMock(mock, ms.logger)
+	if err != nil {
+		return err
+	}
+	data, err := yamlLib.Marshal(&mockYaml)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, `INSERT INTO mocks (test_set_id, seq, name, kind, unfiltered, req_timestamp, res_timestamp, doc)
+		VALUES (?, (SELECT COALESCE(MAX(seq), -1) + 1 FROM mocks WHERE test_set_id = ?), ?, ?, ?, ?, ?, ?)`,
+		testSetID, testSetID, mock.Name, string(mock.Kind), yamlMockdb.IsUnFilteredMock(mock), unixNano(mock.Spec.ReqTimestampMock), unixNano(mock.Spec.ResTimestampMock), string(data))
+	return err
+}
+
+// unixNano returns 0 for the missing timestamps so that they can be matched in the queries
+func unixNano(t time.Time) int64 {
+	if t == (time.Time{}) {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func (ms *MockSQLite) getNextID() int64 {
+	return atomic.AddInt64(&ms.idCounter, 1)
+}
@@ -0,0 +1,126 @@
+//go:build linux
+
+// Package reportdb provides a sqlite backed storage for the test reports.
+package reportdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+type TestReport struct {
+	tests  map[string]map[string][]models.TestResult
+	m      sync.Mutex
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func New(logger *zap.Logger, db *sql.DB) *TestReport {
+	return &TestReport{
+		tests:  make(map[string]map[string][]models.TestResult),
+		m:      sync.Mutex{},
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (tr *TestReport) GetAllTestRunIDs(ctx context.Context) ([]string, error) {
+	return tr.queryIDs(ctx, `SELECT DISTINCT test_run_id FROM reports ORDER BY test_run_id`)
+}
+
+// GetAllTestSetIDs returns the ids of the test sets for which a report exists in the given test run.
+func (tr *TestReport) GetAllTestSetIDs(ctx context.Context, testRunID string) ([]string, error) {
+	return tr.queryIDs(ctx, `SELECT test_set_id FROM reports WHERE test_run_id = ? ORDER BY test_set_id`, testRunID)
+}
+
+func (tr *TestReport) InsertTestCaseResult(_ context.Context, testRunID string, testSetID string, result *models.TestResult) error {
+	tr.m.Lock()
+	defer tr.m.Unlock()
+
+	testSet := tr.tests[testRunID]
+	if testSet == nil {
+		testSet = make(map[string][]models.TestResult)
+		testSet[testSetID] = []models.TestResult{*result}
+	} else {
+		testSet[testSetID] = append(testSet[testSetID], *result)
+	}
+	tr.tests[testRunID] = testSet
+	return nil
+}
+
+func (tr *TestReport) GetTestCaseResults(_ context.Context, testRunID string, testSetID string) ([]models.TestResult, error) {
+	tr.m.Lock()
+	defer tr.m.Unlock()
+
+	testRun, ok := tr.tests[testRunID]
+	if !ok {
+		return []models.TestResult{}, fmt.Errorf("%s found no test results for test report with id: %s", utils.Emoji, testRunID)
+	}
+	testSetResults, ok := testRun[testSetID]
+	if !ok {
+		return []models.TestResult{}, fmt.Errorf("%s found no test results for test set with id: %s", utils.Emoji, testSetID)
+	}
+	return testSetResults, nil
+}
+
+func (tr *TestReport) GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error) {
+	var doc string
+	err := tr.db.QueryRowContext(ctx, `SELECT doc FROM reports WHERE test_run_id = ? AND test_set_id = ?`, testRunID, testSetID).Scan(&doc)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%s found no report for test set %s in test run %s", utils.Emoji, testSetID, testRunID)
+	}
+	if err != nil {
+		utils.LogError(tr.logger, err, "failed to read the report from sqlite", zap.String("testRunID", testRunID), zap.String("testSetID", testSetID))
+		return nil, err
+	}
+	var report models.TestReport
+	err = yamlLib.Unmarshal([]byte(doc), &report)
+	if err != nil {
+		return &models.TestReport{}, fmt.Errorf("%s failed to decode the report. error: %v", utils.Emoji, err.Error())
+	}
+	return &report, nil
+}
+
+func (tr *TestReport) InsertReport(ctx context.Context, testRunID string, testSetID string, testReport *models.TestReport) error {
+	if testReport.Name == "" {
+		testReport.Name = testSetID + "-report"
+	}
+
+	data, err := yamlLib.Marshal(&testReport)
+	if err != nil {
+		return fmt.Errorf("%s failed to marshal document to yaml. error: %s", utils.Emoji, err.Error())
+	}
+	_, err = tr.db.ExecContext(ctx, `INSERT INTO reports (test_run_id, test_set_id, doc) VALUES (?, ?, ?)
+		ON CONFLICT (test_run_id, test_set_id) DO UPDATE SET doc = excluded.doc`, testRunID, testSetID, string(data))
+	if err != nil {
+		utils.LogError(tr.logger, err, "failed to write the report to sqlite", zap.String("testRunID", testRunID), zap.String("testSetID", testSetID))
+		return err
+	}
+	return nil
+}
+
+func (tr *TestReport) queryIDs(ctx context.Context, query string, args ...any) ([]string, error) {
+	rows, err := tr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
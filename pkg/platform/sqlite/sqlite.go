@@ -0,0 +1,75 @@
+//go:build linux
+
+// Package sqlite provides a sqlite backed storage for the testcases, mocks and reports.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	// registers the pure go sqlite driver
+	_ "modernc.org/sqlite"
+)
+
+// DBName is the name of the sqlite database file created inside the keploy directory.
+const DBName = "keploy.db"
+
+// every row stores the same yaml document which the yaml storage writes on the disk, so that the
+// data can be converted from one storage to the other without any loss.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS test_cases (
+		test_set_id   TEXT NOT NULL,
+		name          TEXT NOT NULL,
+		kind          TEXT NOT NULL,
+		req_timestamp INTEGER NOT NULL,
+		doc           TEXT NOT NULL,
+		PRIMARY KEY (test_set_id, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS mocks (
+		test_set_id   TEXT NOT NULL,
+		seq           INTEGER NOT NULL,
+		name          TEXT NOT NULL,
+		kind          TEXT NOT NULL,
+		unfiltered    INTEGER NOT NULL,
+		req_timestamp INTEGER NOT NULL,
+		res_timestamp INTEGER NOT NULL,
+		doc           TEXT NOT NULL,
+		PRIMARY KEY (test_set_id, seq)
+	)`,
+	`CREATE INDEX IF NOT EXISTS mocks_window ON mocks (test_set_id, unfiltered, req_timestamp)`,
+	`CREATE TABLE IF NOT EXISTS reports (
+		test_run_id TEXT NOT NULL,
+		test_set_id TEXT NOT NULL,
+		doc         TEXT NOT NULL,
+		PRIMARY KEY (test_run_id, test_set_id)
+	)`,
+}
+
+// Open opens (and creates if required) the sqlite database inside the given keploy directory.
+func Open(ctx context.Context, logger *zap.Logger, path string) (*sql.DB, error) {
+	err := os.MkdirAll(path, 0777)
+	if err != nil {
+		utils.LogError(logger, err, "failed to create the directory for the sqlite database", zap.String("path", path))
+		return nil, err
+	}
+	dbPath := filepath.Join(path, DBName)
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the sqlite database at %s: %v", dbPath, err)
+	}
+	// sqlite allows a single writer at a time
+	db.SetMaxOpenConns(1)
+	for _, stmt := range schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to create the sqlite schema: %v", err)
+		}
+	}
+	logger.Debug("opened the sqlite database", zap.String("path", dbPath))
+	return db, nil
+}
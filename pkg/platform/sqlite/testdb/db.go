@@ -0,0 +1,167 @@
+//go:build linux
+
+// Package testdb provides a sqlite backed storage for the testcases.
+package testdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	yamlTestdb "go.keploy.io/server/v2/pkg/platform/yaml/testdb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+type TestSQLite struct {
+	db     *sql.DB
+	logger *zap.Logger
+}
+
+func New(logger *zap.Logger, db *sql.DB) *TestSQLite {
+	return &TestSQLite{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (ts *TestSQLite) InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error {
+	name, err := ts.upsert(ctx, testSetID, tc)
+	if err != nil {
+		return err
+	}
+	// the name given to a new testcase is available to the caller
+	tc.Name = name
+
+	ts.logger.Info("🟠 Keploy has captured test cases for the user's application.", zap.String("testset", testSetID), zap.String("testcase name", name))
+
+	return nil
+}
+
+func (ts *TestSQLite) GetAllTestSetIDs(ctx context.Context) ([]string, error) {
+	rows, err := ts.db.QueryContext(ctx, `SELECT test_set_id FROM test_cases UNION SELECT test_set_id FROM mocks ORDER BY test_set_id`)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to query the test sets from sqlite")
+		return nil, err
+	}
+	defer rows.Close()
+
+	var testSetIDs []string
+	for rows.Next() {
+		var testSetID string
+		if err := rows.Scan(&testSetID); err != nil {
+			return nil, err
+		}
+		testSetIDs = append(testSetIDs, testSetID)
+	}
+	return testSetIDs, rows.Err()
+}
+
+func (ts *TestSQLite) GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error) {
+	rows, err := ts.db.QueryContext(ctx, `SELECT doc FROM test_cases WHERE test_set_id = ? ORDER BY req_timestamp, name`, testSetID)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to query the testcases from sqlite", zap.String("testset", testSetID))
+		return nil, err
+	}
+	defer rows.Close()
+
+	tcs := []*models.TestCase{}
+	for rows.Next() {
+		var doc string
+		if err := rows.Scan(&doc); err != nil {
+			return nil, err
+		}
+		var testCase *yaml.NetworkTrafficDoc
+		err = yamlLib.Unmarshal([]byte(doc), &testCase)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to unmarshall YAML data")
+			return nil, err
+		}
+		tc, err := yamlTestdb.Decode(testCase, ts.logger)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to decode the testcase")
+			return nil, err
+		}
+		tcs = append(tcs, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(tcs) == 0 {
+		ts.logger.Debug("no tests are recorded for the session", zap.String("index", testSetID))
+		return nil, nil
+	}
+	return tcs, nil
+}
+
+func (ts *TestSQLite) UpdateTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error {
+	name, err := ts.upsert(ctx, testSetID, tc)
+	if err != nil {
+		return err
+	}
+
+	ts.logger.Info("🔄 Keploy has updated the test cases for the user's application.", zap.String("testset", testSetID), zap.String("testcase name", name))
+	return nil
+}
+
+func (ts *TestSQLite) upsert(ctx context.Context, testSetID string, tc *models.TestCase) (string, error) {
+	tcsName := tc.Name
+	if tcsName == "" {
+		lastIndx, err := ts.findLastIndex(ctx, testSetID)
+		if err != nil {
+			return "", err
+		}
+		tcsName = fmt.Sprintf("test-%v", lastIndx)
+	}
+	yamlTc, err := yamlTestdb.EncodeTestcase(*tc, ts.logger)
+	if err != nil {
+		return tcsName, err
+	}
+	yamlTc.Name = tcsName
+	data, err := yamlLib.Marshal(&yamlTc)
+	if err != nil {
+		return tcsName, err
+	}
+	_, err = ts.db.ExecContext(ctx, `INSERT INTO test_cases (test_set_id, name, kind, req_timestamp, doc) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (test_set_id, name) DO UPDATE SET kind = excluded.kind, req_timestamp = excluded.req_timestamp, doc = excluded.doc`,
+		testSetID, tcsName, string(tc.Kind), yamlTestdb.RequestTimestamp(tc).UnixNano(), string(data))
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to write testcase to sqlite")
+		return tcsName, err
+	}
+	return tcsName, nil
+}
+
+// findLastIndex returns the index for the new testcase of the test set, similar to the yaml file names.
+func (ts *TestSQLite) findLastIndex(ctx context.Context, testSetID string) (int, error) {
+	rows, err := ts.db.QueryContext(ctx, `SELECT name FROM test_cases WHERE test_set_id = ?`, testSetID)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	lastIndex := 0
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return 0, err
+		}
+		nameParts := strings.Split(name, "-")
+		if len(nameParts) != 2 || nameParts[0] != "test" {
+			continue
+		}
+		indx, err := strconv.Atoi(nameParts[1])
+		if err != nil {
+			continue
+		}
+		if indx > lastIndex {
+			lastIndex = indx
+		}
+	}
+	return lastIndex + 1, rows.Err()
+}
@@ -0,0 +1,124 @@
+//go:build linux
+
+// Package coveragedb stores the coverage of the test cases along with the reports of the test runs.
+package coveragedb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// fileSuffix is the suffix of the files holding the coverage of the test cases of a test set
+const fileSuffix = "-coverage"
+
+type CoverageDB struct {
+	logger *zap.Logger
+	path   string
+	m      sync.Mutex
+}
+
+func New(logger *zap.Logger, reportPath string) *CoverageDB {
+	return &CoverageDB{
+		logger: logger,
+		path:   reportPath,
+	}
+}
+
+// InsertCoverage appends the coverage of the test case to the coverage file of its test set in the report
+// directory of the test run.
+func (db *CoverageDB) InsertCoverage(ctx context.Context, testRunID string, testSetID string, coverage *models.TestCaseCoverage) error {
+	db.m.Lock()
+	defer db.m.Unlock()
+
+	data, err := yamlLib.Marshal(coverage)
+	if err != nil {
+		return fmt.Errorf("%s failed to marshal the coverage of the test case to yaml. error: %s", utils.Emoji, err.Error())
+	}
+	reportPath := filepath.Join(db.path, testRunID)
+	err = yaml.WriteFile(ctx, db.logger, reportPath, testSetID+fileSuffix, data, true)
+	if err != nil {
+		utils.LogError(db.logger, err, "failed to write the coverage of the test case", zap.String("testcase", coverage.TestCase), zap.String("testset", testSetID))
+		return err
+	}
+	return nil
+}
+
+// GetAllTestRunIDs returns the test runs for which the coverage of the test cases was captured.
+func (db *CoverageDB) GetAllTestRunIDs(ctx context.Context) ([]string, error) {
+	testRunIDs, err := yaml.ReadSessionIndices(ctx, db.path, db.logger)
+	if err != nil {
+		return nil, err
+	}
+	var withCoverage []string
+	for _, testRunID := range testRunIDs {
+		testSetIDs, err := db.GetAllTestSetIDs(ctx, testRunID)
+		if err != nil {
+			return nil, err
+		}
+		if len(testSetIDs) > 0 {
+			withCoverage = append(withCoverage, testRunID)
+		}
+	}
+	return withCoverage, nil
+}
+
+// GetAllTestSetIDs returns the test sets of the test run for which the coverage of the test cases was captured.
+func (db *CoverageDB) GetAllTestSetIDs(_ context.Context, testRunID string) ([]string, error) {
+	path, err := yaml.ValidatePath(filepath.Join(db.path, testRunID))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed to read the coverage of test run %s. error: %v", utils.Emoji, testRunID, err.Error())
+	}
+	var testSetIDs []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileSuffix+".yaml") {
+			continue
+		}
+		testSetIDs = append(testSetIDs, strings.TrimSuffix(entry.Name(), fileSuffix+".yaml"))
+	}
+	sort.Strings(testSetIDs)
+	return testSetIDs, nil
+}
+
+// GetCoverage returns the coverage of the test cases of the test set, in the order they were run.
+func (db *CoverageDB) GetCoverage(ctx context.Context, testRunID string, testSetID string) ([]*models.TestCaseCoverage, error) {
+	path := filepath.Join(db.path, testRunID)
+	data, err := yaml.ReadFile(ctx, db.logger, path, testSetID+fileSuffix)
+	if err != nil {
+		return nil, err
+	}
+	var coverages []*models.TestCaseCoverage
+	decoder := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var coverage models.TestCaseCoverage
+		err := decoder.Decode(&coverage)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s failed to decode the coverage of test set %s. error: %v", utils.Emoji, testSetID, err.Error())
+		}
+		if coverage.TestSet == "" {
+			coverage.TestSet = testSetID
+		}
+		coverages = append(coverages, &coverage)
+	}
+	return coverages, nil
+}
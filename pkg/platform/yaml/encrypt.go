@@ -0,0 +1,270 @@
+package yaml
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// EncryptionAlgorithm is used to encrypt both the specs of the documents and their data keys.
+const EncryptionAlgorithm = "AES-256-GCM"
+
+// keySize is the size in bytes of the master key and of the data keys
+const keySize = 32
+
+// Envelope describes the encryption of the spec of a document. The spec is encrypted with a random data
+// key of its own, which is stored wrapped (encrypted) with the master key identified by KeyID.
+type Envelope struct {
+	Algorithm string `json:"algorithm" yaml:"algorithm"`
+	KeyID     string `json:"keyId" yaml:"keyId"`
+	DataKey   string `json:"dataKey" yaml:"dataKey"`
+}
+
+// encryptedSection is the part of a document which is encrypted, the curl command is included as it
+// carries the request of the testcase.
+type encryptedSection struct {
+	Spec yamlLib.Node `yaml:"spec"`
+	Curl string       `yaml:"curl,omitempty"`
+}
+
+// Cipher encrypts and decrypts the specs of the documents with a master key. A nil Cipher means that the
+// encryption is disabled: the documents are written in clear text and the encrypted ones cannot be read.
+type Cipher struct {
+	key   []byte
+	keyID string
+}
+
+// NewCipher returns a cipher for the given 256 bit master key.
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes long, found %d bytes", keySize, len(key))
+	}
+	sum := sha256.Sum256(key)
+	return &Cipher{key: key, keyID: hex.EncodeToString(sum[:8])}, nil
+}
+
+// KeyID identifies the master key of the cipher without revealing it.
+func (c *Cipher) KeyID() string {
+	if c == nil {
+		return ""
+	}
+	return c.keyID
+}
+
+// ParseKey decodes a master key given as base64 or hex.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(s); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("encryption key must be a base64 or hex encoded %d byte key", keySize)
+}
+
+// LoadKey reads the master key from the env variable, or from the key file when the variable is not set.
+func LoadKey(env, file string) ([]byte, error) {
+	if env != "" {
+		if v, ok := os.LookupEnv(env); ok && v != "" {
+			key, err := ParseKey(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid encryption key in %s: %v", env, err)
+			}
+			return key, nil
+		}
+	}
+	if file == "" {
+		return nil, fmt.Errorf("encryption key is not configured, set the %s env variable or the encryption key file", env)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the encryption key file: %v", err)
+	}
+	key, err := ParseKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key in %s: %v", file, err)
+	}
+	return key, nil
+}
+
+// GenerateKey returns a new random master key encoded in base64.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptDoc replaces the spec and the curl command of the document with their ciphertext. The name, kind
+// and version of the document are kept in clear text so that the files can still be listed and indexed.
+// References to the shared mock pool carry no spec and are left as they are.
+func (c *Cipher) EncryptDoc(doc *NetworkTrafficDoc) error {
+	if c == nil || doc.Encryption != nil || doc.Ref != nil {
+		return nil
+	}
+	plain, err := yamlLib.Marshal(&encryptedSection{Spec: doc.Spec, Curl: doc.Curl})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the spec of %s: %v", doc.Name, err)
+	}
+	dataKey := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return err
+	}
+	spec, err := seal(dataKey, plain, []byte(doc.Kind))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt the spec of %s: %v", doc.Name, err)
+	}
+	wrapped, err := seal(c.key, dataKey, []byte(c.keyID))
+	if err != nil {
+		return fmt.Errorf("failed to wrap the data key of %s: %v", doc.Name, err)
+	}
+	doc.Spec = yamlLib.Node{Kind: yamlLib.ScalarNode, Tag: "!!str", Value: spec}
+	doc.Curl = ""
+	doc.Encryption = &Envelope{
+		Algorithm: EncryptionAlgorithm,
+		KeyID:     c.keyID,
+		DataKey:   wrapped,
+	}
+	return nil
+}
+
+// DecryptDoc restores the spec and the curl command of an encrypted document, documents in clear text
+// are left as they are.
+func (c *Cipher) DecryptDoc(doc *NetworkTrafficDoc) error {
+	env := doc.Encryption
+	if env == nil {
+		return nil
+	}
+	if c == nil {
+		return fmt.Errorf("%s is encrypted, enable the encryption and configure its key to read it", doc.Name)
+	}
+	if env.Algorithm != EncryptionAlgorithm {
+		return fmt.Errorf("%s is encrypted with unsupported algorithm %q", doc.Name, env.Algorithm)
+	}
+	if env.KeyID != c.keyID {
+		return fmt.Errorf("%s is encrypted with the key %s but the configured key is %s", doc.Name, env.KeyID, c.keyID)
+	}
+	dataKey, err := open(c.key, env.DataKey, []byte(env.KeyID))
+	if err != nil {
+		return fmt.Errorf("failed to unwrap the data key of %s: %v", doc.Name, err)
+	}
+	plain, err := open(dataKey, doc.Spec.Value, []byte(doc.Kind))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt the spec of %s: %v", doc.Name, err)
+	}
+	var section encryptedSection
+	if err := yamlLib.Unmarshal(plain, &section); err != nil {
+		return fmt.Errorf("failed to unmarshal the decrypted spec of %s: %v", doc.Name, err)
+	}
+	doc.Spec = section.Spec
+	doc.Curl = section.Curl
+	doc.Encryption = nil
+	return nil
+}
+
+// DecodeDocs decodes the yaml documents in data and decrypts the encrypted ones with the cipher.
+func (c *Cipher) DecodeDocs(data []byte) ([]*NetworkTrafficDoc, error) {
+	var docs []*NetworkTrafficDoc
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc *NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
+		}
+		if doc == nil {
+			continue
+		}
+		if err := c.DecryptDoc(doc); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// RotateDocs re-encrypts the documents in data with the next cipher and returns the names of the rotated
+// documents. Documents in clear text are encrypted as well, the ones already encrypted with the key of the
+// next cipher are left as they are so that an interrupted rotation can be resumed.
+func (c *Cipher) RotateDocs(data []byte, next *Cipher) ([]*NetworkTrafficDoc, []string, error) {
+	var docs []*NetworkTrafficDoc
+	var rotated []string
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc *NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
+		}
+		if doc == nil {
+			continue
+		}
+		docs = append(docs, doc)
+		if doc.Ref != nil || doc.Encryption != nil && doc.Encryption.KeyID == next.KeyID() {
+			continue
+		}
+		if err := c.DecryptDoc(doc); err != nil {
+			return nil, nil, err
+		}
+		if err := next.EncryptDoc(doc); err != nil {
+			return nil, nil, err
+		}
+		rotated = append(rotated, doc.Name)
+	}
+	return docs, rotated, nil
+}
+
+// seal encrypts the plaintext and returns the nonce followed by the ciphertext encoded in base64.
+func seal(key, plaintext, additionalData []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, additionalData)), nil
+}
+
+func open(key []byte, ciphertext string, additionalData []byte) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
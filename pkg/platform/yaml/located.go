@@ -0,0 +1,95 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// LocatedDoc is a decoded document of a yaml file along with its position in the file. Err is set when the
+// document cannot be decoded, Value is the zero value for the documents skipped by the decoder.
+type LocatedDoc[T any] struct {
+	File string
+	Line int
+	// Node is the mapping node of the document, it is nil when the file cannot be parsed
+	Node  *yamlLib.Node
+	Value T
+	Err   error
+}
+
+// yamlErrLine extracts the line from the errors of the yaml parser
+var yamlErrLine = regexp.MustCompile(`line ([0-9]+)`)
+
+// DecodeLocated decodes the documents of the yaml file data along with their lines. The documents are
+// decrypted with the cipher before decode converts them into their values. A file which cannot be parsed
+// returns a single document holding the parse error.
+func DecodeLocated[T any](file string, data []byte, c *Cipher, decode func(doc *NetworkTrafficDoc) (T, error)) []LocatedDoc[T] {
+	var docs []LocatedDoc[T]
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var node yamlLib.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			line := 1
+			if m := yamlErrLine.FindStringSubmatch(err.Error()); m != nil {
+				line, _ = strconv.Atoi(m[1])
+			}
+			return append(docs, LocatedDoc[T]{File: file, Line: line, Err: err})
+		}
+		if node.Kind == yamlLib.DocumentNode && len(node.Content) > 0 {
+			node = *node.Content[0]
+		}
+		located := LocatedDoc[T]{File: file, Line: node.Line, Node: &node}
+		var doc *NetworkTrafficDoc
+		if err := node.Decode(&doc); err != nil {
+			located.Err = err
+		} else if doc == nil {
+			continue
+		} else if err := c.DecryptDoc(doc); err != nil {
+			located.Err = err
+		} else if _, err := UpgradeDoc(doc); err != nil {
+			// the documents of an unsupported schema version are only decoded on a best effort basis when
+			// replaying, they are reported here
+			located.Err = err
+		} else {
+			located.Value, located.Err = decode(doc)
+		}
+		docs = append(docs, located)
+	}
+	return docs
+}
+
+// KeyLine returns the line of the value at the path of keys in the mapping node, the keys are matched
+// case-insensitively. The line of the deepest key found is returned when the path does not exist.
+func KeyLine(node *yamlLib.Node, path ...string) int {
+	if node == nil {
+		return 0
+	}
+	line := node.Line
+	for _, key := range path {
+		if node.Kind != yamlLib.MappingNode {
+			return line
+		}
+		found := false
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, key) {
+				line = node.Content[i].Line
+				node = node.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return line
+		}
+	}
+	return line
+}
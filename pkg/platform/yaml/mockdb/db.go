@@ -4,14 +4,12 @@
 package mockdb
 
 import (
-	"bytes"
 	"context"
-	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -27,14 +25,24 @@ type MockYaml struct {
 	MockName  string
 	Logger    *zap.Logger
 	idCounter int64
+	// cipher encrypts the specs of the written mocks, it is nil when the encryption is disabled
+	cipher *yaml.Cipher
+	// mu serialises the writes to the mock file as the index stores the offsets of the mocks
+	mu     sync.Mutex
+	index  indexCache
+	shared sharedPool
 }
 
-func New(Logger *zap.Logger, mockPath string, mockName string) *MockYaml {
+func New(Logger *zap.Logger, mockPath string, mockName string, cipher *yaml.Cipher) *MockYaml {
 	return &MockYaml{
 		MockPath:  mockPath,
 		MockName:  mockName,
 		Logger:    Logger,
 		idCounter: -1,
+		cipher:    cipher,
+		index: indexCache{
+			indices: make(map[string]*mockIndex),
+		},
 	}
 }
 
@@ -42,10 +50,7 @@ func New(Logger *zap.Logger, mockPath string, mockName string) *MockYaml {
 //
 // mockNames is a map which contains the name of the mocks as key and a isConfig boolean as value
 func (ys *MockYaml) UpdateMocks(ctx context.Context, testSetID string, mockNames map[string]bool) error {
-	mockFileName := "mocks"
-	if ys.MockName != "" {
-		mockFileName = ys.MockName
-	}
+	mockFileName := ys.mockFileName()
 	path := filepath.Join(ys.MockPath, testSetID)
 	ys.Logger.Debug("logging the names of the unused mocks to be removed", zap.Any("mockNames", mockNames), zap.Any("for testset", testSetID), zap.Any("at path", filepath.Join(path, mockFileName+".yaml")))
 
@@ -59,61 +64,103 @@ func (ys *MockYaml) UpdateMocks(ctx context.Context, testSetID string, mockNames
 		utils.LogError(ys.Logger, err, "failed to find the mocks yaml file")
 		return err
 	}
+
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+
+	// the documents are filtered as they are, so that the references to the shared mock pool are kept
 	data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
 	if err != nil {
-		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.Any("at path", filepath.Join(path, mockFileName+".yaml")))
+		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.Any("at path", mockPath))
 		return err
 	}
-
-	// decode the mocks read from the yaml file
-	dec := yamlLib.NewDecoder(bytes.NewReader(data))
-	var mockYamls []*yaml.NetworkTrafficDoc
-	for {
-		var doc *yaml.NetworkTrafficDoc
-		err := dec.Decode(&doc)
-		if errors.Is(err, io.EOF) {
-			break
+	docs, err := ys.cipher.DecodeDocs(data)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to decode the yaml file documents", zap.Any("at path", mockPath))
+		return err
+	}
+	var usedDocs []*yaml.NetworkTrafficDoc
+	var usedNames []string
+	for _, doc := range docs {
+		if _, ok := mockNames[doc.Name]; ok {
+			usedDocs = append(usedDocs, doc)
+			usedNames = append(usedNames, doc.Name)
 		}
+	}
+	ys.Logger.Debug("logging the names of the used mocks", zap.Any("mockNames", usedNames), zap.Any("for testset", testSetID))
+
+	if len(usedDocs) == 0 {
+		err = os.Remove(mockPath)
+	} else {
+		data, err = yaml.EncodeDocs(usedDocs, ys.cipher)
 		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to decode the yaml file documents", zap.Any("at path", filepath.Join(path, mockFileName+".yaml")))
-			return fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
+			return err
 		}
-		mockYamls = append(mockYamls, doc)
+		err = yaml.WriteFile(ctx, ys.Logger, path, mockFileName, data, false)
 	}
-	mocks, err := decodeMocks(mockYamls, ys.Logger)
 	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to write the used mocks", zap.Any("at path", mockPath))
 		return err
 	}
-	var newMocks []*models.Mock
-	for _, mock := range mocks {
-		if _, ok := mockNames[mock.Name]; ok {
-			newMocks = append(newMocks, mock)
-			continue
-		}
+	// the offsets of the mocks have changed, the index is rebuilt on the next read
+	return ys.removeIndex(testSetID)
+}
+
+// GetAllMocks returns every mock of the test set in the order in which they are stored in the mock file.
+func (ys *MockYaml) GetAllMocks(ctx context.Context, testSetID string) ([]*models.Mock, error) {
+	mockFileName := ys.mockFileName()
+	path := filepath.Join(ys.MockPath, testSetID)
+	mockPath, err := yaml.ValidatePath(filepath.Join(path, mockFileName+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(mockPath); err != nil {
+		return []*models.Mock{}, nil
+	}
+
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.Any("at path", mockPath))
+		return nil, err
 	}
-	ys.Logger.Debug("logging the names of the used mocks", zap.Any("mockNames", newMocks), zap.Any("for testset", testSetID))
 
-	// remove the old mock yaml file
-	err = os.Remove(filepath.Join(path, mockFileName+".yaml"))
+	// decode the mocks read from the yaml file
+	mockYamls, err := ys.cipher.DecodeDocs(data)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to decode the yaml file documents", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	mocks, err := ys.decodeMocks(ctx, mockYamls)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to decode the mocks from yaml docs", zap.Any("session", testSetID))
+		return nil, err
+	}
+	return mocks, nil
+}
+
+// ReplaceMocks rewrites the mock file of the test set with the given mocks. Names of the mocks are kept as they are.
+func (ys *MockYaml) ReplaceMocks(ctx context.Context, testSetID string, mocks []*models.Mock) error {
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+
+	mockFileName := ys.mockFileName()
+	path := filepath.Join(ys.MockPath, testSetID)
+
+	// remove the old mock yaml file along with its index
+	err := os.Remove(filepath.Join(path, mockFileName+".yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err = ys.removeIndex(testSetID)
 	if err != nil {
 		return err
 	}
 
 	// write the new mocks to the new yaml file
-	for _, newMock := range newMocks {
-		mockYaml, err := EncodeMock(newMock, ys.Logger)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to encode the mock to yaml", zap.Any("mock", newMock.Name), zap.Any("for testset", testSetID))
-			return err
-		}
-		data, err = yamlLib.Marshal(&mockYaml)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to marshal the mock to yaml", zap.Any("mock", newMock.Name), zap.Any("for testset", testSetID))
-			return err
-		}
-		err = yaml.WriteFile(ctx, ys.Logger, path, mockFileName, data, true)
+	for _, mock := range mocks {
+		err = ys.appendMock(ctx, mock, testSetID)
 		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to write the mock to yaml", zap.Any("mock", newMock.Name), zap.Any("for testset", testSetID))
+			utils.LogError(ys.Logger, err, "failed to write the mock to yaml", zap.Any("mock", mock.Name), zap.Any("for testset", testSetID))
 			return err
 		}
 	}
@@ -121,85 +168,59 @@ func (ys *MockYaml) UpdateMocks(ctx context.Context, testSetID string, mockNames
 }
 
 func (ys *MockYaml) InsertMock(ctx context.Context, mock *models.Mock, testSetID string) error {
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+
 	mock.Name = fmt.Sprint("mock-", ys.getNextID())
+	return ys.appendMock(ctx, mock, testSetID)
+}
+
+// appendMock writes the mock at the end of the mock file and adds it to the index of the test set.
+func (ys *MockYaml) appendMock(ctx context.Context, mock *models.Mock, testSetID string) error {
 	mockYaml, err := EncodeMock(mock, ys.Logger)
 	if err != nil {
 		return err
 	}
-	mockPath := filepath.Join(ys.MockPath, testSetID)
-	mockFileName := ys.MockName
-	if mockFileName == "" {
-		mockFileName = "mocks"
+	err = ys.cipher.EncryptDoc(mockYaml)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to encrypt the mock", zap.Any("mock", mock.Name))
+		return err
 	}
+	mockPath := filepath.Join(ys.MockPath, testSetID)
+	mockFileName := ys.mockFileName()
 	data, err := yamlLib.Marshal(&mockYaml)
 	if err != nil {
 		return err
 	}
+
+	// offset at which yaml.WriteFile appends the document
+	var offset int64
+	if info, err := os.Stat(filepath.Join(mockPath, mockFileName+".yaml")); err == nil {
+		offset = info.Size() + int64(len(docSeparator))
+	}
 	err = yaml.WriteFile(ctx, ys.Logger, mockPath, mockFileName, data, true)
 	if err != nil {
 		return err
 	}
+	err = ys.appendIndex(ctx, testSetID, newIndexEntry(mock, offset, int64(len(data))))
+	if err != nil {
+		// the index is rebuilt from the mock file when it does not cover the whole file
+		ys.Logger.Debug("failed to update the mock index", zap.String("testSetID", testSetID), zap.Error(err))
+	}
 	return nil
 }
 
+// GetFilteredMocks returns the mocks recorded for the test cases between afterTime and beforeTime.
+// Only the mocks inside the time window are read from the mock file with the help of the mock index.
 func (ys *MockYaml) GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
 
-	var tcsMocks = make([]*models.Mock, 0)
-	var filteredTcsMocks = make([]*models.Mock, 0)
-	mockFileName := "mocks"
-	if ys.MockName != "" {
-		mockFileName = ys.MockName
-	}
-
-	path := filepath.Join(ys.MockPath, testSetID)
-	mockPath, err := yaml.ValidatePath(path + "/" + mockFileName + ".yaml")
+	tcsMocks, err := ys.loadMocks(ctx, testSetID, func(e *IndexEntry) bool {
+		return !e.UnFiltered && e.inWindow(afterTime, beforeTime)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	if _, err := os.Stat(mockPath); err == nil {
-		var mockYamls []*yaml.NetworkTrafficDoc
-		data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to read the mocks from config yaml", zap.Any("session", filepath.Base(path)))
-			return nil, err
-		}
-		dec := yamlLib.NewDecoder(bytes.NewReader(data))
-		for {
-			var doc *yaml.NetworkTrafficDoc
-			err := dec.Decode(&doc)
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
-			}
-			mockYamls = append(mockYamls, doc)
-		}
-		mocks, err := decodeMocks(mockYamls, ys.Logger)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to decode the config mocks from yaml docs", zap.Any("session", filepath.Base(path)))
-			return nil, err
-		}
-
-		for _, mock := range mocks {
-			isFilteredMock := true
-			switch mock.Kind {
-			case "Generic":
-				isFilteredMock = false
-			case "Postgres":
-				isFilteredMock = false
-			case "Http":
-				isFilteredMock = false
-			case "Redis":
-				isFilteredMock = false
-			}
-			if mock.Spec.Metadata["type"] != "config" && isFilteredMock {
-				tcsMocks = append(tcsMocks, mock)
-			}
-		}
-	}
-	filteredTcsMocks, _ = ys.filterByTimeStamp(ctx, tcsMocks, afterTime, beforeTime, ys.Logger)
+	filteredTcsMocks, _ := FilterByTimeStamp(tcsMocks, afterTime, beforeTime, ys.Logger)
 
 	sort.SliceStable(filteredTcsMocks, func(i, j int) bool {
 		return filteredTcsMocks[i].Spec.ReqTimestampMock.Before(filteredTcsMocks[j].Spec.ReqTimestampMock)
@@ -210,63 +231,14 @@ func (ys *MockYaml) GetFilteredMocks(ctx context.Context, testSetID string, afte
 
 func (ys *MockYaml) GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
 
-	var configMocks = make([]*models.Mock, 0)
-
-	mockName := "mocks"
-	if ys.MockName != "" {
-		mockName = ys.MockName
-	}
-
-	path := filepath.Join(ys.MockPath, testSetID)
-
-	mockPath, err := yaml.ValidatePath(path + "/" + mockName + ".yaml")
+	configMocks, err := ys.loadMocks(ctx, testSetID, func(e *IndexEntry) bool {
+		return e.UnFiltered
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err := os.Stat(mockPath); err == nil {
-		var mockYamls []*yaml.NetworkTrafficDoc
-		data, err := yaml.ReadFile(ctx, ys.Logger, path, mockName)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to read the mocks from config yaml", zap.Any("session", filepath.Base(path)))
-			return nil, err
-		}
-		dec := yamlLib.NewDecoder(bytes.NewReader(data))
-		for {
-			var doc *yaml.NetworkTrafficDoc
-			err := dec.Decode(&doc)
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
-			}
-			mockYamls = append(mockYamls, doc)
-		}
-		mocks, err := decodeMocks(mockYamls, ys.Logger)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to decode the config mocks from yaml docs", zap.Any("session", filepath.Base(path)))
-			return nil, err
-		}
-		for _, mock := range mocks {
-			isUnFilteredMock := false
-			switch mock.Kind {
-			case "Generic":
-				isUnFilteredMock = true
-			case "Postgres":
-				isUnFilteredMock = true
-			case "Http":
-				isUnFilteredMock = true
-			case "Redis":
-				isUnFilteredMock = true
-			}
-			if mock.Spec.Metadata["type"] == "config" || isUnFilteredMock {
-				configMocks = append(configMocks, mock)
-			}
-		}
-	}
-
-	filteredMocks, unfilteredMocks := ys.filterByTimeStamp(ctx, configMocks, afterTime, beforeTime, ys.Logger)
+	filteredMocks, unfilteredMocks := FilterByTimeStamp(configMocks, afterTime, beforeTime, ys.Logger)
 
 	sort.SliceStable(filteredMocks, func(i, j int) bool {
 		return filteredMocks[i].Spec.ReqTimestampMock.Before(filteredMocks[j].Spec.ReqTimestampMock)
@@ -289,42 +261,137 @@ func (ys *MockYaml) getNextID() int64 {
 	return atomic.AddInt64(&ys.idCounter, 1)
 }
 
-func (ys *MockYaml) filterByTimeStamp(_ context.Context, m []*models.Mock, afterTime time.Time, beforeTime time.Time, logger *zap.Logger) ([]*models.Mock, []*models.Mock) {
+func (ys *MockYaml) mockFileName() string {
+	if ys.MockName != "" {
+		return ys.MockName
+	}
+	return "mocks"
+}
 
-	filteredMocks := make([]*models.Mock, 0)
-	unfilteredMocks := make([]*models.Mock, 0)
+// MigrateMocks upgrades the mocks of the test set to the latest schema version and returns the names of
+// the upgraded mocks. The mock file is rewritten in place unless dryRun is set.
+func (ys *MockYaml) MigrateMocks(ctx context.Context, testSetID string, dryRun bool) ([]string, error) {
+	mockFileName := ys.mockFileName()
+	path := filepath.Join(ys.MockPath, testSetID)
+	mockPath, err := yaml.ValidatePath(filepath.Join(path, mockFileName+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(mockPath); err != nil {
+		return nil, nil
+	}
+
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
 
-	if afterTime == (time.Time{}) {
-		return m, unfilteredMocks
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	docs, upgraded, err := yaml.UpgradeDocs(data, ys.cipher)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to upgrade the mocks", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	if len(upgraded) == 0 {
+		return nil, nil
+	}
+	// the upgraded mocks must decode into the current layout before they are written back
+	if _, err := ys.decodeMocks(ctx, docs); err != nil {
+		return nil, fmt.Errorf("upgraded mocks of test set %s do not decode: %v", testSetID, err)
+	}
+	if dryRun {
+		return upgraded, nil
 	}
 
-	if beforeTime == (time.Time{}) {
-		return m, unfilteredMocks
+	data, err = yaml.EncodeDocs(docs, ys.cipher)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.WriteFile(ctx, ys.Logger, path, mockFileName, data, false)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to write the upgraded mocks", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	// the offsets of the mocks have changed, the index is rebuilt on the next read
+	if err := ys.removeIndex(testSetID); err != nil {
+		utils.LogError(ys.Logger, err, "failed to remove the mock index", zap.String("testSetID", testSetID))
+		return nil, err
 	}
+	return upgraded, nil
+}
 
-	isNonKeploy := false
+// RotateMocks re-encrypts the mocks of the test set with the next cipher and returns the names of the
+// rotated mocks.
+func (ys *MockYaml) RotateMocks(ctx context.Context, testSetID string, next *yaml.Cipher) ([]string, error) {
+	mockFileName := ys.mockFileName()
+	path := filepath.Join(ys.MockPath, testSetID)
+	mockPath, err := yaml.ValidatePath(filepath.Join(path, mockFileName+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(mockPath); err != nil {
+		return nil, nil
+	}
 
-	for _, mock := range m {
-		if mock.Version != "api.keploy.io/v1beta1" && mock.Version != "api.keploy.io/v1beta2" {
-			isNonKeploy = true
-		}
-		if mock.Spec.ReqTimestampMock == (time.Time{}) || mock.Spec.ResTimestampMock == (time.Time{}) {
-			logger.Debug("request or response timestamp of mock is missing")
-			mock.TestModeInfo.IsFiltered = true
-			filteredMocks = append(filteredMocks, mock)
-			continue
-		}
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
 
-		if mock.Spec.ReqTimestampMock.After(afterTime) && mock.Spec.ResTimestampMock.Before(beforeTime) {
-			mock.TestModeInfo.IsFiltered = true
-			filteredMocks = append(filteredMocks, mock)
-			continue
-		}
-		mock.TestModeInfo.IsFiltered = false
-		unfilteredMocks = append(unfilteredMocks, mock)
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	docs, rotated, err := ys.cipher.RotateDocs(data, next)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to re-encrypt the mocks", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	if len(rotated) == 0 {
+		return nil, nil
+	}
+
+	data, err = yaml.EncodeDocs(docs, next)
+	if err != nil {
+		return nil, err
 	}
-	if isNonKeploy {
-		ys.Logger.Debug("Few mocks in the mock File are not recorded by keploy ignoring them")
+	err = yaml.WriteFile(ctx, ys.Logger, path, mockFileName, data, false)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to write the re-encrypted mocks", zap.Any("at path", mockPath))
+		return nil, err
+	}
+	// the offsets of the mocks have changed, the index is rebuilt on the next read
+	if err := ys.removeIndex(testSetID); err != nil {
+		utils.LogError(ys.Logger, err, "failed to remove the mock index", zap.String("testSetID", testSetID))
+		return nil, err
+	}
+	return rotated, nil
+}
+
+// GetLocatedMocks decodes the mocks of the test set along with their position in the mock file. The mocks
+// which do not decode are returned with their error.
+func (ys *MockYaml) GetLocatedMocks(ctx context.Context, testSetID string) ([]yaml.LocatedDoc[*models.Mock], error) {
+	mockFileName := ys.mockFileName()
+	path := filepath.Join(ys.MockPath, testSetID)
+	mockPath, err := yaml.ValidatePath(filepath.Join(path, mockFileName+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(mockPath); err != nil {
+		return nil, nil
+	}
+
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.Any("at path", mockPath))
+		return nil, err
 	}
-	return filteredMocks, unfilteredMocks
+	return yaml.DecodeLocated(mockPath, data, ys.cipher, func(doc *yaml.NetworkTrafficDoc) (*models.Mock, error) {
+		mocks, err := ys.decodeMocks(ctx, []*yaml.NetworkTrafficDoc{doc})
+		if err != nil || len(mocks) == 0 {
+			return nil, err
+		}
+		return mocks[0], nil
+	}), nil
 }
@@ -0,0 +1,328 @@
+//go:build linux
+
+package mockdb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// IndexEntry locates a single mock document inside the mock file of a test set.
+type IndexEntry struct {
+	Name             string      `yaml:"name"`
+	Kind             models.Kind `yaml:"kind"`
+	Offset           int64       `yaml:"offset"`
+	Length           int64       `yaml:"length"`
+	UnFiltered       bool        `yaml:"unfiltered"`
+	ReqTimestampMock time.Time   `yaml:"reqTimestampMock,omitempty"`
+	ResTimestampMock time.Time   `yaml:"resTimestampMock,omitempty"`
+}
+
+// end returns the offset of the byte following the indexed document.
+func (e *IndexEntry) end() int64 {
+	return e.Offset + e.Length
+}
+
+// inWindow reports whether the mock was recorded between afterTime and beforeTime. Mocks without timestamps
+// and a zero window match as well, same as FilterByTimeStamp.
+func (e *IndexEntry) inWindow(afterTime, beforeTime time.Time) bool {
+	if afterTime == (time.Time{}) || beforeTime == (time.Time{}) {
+		return true
+	}
+	if e.ReqTimestampMock == (time.Time{}) || e.ResTimestampMock == (time.Time{}) {
+		return true
+	}
+	return e.ReqTimestampMock.After(afterTime) && e.ResTimestampMock.Before(beforeTime)
+}
+
+// mockIndex is the in-memory copy of the index file of a test set along with
+// the size of the mock file it was built for.
+type mockIndex struct {
+	entries  []IndexEntry
+	fileSize int64
+}
+
+type indexCache struct {
+	mu      sync.Mutex
+	indices map[string]*mockIndex
+}
+
+func (ys *MockYaml) indexFileName() string {
+	return ys.mockFileName() + ".index"
+}
+
+// appendIndex adds the entry of a mock which was just appended to the mock file. No index is started for a
+// mock file which already had mocks, as it would not cover them, the full index is built on the next read.
+func (ys *MockYaml) appendIndex(ctx context.Context, testSetID string, entry IndexEntry) error {
+	if entry.Offset > 0 {
+		indexPath := filepath.Join(ys.MockPath, testSetID, ys.indexFileName()+".yaml")
+		if _, err := os.Stat(indexPath); os.IsNotExist(err) {
+			return nil
+		}
+	}
+	data, err := yamlLib.Marshal(&entry)
+	if err != nil {
+		return err
+	}
+	err = yaml.WriteFile(ctx, ys.Logger, filepath.Join(ys.MockPath, testSetID), ys.indexFileName(), data, true)
+	if err != nil {
+		return err
+	}
+
+	ys.index.mu.Lock()
+	defer ys.index.mu.Unlock()
+	if idx, ok := ys.index.indices[testSetID]; ok {
+		idx.entries = append(idx.entries, entry)
+		idx.fileSize = entry.end()
+	}
+	return nil
+}
+
+// removeIndex deletes the index of the test set, it is rebuilt on the next read.
+func (ys *MockYaml) removeIndex(testSetID string) error {
+	ys.index.mu.Lock()
+	delete(ys.index.indices, testSetID)
+	ys.index.mu.Unlock()
+
+	err := os.Remove(filepath.Join(ys.MockPath, testSetID, ys.indexFileName()+".yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// getIndex returns the index of the mock file of the test set. The index is read from the index file and
+// rebuilt from the mock file when it is missing or does not cover the mock file (eg: the mock file was edited by hand).
+func (ys *MockYaml) getIndex(ctx context.Context, testSetID string, fileSize int64) ([]IndexEntry, error) {
+	ys.index.mu.Lock()
+	defer ys.index.mu.Unlock()
+
+	if idx, ok := ys.index.indices[testSetID]; ok && idx.fileSize == fileSize {
+		return idx.entries[:len(idx.entries):len(idx.entries)], nil
+	}
+
+	idx, err := ys.readIndex(ctx, testSetID)
+	if err != nil || idx.fileSize != fileSize {
+		ys.Logger.Debug("mock index is missing or stale, rebuilding it", zap.String("testSetID", testSetID), zap.Error(err))
+		idx, err = ys.buildIndex(ctx, testSetID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	ys.index.indices[testSetID] = idx
+	return idx.entries[:len(idx.entries):len(idx.entries)], nil
+}
+
+func (ys *MockYaml) readIndex(ctx context.Context, testSetID string) (*mockIndex, error) {
+	data, err := yaml.ReadFile(ctx, ys.Logger, filepath.Join(ys.MockPath, testSetID), ys.indexFileName())
+	if err != nil {
+		return nil, err
+	}
+	idx := &mockIndex{}
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	for {
+		var entry IndexEntry
+		err := dec.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the mock index. error: %v", err.Error())
+		}
+		idx.entries = append(idx.entries, entry)
+		idx.fileSize = entry.end()
+	}
+	// the index only grows at the end of the mock file, an index whose first mock is not at the start of the
+	// file misses the mocks written before it
+	if len(idx.entries) > 0 && idx.entries[0].Offset > int64(len(docSeparator)) {
+		return nil, fmt.Errorf("the mock index does not cover the start of the mock file")
+	}
+	return idx, nil
+}
+
+// buildIndex scans the mock file of the test set and rewrites its index file.
+func (ys *MockYaml) buildIndex(ctx context.Context, testSetID string) (*mockIndex, error) {
+	path := filepath.Join(ys.MockPath, testSetID)
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, ys.mockFileName())
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &mockIndex{fileSize: int64(len(data))}
+	var indexData []byte
+	for _, doc := range splitDocs(data) {
+		yamlDoc, mock, err := ys.decodeDoc(ctx, data[doc.Offset:doc.end()])
+		if err != nil {
+			utils.LogError(ys.Logger, err, "failed to decode the mock while building the index", zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		if yamlDoc == nil {
+			continue
+		}
+		entry := IndexEntry{Name: yamlDoc.Name, Kind: yamlDoc.Kind, Offset: doc.Offset, Length: doc.Length}
+		if mock != nil {
+			entry = newIndexEntry(mock, doc.Offset, doc.Length)
+		}
+		idx.entries = append(idx.entries, entry)
+
+		d, err := yamlLib.Marshal(&entry)
+		if err != nil {
+			return nil, err
+		}
+		if len(indexData) > 0 {
+			indexData = append(indexData, docSeparator...)
+		}
+		indexData = append(indexData, d...)
+	}
+
+	err = yaml.WriteFile(ctx, ys.Logger, path, ys.indexFileName(), indexData, false)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to write the mock index", zap.String("testSetID", testSetID))
+		return nil, err
+	}
+	return idx, nil
+}
+
+// loadMocks reads only the indexed mocks which satisfy the given condition from the mock file.
+func (ys *MockYaml) loadMocks(ctx context.Context, testSetID string, match func(e *IndexEntry) bool) ([]*models.Mock, error) {
+	path := filepath.Join(ys.MockPath, testSetID)
+	mockPath, err := yaml.ValidatePath(filepath.Join(path, ys.mockFileName()+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(mockPath)
+	if err != nil {
+		return []*models.Mock{}, nil
+	}
+
+	entries, err := ys.getIndex(ctx, testSetID, info.Size())
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to get the mock index", zap.String("testSetID", testSetID))
+		return nil, err
+	}
+
+	file, err := os.Open(mockPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			utils.LogError(ys.Logger, err, "failed to close file", zap.String("file", mockPath))
+		}
+	}()
+
+	mocks := []*models.Mock{}
+	for i := range entries {
+		entry := &entries[i]
+		if !match(entry) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		buf := make([]byte, entry.Length)
+		_, err := file.ReadAt(buf, entry.Offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the mock %s from the mock file: %v", entry.Name, err)
+		}
+		_, mock, err := ys.decodeDoc(ctx, buf)
+		if err != nil {
+			utils.LogError(ys.Logger, err, "failed to decode the mock", zap.String("mock", entry.Name), zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		if mock != nil {
+			mocks = append(mocks, mock)
+		}
+	}
+	return mocks, nil
+}
+
+// decodeDoc decodes a single yaml document of the mock file. The returned mock is nil for the
+// documents which are skipped by DecodeMocks.
+func (ys *MockYaml) decodeDoc(ctx context.Context, data []byte) (*yaml.NetworkTrafficDoc, *models.Mock, error) {
+	var doc *yaml.NetworkTrafficDoc
+	err := yamlLib.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
+	}
+	if doc == nil {
+		return nil, nil, nil
+	}
+	if err := ys.cipher.DecryptDoc(doc); err != nil {
+		return nil, nil, err
+	}
+	mocks, err := ys.decodeMocks(ctx, []*yaml.NetworkTrafficDoc{doc})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(mocks) == 0 {
+		return doc, nil, nil
+	}
+	return doc, mocks[0], nil
+}
+
+func newIndexEntry(mock *models.Mock, offset, length int64) IndexEntry {
+	return IndexEntry{
+		Name:             mock.Name,
+		Kind:             mock.Kind,
+		Offset:           offset,
+		Length:           length,
+		UnFiltered:       IsUnFilteredMock(mock),
+		ReqTimestampMock: mock.Spec.ReqTimestampMock,
+		ResTimestampMock: mock.Spec.ResTimestampMock,
+	}
+}
+
+// docSeparator is written by yaml.WriteFile between the appended documents
+const docSeparator = "---\n"
+
+// splitDocs returns the position of every yaml document in the mock file.
+func splitDocs(data []byte) []IndexEntry {
+	var docs []IndexEntry
+	var start, pos int64
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+	scanner.Split(scanLinesWithEOL)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if string(line) == docSeparator || string(bytes.TrimRight(line, "\r\n")) == "---" {
+			if pos > start {
+				docs = append(docs, IndexEntry{Offset: start, Length: pos - start})
+			}
+			start = pos + int64(len(line))
+		}
+		pos += int64(len(line))
+	}
+	if pos > start {
+		docs = append(docs, IndexEntry{Offset: start, Length: pos - start})
+	}
+	return docs
+}
+
+// scanLinesWithEOL is bufio.ScanLines which keeps the line endings so that the offsets can be tracked.
+func scanLinesWithEOL(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		return i + 1, data[:i+1], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
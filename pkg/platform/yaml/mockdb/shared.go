@@ -0,0 +1,280 @@
+//go:build linux
+
+package mockdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// sharedMockPrefix is the prefix of the names of the mocks in the shared mock pool
+const sharedMockPrefix = "shared-mock-"
+
+// sharedPool is the in-memory copy of the shared mock pool along with the size of the file it was read from.
+type sharedPool struct {
+	mu       sync.Mutex
+	docs     map[string]*yaml.NetworkTrafficDoc
+	fileSize int64
+}
+
+// sharedDocs returns the documents of the shared mock pool by their names. The pool is read again
+// when its file has changed since it was last read.
+func (ys *MockYaml) sharedDocs(ctx context.Context) (map[string]*yaml.NetworkTrafficDoc, error) {
+	ys.shared.mu.Lock()
+	defer ys.shared.mu.Unlock()
+
+	path := filepath.Join(ys.MockPath, yaml.SharedMocksDir)
+	info, err := os.Stat(filepath.Join(path, ys.mockFileName()+".yaml"))
+	if err != nil {
+		return map[string]*yaml.NetworkTrafficDoc{}, nil
+	}
+	if ys.shared.docs != nil && ys.shared.fileSize == info.Size() {
+		return ys.shared.docs, nil
+	}
+	data, err := yaml.ReadFile(ctx, ys.Logger, path, ys.mockFileName())
+	if err != nil {
+		return nil, err
+	}
+	docs, err := ys.cipher.DecodeDocs(data)
+	if err != nil {
+		return nil, err
+	}
+	ys.shared.docs = make(map[string]*yaml.NetworkTrafficDoc, len(docs))
+	for _, doc := range docs {
+		ys.shared.docs[doc.Name] = doc
+	}
+	ys.shared.fileSize = info.Size()
+	return ys.shared.docs, nil
+}
+
+// decodeMocks converts the yaml documents of a mock file into mocks, the references to the shared mock
+// pool are resolved into the copies of the referenced mocks.
+func (ys *MockYaml) decodeMocks(ctx context.Context, docs []*yaml.NetworkTrafficDoc) ([]*models.Mock, error) {
+	mocks := []*models.Mock{}
+	for _, doc := range docs {
+		if doc.Ref == nil {
+			decoded, err := DecodeMocks([]*yaml.NetworkTrafficDoc{doc}, ys.Logger)
+			if err != nil {
+				return nil, err
+			}
+			mocks = append(mocks, decoded...)
+			continue
+		}
+		shared, err := ys.sharedDocs(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the shared mock pool: %v", err)
+		}
+		sharedDoc, ok := shared[doc.Ref.Name]
+		if !ok {
+			return nil, fmt.Errorf("mock %s refers to %s which is missing from the shared mock pool", doc.Name, doc.Ref.Name)
+		}
+		// decode a copy so that every reference gets its own mock
+		copied := *sharedDoc
+		decoded, err := DecodeMocks([]*yaml.NetworkTrafficDoc{&copied}, ys.Logger)
+		if err != nil {
+			return nil, err
+		}
+		for _, mock := range decoded {
+			mock.Name = doc.Name
+			mock.ConnectionID = doc.ConnectionID
+			mock.Spec.Created = doc.Ref.Created
+			mock.Spec.ReqTimestampMock = doc.Ref.ReqTimestampMock
+			mock.Spec.ResTimestampMock = doc.Ref.ResTimestampMock
+		}
+		mocks = append(mocks, decoded...)
+	}
+	return mocks, nil
+}
+
+// mockKey identifies the content of a mock, the fields which differ between the recordings of the same
+// dependency call (name, connection and timestamps) are left out.
+func (ys *MockYaml) mockKey(mock *models.Mock) (string, error) {
+	m := *mock
+	m.Name = ""
+	m.ConnectionID = ""
+	m.Spec.Created = 0
+	m.Spec.ReqTimestampMock = time.Time{}
+	m.Spec.ResTimestampMock = time.Time{}
+	doc, err := EncodeMock(&m, ys.Logger)
+	if err != nil {
+		return "", err
+	}
+	data, err := yamlLib.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return string(sum[:]), nil
+}
+
+// mockOccurrence is a mock document of a test set which can be moved to the shared mock pool.
+type mockOccurrence struct {
+	testSetID string
+	doc       *yaml.NetworkTrafficDoc
+	mock      *models.Mock
+	key       string
+}
+
+// DedupeMocks moves the mocks recorded more than once in the test sets to the shared mock pool and replaces
+// them with references to the pool. It returns the number of mocks replaced in every test set, nothing is
+// written in dry run mode.
+func (ys *MockYaml) DedupeMocks(ctx context.Context, testSetIDs []string, dryRun bool) (map[string]int, error) {
+	ys.mu.Lock()
+	defer ys.mu.Unlock()
+
+	shared, err := ys.sharedDocs(ctx)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the shared mock pool")
+		return nil, err
+	}
+	// mocks already in the pool are reused, the new ones are numbered after them
+	pool := map[string]string{}
+	var poolDocs []*yaml.NetworkTrafficDoc
+	var nextID int64
+	for name, doc := range shared {
+		copied := *doc
+		decoded, err := DecodeMocks([]*yaml.NetworkTrafficDoc{&copied}, ys.Logger)
+		if err != nil {
+			return nil, err
+		}
+		if len(decoded) > 0 {
+			key, err := ys.mockKey(decoded[0])
+			if err != nil {
+				return nil, err
+			}
+			pool[key] = name
+		}
+		if id, err := strconv.ParseInt(strings.TrimPrefix(name, sharedMockPrefix), 10, 64); err == nil && id >= nextID {
+			nextID = id + 1
+		}
+	}
+
+	// collect the mocks of every test set along with the number of their recordings
+	docsBySet := map[string][]*yaml.NetworkTrafficDoc{}
+	var occurrences []mockOccurrence
+	counts := map[string]int{}
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		path := filepath.Join(ys.MockPath, testSetID)
+		if _, err := os.Stat(filepath.Join(path, ys.mockFileName()+".yaml")); err != nil {
+			continue
+		}
+		data, err := yaml.ReadFile(ctx, ys.Logger, path, ys.mockFileName())
+		if err != nil {
+			utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		docs, err := ys.cipher.DecodeDocs(data)
+		if err != nil {
+			utils.LogError(ys.Logger, err, "failed to decode the mocks", zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		docsBySet[testSetID] = docs
+		for _, doc := range docs {
+			if doc.Ref != nil {
+				continue
+			}
+			decoded, err := DecodeMocks([]*yaml.NetworkTrafficDoc{doc}, ys.Logger)
+			if err != nil {
+				return nil, err
+			}
+			if len(decoded) == 0 {
+				continue
+			}
+			key, err := ys.mockKey(decoded[0])
+			if err != nil {
+				return nil, err
+			}
+			occurrences = append(occurrences, mockOccurrence{testSetID: testSetID, doc: doc, mock: decoded[0], key: key})
+			counts[key]++
+		}
+	}
+
+	// replace the duplicated mocks with references, the first recording of a mock is copied to the pool
+	replaced := map[string]int{}
+	for _, o := range occurrences {
+		name, ok := pool[o.key]
+		if !ok {
+			if counts[o.key] < 2 {
+				continue
+			}
+			name = fmt.Sprint(sharedMockPrefix, nextID)
+			nextID++
+			pool[o.key] = name
+			sharedDoc := *o.doc
+			sharedDoc.Name = name
+			sharedDoc.ConnectionID = ""
+			poolDocs = append(poolDocs, &sharedDoc)
+		}
+		*o.doc = yaml.NetworkTrafficDoc{
+			Version:      o.doc.Version,
+			Kind:         o.doc.Kind,
+			Name:         o.doc.Name,
+			ConnectionID: o.doc.ConnectionID,
+			Ref: &yaml.MockRef{
+				Name:             name,
+				Created:          o.mock.Spec.Created,
+				ReqTimestampMock: o.mock.Spec.ReqTimestampMock,
+				ResTimestampMock: o.mock.Spec.ResTimestampMock,
+			},
+		}
+		replaced[o.testSetID]++
+	}
+	if dryRun || len(replaced) == 0 {
+		return replaced, nil
+	}
+
+	// the pool is written before the references to it
+	if len(poolDocs) > 0 {
+		err = ys.appendShared(ctx, poolDocs)
+		if err != nil {
+			utils.LogError(ys.Logger, err, "failed to write the shared mock pool")
+			return nil, err
+		}
+	}
+	for testSetID := range replaced {
+		data, err := yaml.EncodeDocs(docsBySet[testSetID], ys.cipher)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.WriteFile(ctx, ys.Logger, filepath.Join(ys.MockPath, testSetID), ys.mockFileName(), data, false)
+		if err != nil {
+			utils.LogError(ys.Logger, err, "failed to write the deduplicated mocks", zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		// the offsets of the mocks have changed, the index is rebuilt on the next read
+		if err := ys.removeIndex(testSetID); err != nil {
+			utils.LogError(ys.Logger, err, "failed to remove the mock index", zap.String("testSetID", testSetID))
+			return nil, err
+		}
+	}
+	return replaced, nil
+}
+
+// appendShared adds the documents at the end of the mock file of the shared mock pool.
+func (ys *MockYaml) appendShared(ctx context.Context, docs []*yaml.NetworkTrafficDoc) error {
+	data, err := yaml.EncodeDocs(docs, ys.cipher)
+	if err != nil {
+		return err
+	}
+	err = yaml.WriteFile(ctx, ys.Logger, filepath.Join(ys.MockPath, yaml.SharedMocksDir), ys.mockFileName(), data, true)
+	if err != nil {
+		return err
+	}
+	return ys.removeIndex(yaml.SharedMocksDir)
+}
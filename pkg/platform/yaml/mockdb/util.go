@@ -5,6 +5,7 @@ package mockdb
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/pkg/platform/yaml"
@@ -179,21 +180,30 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc,
 	return &yamlDoc, nil
 }
 
-func decodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, logger *zap.Logger) ([]*models.Mock, error) {
+// DecodeMocks converts the yaml documents of the mock file into mocks.
+func DecodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, logger *zap.Logger) ([]*models.Mock, error) {
 	mocks := []*models.Mock{}
 
 	for _, m := range yamlMocks {
+		mockCheck := strings.Split(string(m.Kind), "-")
+		if len(mockCheck) > 1 {
+			logger.Debug("This dependency does not belong to open source version, will be skipped", zap.String("mock kind:", string(m.Kind)))
+			continue
+		}
+		// mocks recorded with an older schema are upgraded before decoding them into the current layout, the
+		// ones of an unknown version are decoded as they are
+		if _, err := yaml.UpgradeDoc(m); errors.Is(err, yaml.ErrUnsupportedVersion) {
+			logger.Warn("the schema version of the mock is not supported, decoding it with the latest schema. Run keploy lint to check it", zap.Any("mock name", m.Name), zap.String("version", string(m.Version)))
+		} else if err != nil {
+			utils.LogError(logger, err, "failed to upgrade the mock to the latest schema version", zap.Any("mock name", m.Name))
+			return nil, err
+		}
 		mock := models.Mock{
 			Version:      m.Version,
 			Name:         m.Name,
 			Kind:         m.Kind,
 			ConnectionID: m.ConnectionID,
 		}
-		mockCheck := strings.Split(string(m.Kind), "-")
-		if len(mockCheck) > 1 {
-			logger.Debug("This dependency does not belong to open source version, will be skipped", zap.String("mock kind:", string(m.Kind)))
-			continue
-		}
 		switch m.Kind {
 		case models.HTTP:
 			httpSpec := models.HTTPSchema{}
@@ -565,3 +575,54 @@ func decodeMongoMessage(yamlSpec *models.MongoSpec, logger *zap.Logger) (*models
 	mockSpec.MongoResponses = responses
 	return &mockSpec, nil
 }
+
+// IsUnFilteredMock reports whether the mock can be matched by any test case of the test set
+// (config mocks and mocks of the connection-less protocols) rather than only by the test case it was recorded for.
+func IsUnFilteredMock(mock *models.Mock) bool {
+	switch mock.Kind {
+	case models.GENERIC, models.Postgres, models.HTTP, models.REDIS:
+		return true
+	}
+	return mock.Spec.Metadata["type"] == "config"
+}
+
+// FilterByTimeStamp splits the mocks into the ones recorded within the given time window and the rest.
+func FilterByTimeStamp(m []*models.Mock, afterTime time.Time, beforeTime time.Time, logger *zap.Logger) ([]*models.Mock, []*models.Mock) {
+
+	filteredMocks := make([]*models.Mock, 0)
+	unfilteredMocks := make([]*models.Mock, 0)
+
+	if afterTime == (time.Time{}) {
+		return m, unfilteredMocks
+	}
+
+	if beforeTime == (time.Time{}) {
+		return m, unfilteredMocks
+	}
+
+	isNonKeploy := false
+
+	for _, mock := range m {
+		if mock.Version != "api.keploy.io/v1beta1" && mock.Version != "api.keploy.io/v1beta2" {
+			isNonKeploy = true
+		}
+		if mock.Spec.ReqTimestampMock == (time.Time{}) || mock.Spec.ResTimestampMock == (time.Time{}) {
+			logger.Debug("request or response timestamp of mock is missing")
+			mock.TestModeInfo.IsFiltered = true
+			filteredMocks = append(filteredMocks, mock)
+			continue
+		}
+
+		if mock.Spec.ReqTimestampMock.After(afterTime) && mock.Spec.ResTimestampMock.Before(beforeTime) {
+			mock.TestModeInfo.IsFiltered = true
+			filteredMocks = append(filteredMocks, mock)
+			continue
+		}
+		mock.TestModeInfo.IsFiltered = false
+		unfilteredMocks = append(unfilteredMocks, mock)
+	}
+	if isNonKeploy {
+		logger.Debug("Few mocks in the mock File are not recorded by keploy ignoring them")
+	}
+	return filteredMocks, unfilteredMocks
+}
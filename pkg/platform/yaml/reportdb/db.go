@@ -7,7 +7,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"go.keploy.io/server/v2/pkg/models"
@@ -38,6 +41,27 @@ func (fe *TestReport) GetAllTestRunIDs(ctx context.Context) ([]string, error) {
 	return yaml.ReadSessionIndices(ctx, fe.Path, fe.Logger)
 }
 
+// GetAllTestSetIDs returns the ids of the test sets for which a report exists in the given test run.
+func (fe *TestReport) GetAllTestSetIDs(_ context.Context, testRunID string) ([]string, error) {
+	path, err := yaml.ValidatePath(filepath.Join(fe.Path, testRunID))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed to read the reports of test run %s. error: %v", utils.Emoji, testRunID, err.Error())
+	}
+	var testSetIDs []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "-report.yaml") {
+			continue
+		}
+		testSetIDs = append(testSetIDs, strings.TrimSuffix(entry.Name(), "-report.yaml"))
+	}
+	sort.Strings(testSetIDs)
+	return testSetIDs, nil
+}
+
 func (fe *TestReport) InsertTestCaseResult(_ context.Context, testRunID string, testSetID string, result *models.TestResult) error {
 	fe.m.Lock()
 	defer fe.m.Unlock()
@@ -54,6 +78,9 @@ func (fe *TestReport) InsertTestCaseResult(_ context.Context, testRunID string,
 }
 
 func (fe *TestReport) GetTestCaseResults(_ context.Context, testRunID string, testSetID string) ([]models.TestResult, error) {
+	fe.m.Lock()
+	defer fe.m.Unlock()
+
 	testRun, ok := fe.tests[testRunID]
 	if !ok {
 		return []models.TestResult{}, fmt.Errorf("%s found no test results for test report with id: %s", utils.Emoji, testRunID)
@@ -0,0 +1,121 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+
+	"go.keploy.io/server/v2/pkg/models"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// LatestVersion is the schema version of the documents written by this version of keploy.
+const LatestVersion = models.V1Beta1
+
+// Upgrader rewrites a document of the From schema version into the layout of the To schema version.
+type Upgrader struct {
+	From models.Version
+	To   models.Version
+	// Upgrade changes the document in place, the version of the document is set to To by UpgradeDoc.
+	Upgrade func(doc *NetworkTrafficDoc) error
+}
+
+// ErrUnsupportedVersion is returned by UpgradeDoc for the documents of a schema version with no upgrader to
+// LatestVersion
+var ErrUnsupportedVersion = errors.New("unsupported schema version")
+
+// upgraders holds the registered upgraders by the version they upgrade from
+var upgraders = map[models.Version]Upgrader{}
+
+func init() {
+	// documents written before the version was recorded share the layout of v1beta1
+	RegisterUpgrader(Upgrader{
+		From:    "",
+		To:      models.V1Beta1,
+		Upgrade: func(_ *NetworkTrafficDoc) error { return nil },
+	})
+}
+
+// RegisterUpgrader adds an upgrade step to the schema registry. A change in the layout of the
+// testcases or mocks must bump LatestVersion and register the upgrader from the previous version,
+// so that the already recorded documents keep decoding.
+func RegisterUpgrader(u Upgrader) {
+	upgraders[u.From] = u
+}
+
+// SchemaVersions returns the chain of the supported schema versions ending with LatestVersion.
+func SchemaVersions() []models.Version {
+	versions := []models.Version{LatestVersion}
+	for i := 0; i < len(upgraders); i++ {
+		found := false
+		for _, u := range upgraders {
+			if u.To == versions[0] && u.From != "" {
+				versions = append([]models.Version{u.From}, versions...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return versions
+}
+
+// UpgradeDoc upgrades the document to LatestVersion by applying the registered upgraders in order.
+// It reports whether the document was changed.
+func UpgradeDoc(doc *NetworkTrafficDoc) (bool, error) {
+	upgraded := false
+	// bound the steps so that a cycle in the registered upgraders cannot loop forever
+	for i := 0; doc.Version != LatestVersion; i++ {
+		u, ok := upgraders[doc.Version]
+		if !ok || i > len(upgraders) {
+			return upgraded, fmt.Errorf("%w %q of %s, supported versions are %v (documents written by a newer keploy cannot be read)", ErrUnsupportedVersion, doc.Version, doc.Name, SchemaVersions())
+		}
+		if err := u.Upgrade(doc); err != nil {
+			return upgraded, fmt.Errorf("failed to upgrade %s from schema version %q to %q: %v", doc.Name, u.From, u.To, err)
+		}
+		doc.Version = u.To
+		upgraded = true
+	}
+	return upgraded, nil
+}
+
+// UpgradeDocs decodes the yaml documents in data, decrypting them with the cipher, and upgrades each of
+// them to LatestVersion. The names of the upgraded documents are returned along with all the documents.
+func UpgradeDocs(data []byte, c *Cipher) ([]*NetworkTrafficDoc, []string, error) {
+	docs, err := c.DecodeDocs(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	var upgraded []string
+	for _, doc := range docs {
+		changed, err := UpgradeDoc(doc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if changed {
+			upgraded = append(upgraded, doc.Name)
+		}
+	}
+	return docs, upgraded, nil
+}
+
+// EncodeDocs encodes the documents into a single yaml file in the layout written by WriteFile. The specs
+// of the documents are encrypted with the cipher when the encryption is enabled.
+func EncodeDocs(docs []*NetworkTrafficDoc, c *Cipher) ([]byte, error) {
+	var data []byte
+	for i, doc := range docs {
+		if err := c.EncryptDoc(doc); err != nil {
+			return nil, err
+		}
+		d, err := yamlLib.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			data = append(data, "---\n"...)
+		}
+		data = append(data, d...)
+	}
+	return data, nil
+}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/pkg/platform/yaml"
@@ -22,12 +23,15 @@ import (
 type TestYaml struct {
 	TcsPath string
 	logger  *zap.Logger
+	// cipher encrypts the specs of the written testcases, it is nil when the encryption is disabled
+	cipher *yaml.Cipher
 }
 
-func New(logger *zap.Logger, tcsPath string) *TestYaml {
+func New(logger *zap.Logger, tcsPath string, cipher *yaml.Cipher) *TestYaml {
 	return &TestYaml{
 		TcsPath: tcsPath,
 		logger:  logger,
+		cipher:  cipher,
 	}
 }
 
@@ -41,6 +45,8 @@ func (ts *TestYaml) InsertTestCase(ctx context.Context, tc *models.TestCase, tes
 	if err != nil {
 		return err
 	}
+	// the name given to a new testcase is available to the caller
+	tc.Name = tcsInfo.name
 
 	ts.logger.Info("🟠 Keploy has captured test cases for the user's application.", zap.String("path", tcsInfo.path), zap.String("testcase name", tcsInfo.name))
 
@@ -91,6 +97,11 @@ func (ts *TestYaml) GetTestCases(ctx context.Context, testSetID string) ([]*mode
 			utils.LogError(ts.logger, err, "failed to unmarshall YAML data")
 			return nil, err
 		}
+		err = ts.cipher.DecryptDoc(testCase)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to decrypt the testcase", zap.String("testcase", name))
+			return nil, err
+		}
 
 		tc, err := Decode(testCase, ts.logger)
 		if err != nil {
@@ -100,11 +111,20 @@ func (ts *TestYaml) GetTestCases(ctx context.Context, testSetID string) ([]*mode
 		tcs = append(tcs, tc)
 	}
 	sort.SliceStable(tcs, func(i, j int) bool {
-		return tcs[i].HTTPReq.Timestamp.Before(tcs[j].HTTPReq.Timestamp)
+		return RequestTimestamp(tcs[i]).Before(RequestTimestamp(tcs[j]))
 	})
 	return tcs, nil
 }
 
+// RequestTimestamp returns the time the request of the test case was recorded at, the test cases are run in
+// this order.
+func RequestTimestamp(tc *models.TestCase) time.Time {
+	if tc.Kind == models.GRPC_EXPORT {
+		return tc.GrpcReq.Timestamp
+	}
+	return tc.HTTPReq.Timestamp
+}
+
 func (ts *TestYaml) UpdateTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error {
 
 	tcsInfo, err := ts.upsert(ctx, testSetID, tc)
@@ -133,6 +153,11 @@ func (ts *TestYaml) upsert(ctx context.Context, testSetID string, tc *models.Tes
 		return tcsInfo{name: tcsName, path: tcsPath}, err
 	}
 	yamlTc.Name = tcsName
+	err = ts.cipher.EncryptDoc(yamlTc)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to encrypt the testcase", zap.String("testcase", tcsName))
+		return tcsInfo{name: tcsName, path: tcsPath}, err
+	}
 	data, err := yamlLib.Marshal(&yamlTc)
 	if err != nil {
 		return tcsInfo{name: tcsName, path: tcsPath}, err
@@ -145,3 +170,147 @@ func (ts *TestYaml) upsert(ctx context.Context, testSetID string, tc *models.Tes
 
 	return tcsInfo{name: tcsName, path: tcsPath}, nil
 }
+
+// MigrateTestCases upgrades the testcases of the test set to the latest schema version and returns the
+// names of the upgraded testcases. The testcase files are rewritten in place unless dryRun is set.
+func (ts *TestYaml) MigrateTestCases(ctx context.Context, testSetID string, dryRun bool) ([]string, error) {
+	path := filepath.Join(ts.TcsPath, testSetID, "tests")
+	TestPath, err := yaml.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(TestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		utils.LogError(ts.logger, err, "failed to read the file names of yaml testcases", zap.Any("path", TestPath))
+		return nil, err
+	}
+
+	var upgraded []string
+	for _, j := range files {
+		if filepath.Ext(j.Name()) != ".yaml" || strings.Contains(j.Name(), "mocks") {
+			continue
+		}
+		name := strings.TrimSuffix(j.Name(), filepath.Ext(j.Name()))
+		data, err := yaml.ReadFile(ctx, ts.logger, TestPath, name)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to read the testcase from yaml")
+			return nil, err
+		}
+		docs, names, err := yaml.UpgradeDocs(data, ts.cipher)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to upgrade the testcase", zap.String("testcase", name))
+			return nil, err
+		}
+		if len(names) == 0 {
+			continue
+		}
+		// the upgraded testcase must decode into the current layout before it is written back
+		for _, doc := range docs {
+			if _, err := Decode(doc, ts.logger); err != nil {
+				return nil, fmt.Errorf("upgraded testcase %s of test set %s does not decode: %v", name, testSetID, err)
+			}
+		}
+		upgraded = append(upgraded, name)
+		if dryRun {
+			continue
+		}
+		data, err = yaml.EncodeDocs(docs, ts.cipher)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.WriteFile(ctx, ts.logger, TestPath, name, data, false)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to write the upgraded testcase", zap.String("testcase", name))
+			return nil, err
+		}
+	}
+	return upgraded, nil
+}
+
+// RotateTestCases re-encrypts the testcases of the test set with the next cipher and returns the names of
+// the rotated testcases.
+func (ts *TestYaml) RotateTestCases(ctx context.Context, testSetID string, next *yaml.Cipher) ([]string, error) {
+	path := filepath.Join(ts.TcsPath, testSetID, "tests")
+	TestPath, err := yaml.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(TestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		utils.LogError(ts.logger, err, "failed to read the file names of yaml testcases", zap.Any("path", TestPath))
+		return nil, err
+	}
+
+	var rotated []string
+	for _, j := range files {
+		if filepath.Ext(j.Name()) != ".yaml" || strings.Contains(j.Name(), "mocks") {
+			continue
+		}
+		name := strings.TrimSuffix(j.Name(), filepath.Ext(j.Name()))
+		data, err := yaml.ReadFile(ctx, ts.logger, TestPath, name)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to read the testcase from yaml")
+			return nil, err
+		}
+		docs, names, err := ts.cipher.RotateDocs(data, next)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to re-encrypt the testcase", zap.String("testcase", name))
+			return nil, err
+		}
+		if len(names) == 0 {
+			continue
+		}
+		data, err = yaml.EncodeDocs(docs, next)
+		if err != nil {
+			return nil, err
+		}
+		err = yaml.WriteFile(ctx, ts.logger, TestPath, name, data, false)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to write the re-encrypted testcase", zap.String("testcase", name))
+			return nil, err
+		}
+		rotated = append(rotated, name)
+	}
+	return rotated, nil
+}
+
+// GetLocatedTestCases decodes the testcases of the test set along with their position in the yaml files.
+// The testcases which do not decode are returned with their error.
+func (ts *TestYaml) GetLocatedTestCases(ctx context.Context, testSetID string) ([]yaml.LocatedDoc[*models.TestCase], error) {
+	path := filepath.Join(ts.TcsPath, testSetID, "tests")
+	TestPath, err := yaml.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(TestPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		utils.LogError(ts.logger, err, "failed to read the file names of yaml testcases", zap.Any("path", TestPath))
+		return nil, err
+	}
+
+	var located []yaml.LocatedDoc[*models.TestCase]
+	for _, j := range files {
+		if filepath.Ext(j.Name()) != ".yaml" || strings.Contains(j.Name(), "mocks") {
+			continue
+		}
+		name := strings.TrimSuffix(j.Name(), filepath.Ext(j.Name()))
+		data, err := yaml.ReadFile(ctx, ts.logger, TestPath, name)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to read the testcase from yaml")
+			return nil, err
+		}
+		located = append(located, yaml.DecodeLocated(filepath.Join(TestPath, j.Name()), data, ts.cipher, func(doc *yaml.NetworkTrafficDoc) (*models.TestCase, error) {
+			return Decode(doc, ts.logger)
+		})...)
+	}
+	return located, nil
+}
@@ -13,25 +13,33 @@ import (
 	"strconv"
 	"strings"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/pkg/platform/yaml"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
 )
 
 func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*yaml.NetworkTrafficDoc, error) {
 
-	header := pkg.ToHTTPHeader(tc.HTTPReq.Header)
-	curl := pkg.MakeCurlCommand(string(tc.HTTPReq.Method), tc.HTTPReq.URL, pkg.ToYamlHTTPHeader(header), tc.HTTPReq.Body)
 	doc := &yaml.NetworkTrafficDoc{
 		Version: tc.Version,
 		Kind:    tc.Kind,
 		Name:    tc.Name,
-		Curl:    curl,
+	}
+	if tc.Kind == models.HTTP {
+		header := pkg.ToHTTPHeader(tc.HTTPReq.Header)
+		doc.Curl = pkg.MakeCurlCommand(string(tc.HTTPReq.Method), tc.HTTPReq.URL, pkg.ToYamlHTTPHeader(header), tc.HTTPReq.Body)
 	}
 	// find noisy fields
-	m, err := FlattenHTTPResponse(pkg.ToHTTPHeader(tc.HTTPResp.Header), tc.HTTPResp.Body)
+	respHeader, respBody := tc.HTTPResp.Header, tc.HTTPResp.Body
+	if tc.Kind == models.GRPC_EXPORT {
+		// the grpc messages are not json, only the headers can be noisy
+		respHeader, respBody = tc.GrpcResp.Headers.OrdinaryHeaders, ""
+	}
+	m, err := FlattenHTTPResponse(pkg.ToHTTPHeader(respHeader), respBody)
 	if err != nil {
 		msg := "error in flattening http response"
 		utils.LogError(logger, err, msg)
@@ -54,20 +62,45 @@ func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*yaml.NetworkTraffi
 		noise[v] = []string{}
 	}
 
+	assertions := map[string]interface{}{
+		"noise": noise,
+	}
+	if len(tc.Assertions) > 0 {
+		assertions["checks"] = tc.Assertions
+	}
+	if tc.AssertionsOnly {
+		assertions["only"] = true
+	}
+	if tc.SchemaMatch {
+		assertions["schema"] = true
+	}
+	if len(tc.Tolerances) > 0 {
+		assertions["tolerances"] = tc.Tolerances
+	}
+
 	switch tc.Kind {
 	case models.HTTP:
 		err := doc.Spec.Encode(models.HTTPSchema{
-			Request:  tc.HTTPReq,
-			Response: tc.HTTPResp,
-			Created:  tc.Created,
-			Assertions: map[string]interface{}{
-				"noise": noise,
-			},
+			Request:    tc.HTTPReq,
+			Response:   tc.HTTPResp,
+			Created:    tc.Created,
+			Assertions: assertions,
 		})
 		if err != nil {
 			utils.LogError(logger, err, "failed to encode testcase into a yaml doc")
 			return nil, err
 		}
+	case models.GRPC_EXPORT:
+		err := doc.Spec.Encode(models.GrpcSpec{
+			GrpcReq:    tc.GrpcReq,
+			GrpcResp:   tc.GrpcResp,
+			Created:    tc.Created,
+			Assertions: assertions,
+		})
+		if err != nil {
+			utils.LogError(logger, err, "failed to encode the gRPC testcase into a yaml doc")
+			return nil, err
+		}
 	default:
 		utils.LogError(logger, nil, "failed to marshal the testcase into yaml due to invalid kind of testcase")
 		return nil, errors.New("type of testcases is invalid")
@@ -227,6 +260,14 @@ func HasBannedHeaders(object map[string]string, bannedHeaders map[string]string)
 }
 
 func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.TestCase, error) {
+	// testcases recorded with an older schema are upgraded before decoding them into the current layout, the
+	// ones of an unknown version are decoded as they are
+	if _, err := yaml.UpgradeDoc(yamlTestcase); errors.Is(err, yaml.ErrUnsupportedVersion) {
+		logger.Warn("the schema version of the testcase is not supported, decoding it with the latest schema. Run keploy lint to check it", zap.String("testcase", yamlTestcase.Name), zap.String("version", string(yamlTestcase.Version)))
+	} else if err != nil {
+		utils.LogError(logger, err, "failed to upgrade the testcase to the latest schema version", zap.String("testcase", yamlTestcase.Name))
+		return nil, err
+	}
 	tc := models.TestCase{
 		Version: yamlTestcase.Version,
 		Kind:    yamlTestcase.Kind,
@@ -244,21 +285,21 @@ func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.T
 		tc.Created = httpSpec.Created
 		tc.HTTPReq = httpSpec.Request
 		tc.HTTPResp = httpSpec.Response
-		tc.Noise = map[string][]string{}
-		switch reflect.ValueOf(httpSpec.Assertions["noise"]).Kind() {
-		case reflect.Map:
-			for k, v := range httpSpec.Assertions["noise"].(map[string]interface{}) {
-				l := strings.ToLower(k)
-				tc.Noise[l] = []string{}
-				for _, val := range v.([]interface{}) {
-					tc.Noise[l] = append(tc.Noise[l], val.(string))
-				}
-			}
-		case reflect.Slice:
-			for _, v := range httpSpec.Assertions["noise"].([]interface{}) {
-				tc.Noise[v.(string)] = []string{}
+		tc.Noise = decodeNoise(httpSpec.Assertions)
+		if checks, ok := httpSpec.Assertions["checks"]; ok {
+			tc.Assertions, err = decodeAssertions(checks)
+			if err != nil {
+				utils.LogError(logger, err, "failed to decode the assertions of the http testcase", zap.String("testcase", tc.Name))
+				return nil, err
 			}
 		}
+		tc.AssertionsOnly, _ = httpSpec.Assertions["only"].(bool)
+		tc.SchemaMatch, _ = httpSpec.Assertions["schema"].(bool)
+		tc.Tolerances, err = decodeTolerances(httpSpec.Assertions)
+		if err != nil {
+			utils.LogError(logger, err, "failed to decode the tolerances of the http testcase", zap.String("testcase", tc.Name))
+			return nil, err
+		}
 	// unmarshal its mocks from yaml docs to go struct
 	case models.GRPC_EXPORT:
 		grpcSpec := models.GrpcSpec{}
@@ -267,11 +308,74 @@ func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.T
 			utils.LogError(logger, err, "failed to unmarshal a yaml doc into the gRPC testcase")
 			return nil, err
 		}
+		tc.Created = grpcSpec.Created
 		tc.GrpcReq = grpcSpec.GrpcReq
 		tc.GrpcResp = grpcSpec.GrpcResp
+		tc.Noise = decodeNoise(grpcSpec.Assertions)
+		tc.Tolerances, err = decodeTolerances(grpcSpec.Assertions)
+		if err != nil {
+			utils.LogError(logger, err, "failed to decode the tolerances of the gRPC testcase", zap.String("testcase", tc.Name))
+			return nil, err
+		}
 	default:
 		utils.LogError(logger, nil, "failed to unmarshal yaml doc of unknown type", zap.Any("type of yaml doc", tc.Kind))
 		return nil, errors.New("yaml doc of unknown type")
 	}
 	return &tc, nil
 }
+
+// decodeNoise decodes the noise of the assertions of a testcase, the noisy fields are lower case.
+func decodeNoise(assertions map[string]interface{}) map[string][]string {
+	noise := map[string][]string{}
+	switch reflect.ValueOf(assertions["noise"]).Kind() {
+	case reflect.Map:
+		for k, v := range assertions["noise"].(map[string]interface{}) {
+			l := strings.ToLower(k)
+			noise[l] = []string{}
+			for _, val := range v.([]interface{}) {
+				noise[l] = append(noise[l], val.(string))
+			}
+		}
+	case reflect.Slice:
+		for _, v := range assertions["noise"].([]interface{}) {
+			noise[v.(string)] = []string{}
+		}
+	}
+	return noise
+}
+
+// decodeTolerances decodes the tolerances of the assertions of a testcase, the fields are lower case like the
+// noisy ones.
+func decodeTolerances(assertions map[string]interface{}) (map[string][]config.Tolerance, error) {
+	tolerances, ok := assertions["tolerances"]
+	if !ok {
+		return nil, nil
+	}
+	data, err := yamlLib.Marshal(tolerances)
+	if err != nil {
+		return nil, err
+	}
+	var decoded map[string][]config.Tolerance
+	if err := yamlLib.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid tolerances: %w", err)
+	}
+	lower := make(map[string][]config.Tolerance, len(decoded))
+	for field, t := range decoded {
+		lower[strings.ToLower(field)] = t
+	}
+	return lower, nil
+}
+
+// decodeAssertions decodes the checks of the assertions of a testcase, which are decoded generically along
+// with the rest of the assertions.
+func decodeAssertions(checks interface{}) ([]models.Assertion, error) {
+	data, err := yamlLib.Marshal(checks)
+	if err != nil {
+		return nil, err
+	}
+	var assertions []models.Assertion
+	if err := yamlLib.Unmarshal(data, &assertions); err != nil {
+		return nil, fmt.Errorf("invalid assertion checks: %w", err)
+	}
+	return assertions, nil
+}
@@ -7,6 +7,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -22,6 +23,20 @@ type NetworkTrafficDoc struct {
 	Spec         yamlLib.Node   `json:"spec" yaml:"spec"`
 	Curl         string         `json:"curl" yaml:"curl,omitempty"`
 	ConnectionID string         `json:"connectionId" yaml:"connectionId,omitempty"`
+	Encryption   *Envelope      `json:"encryption,omitempty" yaml:"encryption,omitempty"` // set when the spec is encrypted
+	Ref          *MockRef       `json:"ref,omitempty" yaml:"ref,omitempty"`               // set when the mock is stored in the shared mock pool
+}
+
+// SharedMocksDir is the directory of the shared mock pool, it holds the mocks recorded by several test sets.
+const SharedMocksDir = "shared-mocks"
+
+// MockRef points a mock of a test set to its copy in the shared mock pool. The fields which differ between
+// the recordings of the same mock are kept in the reference.
+type MockRef struct {
+	Name             string    `json:"name" yaml:"name"`
+	Created          int64     `json:"created,omitempty" yaml:"created,omitempty"`
+	ReqTimestampMock time.Time `json:"reqTimestampMock,omitempty" yaml:"reqTimestampMock,omitempty"`
+	ResTimestampMock time.Time `json:"resTimestampMock,omitempty" yaml:"resTimestampMock,omitempty"`
 }
 
 // ctxReader wraps an io.Reader with a context for cancellation support
@@ -104,7 +119,7 @@ func ReadFile(ctx context.Context, logger *zap.Logger, path, name string) ([]byt
 	filePath := filepath.Join(path, name+".yaml")
 	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read the file: %v", err)
+		return nil, fmt.Errorf("failed to read the file: %w", err)
 	}
 
 	defer func() {
@@ -172,7 +187,7 @@ func ReadSessionIndices(_ context.Context, path string, Logger *zap.Logger) ([]s
 	}
 
 	for _, v := range files {
-		if v.Name() != "reports" && v.Name() != "testReports" && v.IsDir() {
+		if v.Name() != "reports" && v.Name() != "testReports" && v.Name() != SharedMocksDir && v.IsDir() {
 			indices = append(indices, v.Name())
 		}
 	}
@@ -0,0 +1,44 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redactedPlaceholder matches the placeholders written in place of the redacted values at record time
+var redactedPlaceholder = regexp.MustCompile(`\[REDACTED-[0-9]+\]`)
+
+// RedactedPlaceholder returns the placeholder of the n-th distinct value redacted in a recording.
+func RedactedPlaceholder(n int) string {
+	return fmt.Sprintf("[REDACTED-%d]", n)
+}
+
+// IsRedacted reports whether the recorded value contains a redaction placeholder.
+func IsRedacted(s string) bool {
+	return strings.Contains(s, "[REDACTED-") && redactedPlaceholder.MatchString(s)
+}
+
+// MatchesRedacted reports whether the actual value matches the recorded value containing redaction
+// placeholders, every placeholder matches any text. It is false when the recorded value is not redacted.
+func MatchesRedacted(recorded, actual string) bool {
+	if !IsRedacted(recorded) {
+		return false
+	}
+	locs := redactedPlaceholder.FindAllStringIndex(recorded, -1)
+	var sb strings.Builder
+	sb.WriteString("(?s)^")
+	prev := 0
+	for _, loc := range locs {
+		sb.WriteString(regexp.QuoteMeta(recorded[prev:loc[0]]))
+		sb.WriteString(".*")
+		prev = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(recorded[prev:]))
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return false
+	}
+	return re.MatchString(actual)
+}
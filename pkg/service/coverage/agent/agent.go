@@ -0,0 +1,117 @@
+// Package agent lets keploy attribute the coverage of a go app to each of its test cases. The app is built
+// with go build -cover -covermode=atomic and starts the agent in its main function:
+//
+//	if err := agent.Start(); err != nil {
+//		log.Printf("failed to start the keploy coverage agent: %v", err)
+//	}
+//
+// The agent is started only when the app is run by keploy test with go coverage enabled. After every test
+// case keploy asks the agent to write the coverage counters of the app to a directory and to clear them, so
+// that the counters written hold the code run by this test case only. The counters are written to GOCOVERDIR
+// as well so that the coverage of the whole test run is kept. The package has no dependency but the standard
+// library.
+package agent
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime/coverage"
+	"strconv"
+)
+
+const (
+	// AddrEnv is exported by keploy to the app with the address on which the agent listens
+	AddrEnv = "KEPLOY_COVERAGE_ADDR"
+	// DefaultAddr is the address of the agent when keploy doesn't set one
+	DefaultAddr = "127.0.0.1:16790"
+	// FlushPath is the endpoint writing the coverage counters of the app and clearing them
+	FlushPath = "/keploy/coverage/flush"
+	// DirParam is the query parameter with the directory the counters are written to, they are only written
+	// to GOCOVERDIR when it is empty
+	DirParam = "dir"
+	// workerIDEnv is exported by keploy to every app instance of the parallel mode with the index of its worker
+	workerIDEnv = "KEPLOY_WORKER_ID"
+)
+
+// Addr returns the address of the agent of the app instance of the worker, the instance of worker N listens
+// on the port of the base address + N like for the requests of the test cases.
+func Addr(base string, worker int) (string, error) {
+	host, port, err := net.SplitHostPort(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q of the coverage agent: %w", base, err)
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", fmt.Errorf("invalid port of the coverage agent address %q: %w", base, err)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(p+worker)), nil
+}
+
+// Start serves the flush endpoint in the background when the app is run by keploy, it is a no-op otherwise.
+func Start() error {
+	base := os.Getenv(AddrEnv)
+	if base == "" {
+		return nil
+	}
+	worker := 0
+	if id := os.Getenv(workerIDEnv); id != "" {
+		var err error
+		worker, err = strconv.Atoi(id)
+		if err != nil {
+			return fmt.Errorf("invalid %s %q: %w", workerIDEnv, id, err)
+		}
+	}
+	addr, err := Addr(base, worker)
+	if err != nil {
+		return err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(FlushPath, flush)
+	go func() {
+		_ = http.Serve(ln, mux)
+	}()
+	return nil
+}
+
+func flush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var dirs []string
+	if dir := os.Getenv("GOCOVERDIR"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if dir := r.URL.Query().Get(DirParam); dir != "" && dir != os.Getenv("GOCOVERDIR") {
+		dirs = append(dirs, dir)
+	}
+	for _, dir := range dirs {
+		if err := writeCounters(dir); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	// the counters can be cleared only when the app is built with -covermode=atomic
+	if err := coverage.ClearCounters(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeCounters writes the coverage counters along with the meta data needed to read them to the directory.
+func writeCounters(dir string) error {
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return err
+	}
+	if err := coverage.WriteMetaDir(dir); err != nil {
+		return fmt.Errorf("failed to write the coverage meta data, is the app built with -cover? %w", err)
+	}
+	return coverage.WriteCountersDir(dir)
+}
@@ -0,0 +1,121 @@
+package coverage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/coverage/agent"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// Collector snapshots the coverage of a go app after each of its test cases, through the coverage agent
+// started by the app.
+type Collector struct {
+	logger *zap.Logger
+	client *http.Client
+	addr   string
+	// the git checkout of the app when the coverage is captured
+	commit string
+	dirty  bool
+}
+
+func NewCollector(logger *zap.Logger, addr string) *Collector {
+	return &Collector{
+		logger: logger,
+		client: &http.Client{Timeout: 30 * time.Second},
+		addr:   addr,
+	}
+}
+
+// Reset clears the coverage counters of the app so that the code run before the first test case, like the
+// startup of the app, is not attributed to it. The counters are still written to GOCOVERDIR. It also reads
+// the git checkout the coverage is captured on, the coverage is left without commit outside of a git
+// repository.
+func (c *Collector) Reset(ctx context.Context) error {
+	commit, dirty, err := Revision(ctx, "")
+	if err != nil {
+		c.logger.Debug("failed to get the git commit of the app, the coverage can't be used to select the test cases affected by changes", zap.Error(err))
+	}
+	c.commit, c.dirty = commit, dirty
+	return c.flush(ctx, "")
+}
+
+// Snapshot returns the code run by the app since the previous snapshot or reset, it is attributed to the test
+// case.
+func (c *Collector) Snapshot(ctx context.Context, testSetID, testCaseID string) (*models.TestCaseCoverage, error) {
+	dir, err := os.MkdirTemp("", "keploy-coverage-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the coverage directory of the test case: %w", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			utils.LogError(c.logger, err, "failed to remove the coverage directory of the test case", zap.String("dir", dir))
+		}
+	}()
+
+	if err := c.flush(ctx, dir); err != nil {
+		return nil, err
+	}
+	profile := filepath.Join(dir, "coverage.txt")
+	cmd := exec.CommandContext(ctx, "go", "tool", "covdata", "textfmt", "-i="+dir, "-o="+profile)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("failed to convert the coverage counters of the test case with %s: %w: %s", cmd.String(), err, strings.TrimSpace(string(output)))
+	}
+	f, err := os.Open(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the coverage profile of the test case: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			utils.LogError(c.logger, err, "failed to close the coverage profile of the test case", zap.String("file", profile))
+		}
+	}()
+	files, err := ParseProfile(f)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TestCaseCoverage{
+		TestSet:  testSetID,
+		TestCase: testCaseID,
+		Commit:   c.commit,
+		Dirty:    c.dirty,
+		Files:    files,
+	}, nil
+}
+
+// flush asks the agent of the app to write its coverage counters to the directory and to clear them.
+func (c *Collector) flush(ctx context.Context, dir string) error {
+	flushURL := url.URL{Scheme: "http", Host: c.addr, Path: agent.FlushPath}
+	if dir != "" {
+		flushURL.RawQuery = url.Values{agent.DirParam: []string{dir}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, flushURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach the coverage agent of the app on %s, is it started by the app? %w", c.addr, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogError(c.logger, err, "failed to close the response body of the coverage agent")
+		}
+	}()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("the coverage agent of the app failed to flush the coverage counters, the app has to be built with -cover -covermode=atomic: %s", bytes.TrimSpace(body))
+	}
+	return nil
+}
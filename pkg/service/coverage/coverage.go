@@ -0,0 +1,244 @@
+package coverage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Coverage struct {
+	logger     *zap.Logger
+	coverageDB CoverageDB
+}
+
+func New(logger *zap.Logger, coverageDB CoverageDB) Service {
+	return &Coverage{
+		logger:     logger,
+		coverageDB: coverageDB,
+	}
+}
+
+// Report is the code covered by the test cases of a test run, per test case and per source file.
+type Report struct {
+	TestRun string         `json:"testRun"`
+	Tests   []TestCoverage `json:"tests"`
+	Files   []FileCoverage `json:"files"`
+}
+
+// TestCoverage is the code covered by a test case.
+type TestCoverage struct {
+	TestSet  string                `json:"testSet"`
+	TestCase string                `json:"testCase"`
+	Files    []models.FileCoverage `json:"files"`
+	// Lines is the number of lines covered by the test case
+	Lines int `json:"lines"`
+	// RedundantWith is a test case covering every line covered by this one, the test case can be removed
+	// without losing coverage
+	RedundantWith string `json:"redundantWith,omitempty"`
+	lines         map[string]map[int]bool
+}
+
+// FileCoverage is the coverage of a source file by the test cases.
+type FileCoverage struct {
+	Path string `json:"path"`
+	// Lines is the number of lines of the file covered by at least a test case
+	Lines int `json:"lines"`
+	// Tests are the test cases covering the file as <test set>/<test case>
+	Tests []string `json:"tests"`
+}
+
+// Name returns the name of the test case prefixed with its test set.
+func (t *TestCoverage) Name() string {
+	return t.TestSet + "/" + t.TestCase
+}
+
+func (c *Coverage) Report(ctx context.Context, testRunID string) (*Report, error) {
+	testRunID, err := c.getTestRunID(ctx, testRunID)
+	if err != nil {
+		return nil, err
+	}
+	testSetIDs, err := c.coverageDB.GetAllTestSetIDs(ctx, testRunID)
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to get the test sets of the test run", zap.String("testRunID", testRunID))
+		return nil, err
+	}
+	var coverages []*models.TestCaseCoverage
+	for _, testSetID := range testSetIDs {
+		testSetCoverages, err := c.coverageDB.GetCoverage(ctx, testRunID, testSetID)
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to get the coverage of the test set", zap.String("testRunID", testRunID), zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		coverages = append(coverages, testSetCoverages...)
+	}
+	return NewReport(testRunID, coverages)
+}
+
+// getTestRunID returns the given test run id or the latest test run with coverage data when it is empty
+func (c *Coverage) getTestRunID(ctx context.Context, testRunID string) (string, error) {
+	testRunIDs, err := c.coverageDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to get the test runs with coverage data")
+		return "", err
+	}
+	if len(testRunIDs) == 0 {
+		return "", ErrNoCoverage
+	}
+	if testRunID == "" {
+		return pkg.LastID(testRunIDs, models.TestRunTemplateName), nil
+	}
+	for _, id := range testRunIDs {
+		if id == testRunID {
+			return testRunID, nil
+		}
+	}
+	return "", fmt.Errorf("no coverage data found for test run %s", testRunID)
+}
+
+// NewReport aggregates the coverage of the test cases of the test run, and finds the test cases whose lines
+// are all covered by another one.
+func NewReport(testRunID string, coverages []*models.TestCaseCoverage) (*Report, error) {
+	report := &Report{TestRun: testRunID, Tests: []TestCoverage{}, Files: []FileCoverage{}}
+	files := map[string]*FileCoverage{}
+	fileLines := map[string]map[int]bool{}
+	for _, cov := range coverages {
+		test := TestCoverage{
+			TestSet:  cov.TestSet,
+			TestCase: cov.TestCase,
+			Files:    cov.Files,
+			lines:    map[string]map[int]bool{},
+		}
+		for _, f := range cov.Files {
+			lines, err := ParseLines(f.Lines)
+			if err != nil {
+				return nil, fmt.Errorf("invalid coverage of %s by the test case %s: %w", f.Path, test.Name(), err)
+			}
+			if len(lines) == 0 {
+				continue
+			}
+			test.lines[f.Path] = lines
+			test.Lines += len(lines)
+
+			if files[f.Path] == nil {
+				files[f.Path] = &FileCoverage{Path: f.Path}
+				fileLines[f.Path] = map[int]bool{}
+			}
+			files[f.Path].Tests = append(files[f.Path].Tests, test.Name())
+			for l := range lines {
+				fileLines[f.Path][l] = true
+			}
+		}
+		report.Tests = append(report.Tests, test)
+	}
+
+	// a test case is redundant with a test case covering more lines, or the same lines and run before it, so
+	// that removing all the redundant test cases keeps the coverage
+	for i := range report.Tests {
+		t := &report.Tests[i]
+		if t.Lines == 0 {
+			continue
+		}
+		for j := range report.Tests {
+			u := &report.Tests[j]
+			if i == j || u.Lines < t.Lines || (u.Lines == t.Lines && j > i) {
+				continue
+			}
+			if covers(u.lines, t.lines) {
+				t.RedundantWith = u.Name()
+				break
+			}
+		}
+	}
+
+	for path, f := range files {
+		f.Lines = len(fileLines[path])
+		report.Files = append(report.Files, *f)
+	}
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+	return report, nil
+}
+
+// covers tells whether every line of b is covered by a.
+func covers(a, b map[string]map[int]bool) bool {
+	for path, lines := range b {
+		for l := range lines {
+			if !a[path][l] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// WriteTests writes the human readable per test case view of the coverage. When testCase is set only the
+// test cases with this name are listed, along with the lines they cover.
+func (r *Report) WriteTests(w io.Writer, testCase string) error {
+	var sb strings.Builder
+	redundant := 0
+	for _, t := range r.Tests {
+		if t.RedundantWith != "" {
+			redundant++
+		}
+	}
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	fmt.Fprintf(&sb, " COVERAGE OF THE TEST CASES OF %s\n", r.TestRun)
+	fmt.Fprintf(&sb, "\ttest cases: %d\n", len(r.Tests))
+	fmt.Fprintf(&sb, "\tredundant test cases: %d\n", redundant)
+	if len(r.Tests) > 0 {
+		sb.WriteString("\n")
+		tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "\tTest Set\tTest Case\tFiles\tLines\tRedundant With\n")
+		for _, t := range r.Tests {
+			if testCase != "" && t.TestCase != testCase {
+				continue
+			}
+			fmt.Fprintf(tw, "\t%s\t%s\t%d\t%d\t%s\n", t.TestSet, t.TestCase, len(t.lines), t.Lines, t.RedundantWith)
+			if testCase == "" {
+				continue
+			}
+			for _, f := range t.Files {
+				if f.Lines != "" {
+					fmt.Fprintf(tw, "\t\t\t%s\t%s\t\n", f.Path, f.Lines)
+				}
+			}
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteFiles writes the human readable per file view of the coverage.
+func (r *Report) WriteFiles(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	fmt.Fprintf(&sb, " COVERAGE OF THE FILES BY THE TEST CASES OF %s\n", r.TestRun)
+	fmt.Fprintf(&sb, "\tfiles: %d\n", len(r.Files))
+	if len(r.Files) > 0 {
+		sb.WriteString("\n")
+		tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "\tFile\tLines\tTest Cases\tCovered By\n")
+		for _, f := range r.Files {
+			fmt.Fprintf(tw, "\t%s\t%d\t%d\t%s\n", f.Path, f.Lines, len(f.Tests), strings.Join(f.Tests, ", "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
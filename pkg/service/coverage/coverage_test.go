@@ -0,0 +1,103 @@
+package coverage
+
+import (
+	"reflect"
+	"testing"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+func TestNewReportRedundantTests(t *testing.T) {
+	cov := func(testCase string, files ...models.FileCoverage) *models.TestCaseCoverage {
+		return &models.TestCaseCoverage{TestSet: "test-set-0", TestCase: testCase, Files: files}
+	}
+	file := func(path, lines string) models.FileCoverage {
+		return models.FileCoverage{Path: path, Lines: lines}
+	}
+
+	tests := []struct {
+		name      string
+		coverages []*models.TestCaseCoverage
+		// want are the test cases each test case is redundant with, empty when it isn't
+		want map[string]string
+	}{
+		{
+			name: "subset of a larger test case",
+			coverages: []*models.TestCaseCoverage{
+				cov("test-1", file("a.go", "1-3")),
+				cov("test-2", file("a.go", "1-10"), file("b.go", "4")),
+			},
+			want: map[string]string{"test-1": "test-set-0/test-2", "test-2": ""},
+		},
+		{
+			name: "same lines are redundant with the first test case only",
+			coverages: []*models.TestCaseCoverage{
+				cov("test-1", file("a.go", "1-3")),
+				cov("test-2", file("a.go", "1-3")),
+				cov("test-3", file("a.go", "1,2,3")),
+			},
+			want: map[string]string{"test-1": "", "test-2": "test-set-0/test-1", "test-3": "test-set-0/test-1"},
+		},
+		{
+			name: "overlapping test cases",
+			coverages: []*models.TestCaseCoverage{
+				cov("test-1", file("a.go", "1-3")),
+				cov("test-2", file("a.go", "3-5")),
+			},
+			want: map[string]string{"test-1": "", "test-2": ""},
+		},
+		{
+			name: "same lines of another file",
+			coverages: []*models.TestCaseCoverage{
+				cov("test-1", file("a.go", "1-3")),
+				cov("test-2", file("b.go", "1-3")),
+			},
+			want: map[string]string{"test-1": "", "test-2": ""},
+		},
+		{
+			name: "no covered lines",
+			coverages: []*models.TestCaseCoverage{
+				cov("test-1", file("a.go", "")),
+				cov("test-2", file("a.go", "1")),
+			},
+			want: map[string]string{"test-1": "", "test-2": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := NewReport("test-run-0", tt.coverages)
+			if err != nil {
+				t.Fatalf("NewReport() error = %v", err)
+			}
+			if len(report.Tests) != len(tt.want) {
+				t.Fatalf("NewReport() has %d test cases, want %d", len(report.Tests), len(tt.want))
+			}
+			for _, test := range report.Tests {
+				if test.RedundantWith != tt.want[test.TestCase] {
+					t.Errorf("%s is redundant with %q, want %q", test.TestCase, test.RedundantWith, tt.want[test.TestCase])
+				}
+			}
+		})
+	}
+}
+
+func TestNewReportFiles(t *testing.T) {
+	report, err := NewReport("test-run-0", []*models.TestCaseCoverage{
+		{TestSet: "test-set-0", TestCase: "test-1", Files: []models.FileCoverage{{Path: "b.go", Lines: "1-3"}, {Path: "a.go", Lines: "7"}}},
+		{TestSet: "test-set-1", TestCase: "test-1", Files: []models.FileCoverage{{Path: "b.go", Lines: "3-4"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewReport() error = %v", err)
+	}
+	want := []FileCoverage{
+		{Path: "a.go", Lines: 1, Tests: []string{"test-set-0/test-1"}},
+		{Path: "b.go", Lines: 4, Tests: []string{"test-set-0/test-1", "test-set-1/test-1"}},
+	}
+	if !reflect.DeepEqual(report.Files, want) {
+		t.Errorf("NewReport() files = %+v, want %+v", report.Files, want)
+	}
+
+	if _, err := NewReport("test-run-0", []*models.TestCaseCoverage{{Files: []models.FileCoverage{{Path: "a.go", Lines: "3-1"}}}}); err == nil {
+		t.Error("NewReport() of an invalid line range succeeded, want an error")
+	}
+}
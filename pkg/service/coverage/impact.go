@@ -0,0 +1,376 @@
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/report"
+	"go.uber.org/zap"
+)
+
+// ErrNoCoverage is returned when no coverage of the test cases was captured
+var ErrNoCoverage = errors.New("no coverage data found, please run the testcases using keploy test --goCoverage on an app which starts the keploy coverage agent")
+
+// Impact is the selection of the test cases to run for the changes since a git ref.
+type Impact struct {
+	// Affected are the test cases covering a changed line, per test set
+	Affected map[string][]string
+	// Covered are the test cases with coverage data per test set, the other test cases are run as their
+	// coverage is unknown
+	Covered map[string]map[string]bool
+	// Stale are the test cases whose coverage was not captured on a clean checkout of the ref, as
+	// <test set>/<test case>. Its lines may not match the ones of the ref so they are run as well
+	Stale []string
+	// Changed are the changed go files, Ignored the changed test files and the ones matching the ignore patterns
+	// or outside of the go modules of the app
+	Changed []string
+	Ignored []string
+	// RunAll is set when the changes can't be mapped to the test cases, along with the reason
+	RunAll string
+}
+
+// Impact maps the go code changed since the git ref to the test cases covering it. The changed lines are the
+// ones of the ref, so only the coverage captured on a clean checkout of the ref is used, the latest one of
+// every test case. The changed files matching the ignore patterns are left out.
+func (c *Coverage) Impact(ctx context.Context, ref string, ignore []string) (*Impact, error) {
+	root, err := git(ctx, "", "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	root = strings.TrimSpace(root)
+	commit, err := git(ctx, root, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	commit = strings.TrimSpace(commit)
+	coverages, err := c.latestCoverages(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &Impact{Affected: map[string][]string{}, Covered: map[string]map[string]bool{}}
+	var usable []*models.TestCaseCoverage
+	for _, cov := range coverages {
+		if cov.Commit != commit || cov.Dirty {
+			impact.Stale = append(impact.Stale, cov.TestSet+"/"+cov.TestCase)
+			continue
+		}
+		if impact.Covered[cov.TestSet] == nil {
+			impact.Covered[cov.TestSet] = map[string]bool{}
+		}
+		impact.Covered[cov.TestSet][cov.TestCase] = true
+		usable = append(usable, cov)
+	}
+	if len(usable) == 0 {
+		impact.RunAll = fmt.Sprintf("no coverage of the test cases was captured on a clean checkout of %s", ref)
+		return impact, nil
+	}
+
+	diff, err := git(ctx, root, "diff", "--no-color", "--no-ext-diff", "--unified=0", commit, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes, err := ParseDiff(strings.NewReader(diff))
+	if err != nil {
+		return nil, err
+	}
+
+	// the changed lines keyed by the path of the file in the coverage profiles
+	changed := map[string]map[int]bool{}
+	modules := map[string]string{}
+	for file, lines := range changes {
+		switch base := path.Base(file); {
+		case base == "go.mod" || base == "go.sum" || base == "go.work" || base == "go.work.sum" || inVendor(file):
+			impact.RunAll = fmt.Sprintf("the go dependencies changed in %s", file)
+			return impact, nil
+		case strings.HasSuffix(base, "_test.go") || ignoredChange(file, ignore):
+			impact.Ignored = append(impact.Ignored, file)
+			continue
+		case !strings.HasSuffix(base, ".go"):
+			// the files of a module can be embedded or read by the app, their changes are not mapped to lines
+			if dir, _ := moduleOf(root, file, modules, c.logger); dir != "" {
+				impact.RunAll = fmt.Sprintf("the file %s of the go module in %s changed", file, dir)
+				return impact, nil
+			}
+			impact.Ignored = append(impact.Ignored, file)
+			continue
+		}
+		impact.Changed = append(impact.Changed, file)
+		changed[importPath(root, file, modules, c.logger)] = lines
+	}
+
+	for _, cov := range usable {
+		if coversChange(cov, changed) {
+			impact.Affected[cov.TestSet] = append(impact.Affected[cov.TestSet], cov.TestCase)
+		}
+	}
+	return impact, nil
+}
+
+// latestCoverages returns the latest coverage of every test case across the test runs, so that the test runs
+// which ran a part of the test cases don't hide the coverage of the other ones. The coverage captured on a
+// clean checkout of the commit is preferred to a later one captured on another checkout.
+func (c *Coverage) latestCoverages(ctx context.Context, commit string) ([]*models.TestCaseCoverage, error) {
+	testRunIDs, err := c.coverageDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(testRunIDs) == 0 {
+		return nil, ErrNoCoverage
+	}
+	report.SortTestRuns(testRunIDs)
+
+	onCommit := func(cov *models.TestCaseCoverage) bool {
+		return cov.Commit == commit && !cov.Dirty
+	}
+	type testKey struct{ testSet, testCase string }
+	latest := map[testKey]int{}
+	var coverages []*models.TestCaseCoverage
+	for _, testRunID := range testRunIDs {
+		testSetIDs, err := c.coverageDB.GetAllTestSetIDs(ctx, testRunID)
+		if err != nil {
+			return nil, err
+		}
+		for _, testSetID := range testSetIDs {
+			testSetCoverages, err := c.coverageDB.GetCoverage(ctx, testRunID, testSetID)
+			if err != nil {
+				return nil, err
+			}
+			for _, cov := range testSetCoverages {
+				key := testKey{cov.TestSet, cov.TestCase}
+				if i, ok := latest[key]; ok {
+					if onCommit(cov) || !onCommit(coverages[i]) {
+						coverages[i] = cov
+					}
+					continue
+				}
+				latest[key] = len(coverages)
+				coverages = append(coverages, cov)
+			}
+		}
+	}
+	return coverages, nil
+}
+
+// coversChange tells whether the test case covers one of the changed lines.
+func coversChange(cov *models.TestCaseCoverage, changed map[string]map[int]bool) bool {
+	for _, f := range cov.Files {
+		changedLines, ok := changed[f.Path]
+		if !ok {
+			// the files outside of a go module are matched by the end of their path
+			for file, lines := range changed {
+				if strings.HasPrefix(file, "/") && strings.HasSuffix(f.Path, file) {
+					changedLines, ok = lines, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		lines, err := ParseLines(f.Lines)
+		if err != nil {
+			// the test case is run when its coverage can't be read
+			return true
+		}
+		for l := range changedLines {
+			if lines[l] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ParseDiff returns the lines changed by a unified diff, per file. The lines are the ones of the old version
+// of the files, as the coverage was captured on it: the removed and the replaced lines, and the lines around
+// the insertions.
+func ParseDiff(r io.Reader) (map[string]map[int]bool, error) {
+	changes := map[string]map[int]bool{}
+	var file string
+	// the header of a file ends with its first hunk, the lines removed by the hunks can start with --- as well
+	header := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file, header = "", true
+		case header && strings.HasPrefix(line, "--- "):
+			// the new files have no coverage, they are left out along with their /dev/null old version
+			file = diffPath(line[4:])
+		case strings.HasPrefix(line, "@@ "):
+			header = false
+			if file == "" {
+				continue
+			}
+			// @@ -<start>[,<count>] +<start>[,<count>] @@
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+				return nil, fmt.Errorf("invalid hunk header %q", line)
+			}
+			start, count, err := hunkRange(fields[1][1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid hunk header %q: %w", line, err)
+			}
+			if changes[file] == nil {
+				changes[file] = map[int]bool{}
+			}
+			if count == 0 {
+				// the lines are inserted after the start line
+				changes[file][start] = true
+				changes[file][start+1] = true
+				continue
+			}
+			for l := start; l < start+count; l++ {
+				changes[file][l] = true
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// diffPath returns the path of the file of a ---/+++ line of a diff, it is empty for /dev/null.
+func diffPath(p string) string {
+	p, _, _ = strings.Cut(p, "\t")
+	if p == "/dev/null" {
+		return ""
+	}
+	if unquoted, err := strconv.Unquote(p); err == nil {
+		p = unquoted
+	}
+	if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
+		p = p[2:]
+	}
+	return p
+}
+
+func hunkRange(r string) (int, int, error) {
+	startStr, countStr, hasCount := strings.Cut(r, ",")
+	start, err := strconv.Atoi(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if hasCount {
+		count, err = strconv.Atoi(countStr)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+// importPath returns the path of the file in the coverage profiles, the import path of its package followed by
+// its name. The files outside of a go module are returned with a leading slash to be matched by suffix.
+func importPath(root, file string, modules map[string]string, logger *zap.Logger) string {
+	dir, module := moduleOf(root, file, modules, logger)
+	if dir == "" {
+		return "/" + file
+	}
+	if dir == "." {
+		return module + "/" + file
+	}
+	return module + "/" + strings.TrimPrefix(file, dir+"/")
+}
+
+// moduleOf returns the directory of the go module of the file and the module path, the directory is empty
+// when the file is outside of a go module. The module paths are cached by directory.
+func moduleOf(root, file string, modules map[string]string, logger *zap.Logger) (string, string) {
+	for d := path.Dir(file); ; d = path.Dir(d) {
+		module, ok := modules[d]
+		if !ok {
+			module = modulePath(filepath.Join(root, filepath.FromSlash(d), "go.mod"), logger)
+			modules[d] = module
+		}
+		if module != "" {
+			return d, module
+		}
+		if d == "." || d == "/" {
+			return "", ""
+		}
+	}
+}
+
+// inVendor tells whether the file is in the vendor directory of a go module.
+func inVendor(file string) bool {
+	return strings.HasPrefix(file, "vendor/") || strings.Contains(file, "/vendor/")
+}
+
+// ignoredChange tells whether the changed file matches one of the ignore patterns. A pattern is matched against
+// the path of the file, its name, and the paths and names of its parent directories.
+func ignoredChange(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		for p := file; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := path.Match(pattern, p); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(p)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// modulePath returns the module path declared by the go.mod file, it is empty when there is no such file.
+func modulePath(goMod string, logger *zap.Logger) string {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			if module, err := strconv.Unquote(fields[1]); err == nil {
+				return module
+			}
+			return fields[1]
+		}
+	}
+	logger.Debug("no module path found in the go.mod file", zap.String("file", goMod))
+	return ""
+}
+
+// Revision returns the git commit checked out in the directory, the current directory when it is empty, and
+// whether the checkout has uncommitted changes to the tracked files.
+func Revision(ctx context.Context, dir string) (string, bool, error) {
+	commit, err := git(ctx, dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", false, err
+	}
+	status, err := git(ctx, dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(commit), strings.TrimSpace(status) != "", nil
+}
+
+// git runs the git command in the directory, the current directory when it is empty, and returns its output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s: %w: %s", cmd.String(), err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
@@ -0,0 +1,222 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+func TestParseDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want map[string]map[int]bool
+	}{
+		{
+			name: "changed lines",
+			diff: `diff --git a/app/main.go b/app/main.go
+index 1111111..2222222 100644
+--- a/app/main.go
++++ b/app/main.go
+@@ -10,2 +10,3 @@ func main() {
+-	a()
+-	b()
++	a(1)
++	b(2)
++	c()
+@@ -20 +21 @@ func f() {
+-	return
++	return nil
+`,
+			want: map[string]map[int]bool{"app/main.go": {10: true, 11: true, 20: true}},
+		},
+		{
+			name: "zero count insertion",
+			diff: `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -7,0 +8,2 @@ func main() {
++	a()
++	b()
+`,
+			want: map[string]map[int]bool{"main.go": {7: true, 8: true}},
+		},
+		{
+			name: "insertion at the top of the file",
+			diff: `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -0,0 +1 @@
++// Package main
+`,
+			want: map[string]map[int]bool{"main.go": {0: true, 1: true}},
+		},
+		{
+			name: "new file",
+			diff: `diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,3 @@
++package main
+`,
+			want: map[string]map[int]bool{},
+		},
+		{
+			name: "removed lines starting with ---",
+			diff: `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3,2 +2,0 @@
+---- a comment
+--- b/other.go
+diff --git "a/dir with space/b.go" "b/dir with space/b.go"
+--- "a/dir with space/b.go"
++++ "b/dir with space/b.go"
+@@ -5 +5 @@
+-x
++y
+`,
+			want: map[string]map[int]bool{"a.go": {3: true, 4: true}, "dir with space/b.go": {5: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDiff(strings.NewReader(tt.diff))
+			if err != nil {
+				t.Fatalf("ParseDiff() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDiff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseDiff(strings.NewReader("diff --git a/a.go b/a.go\n--- a/a.go\n@@ -x +1 @@\n")); err == nil {
+		t.Error("ParseDiff() of an invalid hunk header succeeded, want an error")
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		r         string
+		start     int
+		count     int
+		wantError bool
+	}{
+		{r: "12", start: 12, count: 1},
+		{r: "12,3", start: 12, count: 3},
+		{r: "7,0", start: 7, count: 0},
+		{r: "x,1", wantError: true},
+		{r: "1,y", wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.r, func(t *testing.T) {
+			start, count, err := hunkRange(tt.r)
+			if (err != nil) != tt.wantError {
+				t.Fatalf("hunkRange(%q) error = %v, want error %v", tt.r, err, tt.wantError)
+			}
+			if err == nil && (start != tt.start || count != tt.count) {
+				t.Errorf("hunkRange(%q) = %d, %d, want %d, %d", tt.r, start, count, tt.start, tt.count)
+			}
+		})
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/app // the app\n\ngo 1.22\n")
+	writeFile(t, filepath.Join(root, "tools", "go.mod"), "module \"example.com/tools\"\n")
+	writeFile(t, filepath.Join(root, "broken", "go.mod"), "go 1.22\n")
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"main.go", "example.com/app/main.go"},
+		{"pkg/handler/user.go", "example.com/app/pkg/handler/user.go"},
+		{"tools/gen/main.go", "example.com/tools/gen/main.go"},
+		{"broken/x.go", "example.com/app/broken/x.go"},
+	}
+	modules := map[string]string{}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := importPath(root, tt.file, modules, zap.NewNop()); got != tt.want {
+				t.Errorf("importPath(%q) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+
+	outside := t.TempDir()
+	if got := importPath(outside, "pkg/a.go", map[string]string{}, zap.NewNop()); got != "/pkg/a.go" {
+		t.Errorf("importPath() outside of a module = %q, want %q", got, "/pkg/a.go")
+	}
+}
+
+func TestCoversChange(t *testing.T) {
+	cov := &models.TestCaseCoverage{Files: []models.FileCoverage{
+		{Path: "example.com/app/main.go", Lines: "10-12,20"},
+		{Path: "example.com/app/pkg/a.go", Lines: "5"},
+	}}
+	tests := []struct {
+		name    string
+		changed map[string]map[int]bool
+		want    bool
+	}{
+		{"covered line", map[string]map[int]bool{"example.com/app/main.go": {11: true}}, true},
+		{"line not covered", map[string]map[int]bool{"example.com/app/main.go": {13: true, 19: true}}, false},
+		{"file not covered", map[string]map[int]bool{"example.com/app/b.go": {5: true}}, false},
+		{"outside of a module by suffix", map[string]map[int]bool{"/pkg/a.go": {5: true}}, true},
+		{"no change", map[string]map[int]bool{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := coversChange(cov, tt.changed); got != tt.want {
+				t.Errorf("coversChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	invalid := &models.TestCaseCoverage{Files: []models.FileCoverage{{Path: "example.com/app/main.go", Lines: "x"}}}
+	if !coversChange(invalid, map[string]map[int]bool{"example.com/app/main.go": {1: true}}) {
+		t.Error("coversChange() of an unreadable coverage = false, want true")
+	}
+}
+
+func TestIgnoredChange(t *testing.T) {
+	patterns := []string{"*.md", "keploy", "docs/*.txt"}
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"README.md", true},
+		{"pkg/README.md", true},
+		{"keploy/test-set-0/tests/test-1.yaml", true},
+		{"app/keploy/config.yaml", true},
+		{"docs/notes.txt", true},
+		{"templates/index.html", false},
+		{"keploy.yml", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			if got := ignoredChange(tt.file, patterns); got != tt.want {
+				t.Errorf("ignoredChange(%q) = %v, want %v", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
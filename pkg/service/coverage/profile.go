@@ -0,0 +1,111 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// ParseProfile returns the lines covered by the blocks of a go coverage profile, as written by go tool covdata
+// textfmt, which were run at least once. The files are sorted by path.
+func ParseProfile(r io.Reader) ([]models.FileCoverage, error) {
+	covered := map[string]map[int]bool{}
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// <file>:<start line>.<start col>,<end line>.<end col> <statements> <count>
+		fields := strings.Fields(line)
+		colon := strings.LastIndex(line, ":")
+		if len(fields) != 3 || colon < 0 {
+			return nil, fmt.Errorf("invalid line %d of the coverage profile: %q", n, line)
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid count at line %d of the coverage profile: %w", n, err)
+		}
+		if count == 0 {
+			continue
+		}
+		file := line[:colon]
+		var startLine, startCol, endLine, endCol int
+		if _, err := fmt.Sscanf(line[colon+1:], "%d.%d,%d.%d", &startLine, &startCol, &endLine, &endCol); err != nil {
+			return nil, fmt.Errorf("invalid block at line %d of the coverage profile: %w", n, err)
+		}
+		if covered[file] == nil {
+			covered[file] = map[int]bool{}
+		}
+		for l := startLine; l <= endLine; l++ {
+			covered[file][l] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	files := make([]models.FileCoverage, 0, len(covered))
+	for file, lines := range covered {
+		files = append(files, models.FileCoverage{Path: file, Lines: FormatLines(lines)})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// FormatLines returns the lines as a comma separated list of ranges, e.g. 12-18,20.
+func FormatLines(lines map[int]bool) string {
+	sorted := make([]int, 0, len(lines))
+	for l := range lines {
+		sorted = append(sorted, l)
+	}
+	sort.Ints(sorted)
+
+	var ranges []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(sorted[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
+
+// ParseLines returns the lines of a comma separated list of ranges written by FormatLines.
+func ParseLines(ranges string) (map[int]bool, error) {
+	lines := map[int]bool{}
+	if ranges == "" {
+		return lines, nil
+	}
+	for _, r := range strings.Split(ranges, ",") {
+		start, end, isRange := strings.Cut(r, "-")
+		first, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, fmt.Errorf("invalid line range %q: %w", r, err)
+		}
+		last := first
+		if isRange {
+			last, err = strconv.Atoi(end)
+			if err != nil || last < first {
+				return nil, fmt.Errorf("invalid line range %q", r)
+			}
+		}
+		for l := first; l <= last; l++ {
+			lines[l] = true
+		}
+	}
+	return lines, nil
+}
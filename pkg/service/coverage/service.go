@@ -0,0 +1,31 @@
+// Package coverage provides the services to capture the code covered by each test case and to report it.
+package coverage
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// views of the coverage report
+const (
+	// ViewTest lists the test cases with the code they cover and the ones covering no line of their own
+	ViewTest = "test"
+	// ViewFile lists the source files with the test cases covering them
+	ViewFile = "file"
+)
+
+type Service interface {
+	// Report gathers the coverage of the test cases of the test run, the latest test run with coverage data
+	// is used when testRunID is empty.
+	Report(ctx context.Context, testRunID string) (*Report, error)
+	// Impact maps the go code changed since the git ref to the test cases whose coverage was captured on it,
+	// it returns ErrNoCoverage when the coverage of the test cases was never captured.
+	Impact(ctx context.Context, ref string, ignore []string) (*Impact, error)
+}
+
+type CoverageDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetAllTestSetIDs(ctx context.Context, testRunID string) ([]string, error)
+	GetCoverage(ctx context.Context, testRunID string, testSetID string) ([]*models.TestCaseCoverage, error)
+}
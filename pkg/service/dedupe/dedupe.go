@@ -0,0 +1,58 @@
+package dedupe
+
+import (
+	"context"
+	"sort"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Deduper struct {
+	logger *zap.Logger
+	testDB TestDB
+	mockDB MockDB
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB) Service {
+	return &Deduper{
+		logger: logger,
+		testDB: testDB,
+		mockDB: mockDB,
+	}
+}
+
+func (d *Deduper) Dedupe(ctx context.Context, dryRun bool) error {
+	testSetIDs, err := d.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(d.logger, err, "failed to get the test sets")
+		return err
+	}
+	sort.Strings(testSetIDs)
+
+	replaced, err := d.mockDB.DedupeMocks(ctx, testSetIDs, dryRun)
+	if err != nil {
+		utils.LogError(d.logger, err, "failed to dedupe the mocks")
+		return err
+	}
+
+	var total int
+	for _, testSetID := range testSetIDs {
+		if replaced[testSetID] == 0 {
+			continue
+		}
+		msg := "replaced the duplicated mocks of the test set with references to the shared mock pool"
+		if dryRun {
+			msg = "duplicated mocks of the test set would be moved to the shared mock pool"
+		}
+		d.logger.Info(msg, zap.String("testSetID", testSetID), zap.Int("mocks", replaced[testSetID]))
+		total += replaced[testSetID]
+	}
+
+	msg := "deduplicated the mocks of the test sets"
+	if dryRun {
+		msg = "dry run of the mock deduplication completed, no files were changed"
+	}
+	d.logger.Info(msg, zap.Int("mocks", total))
+	return nil
+}
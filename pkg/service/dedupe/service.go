@@ -0,0 +1,19 @@
+// Package dedupe provides the service to move the mocks shared by the test sets into the shared mock pool.
+package dedupe
+
+import (
+	"context"
+)
+
+type Service interface {
+	// Dedupe moves the mocks recorded more than once into the shared mock pool, nothing is written in dry run mode.
+	Dedupe(ctx context.Context, dryRun bool) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+}
+
+type MockDB interface {
+	DedupeMocks(ctx context.Context, testSetIDs []string, dryRun bool) (map[string]int, error)
+}
@@ -0,0 +1,279 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// Severity of a diagnostic, only the errors make the replay fail.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found at a line of a testcase or mock file.
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s: %s", d.File, d.Line, d.Severity, d.Message)
+}
+
+// Result holds the diagnostics of all the test sets sorted by file and line.
+type Result struct {
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	TestCases   int          `json:"testCases"`
+	Mocks       int          `json:"mocks"`
+}
+
+// Errors returns the number of diagnostics with the error severity.
+func (r *Result) Errors() int {
+	n := 0
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			n++
+		}
+	}
+	return n
+}
+
+// Write prints the diagnostics one per line followed by a summary.
+func (r *Result) Write(w io.Writer) error {
+	for _, d := range r.Diagnostics {
+		if _, err := fmt.Fprintln(w, d.String()); err != nil {
+			return err
+		}
+	}
+	errs := r.Errors()
+	_, err := fmt.Fprintf(w, "checked %d testcases and %d mocks: %d errors, %d warnings\n", r.TestCases, r.Mocks, errs, len(r.Diagnostics)-errs)
+	return err
+}
+
+type Linter struct {
+	logger *zap.Logger
+	testDB TestDB
+	mockDB MockDB
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB) Service {
+	return &Linter{
+		logger: logger,
+		testDB: testDB,
+		mockDB: mockDB,
+	}
+}
+
+func (l *Linter) Lint(ctx context.Context) (*Result, error) {
+	testSetIDs, err := l.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(l.logger, err, "failed to get the test sets")
+		return nil, err
+	}
+
+	res := &Result{Diagnostics: []Diagnostic{}}
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		tcs, err := l.testDB.GetLocatedTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(l.logger, err, "failed to read the testcases", zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		for _, tc := range tcs {
+			res.Diagnostics = append(res.Diagnostics, lintTestCase(tc)...)
+			res.TestCases++
+		}
+		mocks, err := l.mockDB.GetLocatedMocks(ctx, testSetID)
+		if err != nil {
+			utils.LogError(l.logger, err, "failed to read the mocks", zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		res.Diagnostics = append(res.Diagnostics, lintMocks(mocks)...)
+		res.Mocks += len(mocks)
+	}
+	// the mocks of the shared pool are checked like the ones of a test set
+	mocks, err := l.mockDB.GetLocatedMocks(ctx, yaml.SharedMocksDir)
+	if err != nil {
+		utils.LogError(l.logger, err, "failed to read the shared mocks")
+		return nil, err
+	}
+	res.Diagnostics = append(res.Diagnostics, lintMocks(mocks)...)
+	res.Mocks += len(mocks)
+
+	cwd, _ := os.Getwd()
+	for i := range res.Diagnostics {
+		if rel, err := filepath.Rel(cwd, res.Diagnostics[i].File); err == nil && !strings.HasPrefix(rel, "..") {
+			res.Diagnostics[i].File = rel
+		}
+	}
+	sort.SliceStable(res.Diagnostics, func(i, j int) bool {
+		if res.Diagnostics[i].File != res.Diagnostics[j].File {
+			return res.Diagnostics[i].File < res.Diagnostics[j].File
+		}
+		return res.Diagnostics[i].Line < res.Diagnostics[j].Line
+	})
+	return res, nil
+}
+
+func lintTestCase(d yaml.LocatedDoc[*models.TestCase]) []Diagnostic {
+	if d.Err != nil {
+		return []Diagnostic{{File: d.File, Line: d.Line, Severity: SeverityError, Message: fmt.Sprintf("testcase does not decode: %v", d.Err)}}
+	}
+	tc := d.Value
+	var diags []Diagnostic
+	add := func(line int, severity Severity, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{File: d.File, Line: line, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if name := strings.TrimSuffix(filepath.Base(d.File), filepath.Ext(d.File)); tc.Name != name {
+		add(yaml.KeyLine(d.Node, "name"), SeverityWarning, "testcase name %q does not match the file name %q", tc.Name, name)
+	}
+	if tc.Kind != models.HTTP {
+		return diags
+	}
+
+	req, resp := tc.HTTPReq.Timestamp, tc.HTTPResp.Timestamp
+	if !req.IsZero() && !resp.IsZero() && resp.Before(req) {
+		add(yaml.KeyLine(d.Node, "spec", "resp", "timestamp"), SeverityError, "response timestamp %s is before the request timestamp %s", resp, req)
+	}
+
+	fields := responseFields(tc.HTTPResp)
+	keys := make([]string, 0, len(tc.Noise))
+	for k := range tc.Noise {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		line := yaml.KeyLine(d.Node, "spec", "assertions", "noise", key)
+		re, err := regexp.Compile(key)
+		if err != nil {
+			add(line, SeverityError, "noise key %q is not a valid regular expression: %v", key, err)
+			continue
+		}
+		if !noiseMatches(key, re, fields) {
+			add(line, SeverityWarning, "noise key %q does not refer to any field of the response", key)
+		}
+	}
+
+	if tc.Curl != "" && !sameCurl(tc.Curl, pkg.MakeCurlCommand(string(tc.HTTPReq.Method), tc.HTTPReq.URL, pkg.ToYamlHTTPHeader(pkg.ToHTTPHeader(tc.HTTPReq.Header)), tc.HTTPReq.Body)) {
+		add(yaml.KeyLine(d.Node, "curl"), SeverityWarning, "curl command does not match the request of the testcase")
+	}
+	return diags
+}
+
+func lintMocks(docs []yaml.LocatedDoc[*models.Mock]) []Diagnostic {
+	var diags []Diagnostic
+	names := map[string]int{}
+	for _, d := range docs {
+		if d.Err != nil {
+			diags = append(diags, Diagnostic{File: d.File, Line: d.Line, Severity: SeverityError, Message: fmt.Sprintf("mock does not decode: %v", d.Err)})
+			continue
+		}
+		mock := d.Value
+		if mock == nil {
+			continue
+		}
+		if line, ok := names[mock.Name]; ok {
+			diags = append(diags, Diagnostic{File: d.File, Line: yaml.KeyLine(d.Node, "name"), Severity: SeverityError, Message: fmt.Sprintf("mock name %q is already used at line %d", mock.Name, line)})
+		} else {
+			names[mock.Name] = yaml.KeyLine(d.Node, "name")
+		}
+		req, resp := mock.Spec.ReqTimestampMock, mock.Spec.ResTimestampMock
+		if !req.IsZero() && !resp.IsZero() && resp.Before(req) {
+			// the timestamps of the mocks moved to the shared pool are kept in their reference
+			line := yaml.KeyLine(d.Node, "ref", "resTimestampMock")
+			if line == d.Line {
+				line = yaml.KeyLine(d.Node, "spec", "resTimestampMock")
+			}
+			diags = append(diags, Diagnostic{File: d.File, Line: line, Severity: SeverityError, Message: fmt.Sprintf("response timestamp %s of mock %s is before its request timestamp %s", resp, mock.Name, req)})
+		}
+	}
+	return diags
+}
+
+// responseFields returns the noise keys of the fields of the response along with their parents.
+func responseFields(resp models.HTTPResp) map[string]bool {
+	fields := map[string]bool{"body": true, "header": true}
+	for k := range resp.Header {
+		fields["header."+strings.ToLower(k)] = true
+	}
+	var body interface{}
+	if json.Unmarshal([]byte(resp.Body), &body) == nil {
+		addFields("body", body, fields)
+	}
+	return fields
+}
+
+// addFields adds the paths of the json value, array elements share the path of the array like in the noise keys.
+func addFields(prefix string, v interface{}, fields map[string]bool) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			key := prefix + "." + strings.ToLower(k)
+			fields[key] = true
+			addFields(key, child, fields)
+		}
+	case []interface{}:
+		for _, child := range val {
+			addFields(prefix, child, fields)
+		}
+	}
+}
+
+// noiseMatches reports whether the noise key selects a field of the response, the keys are regular expressions.
+func noiseMatches(key string, re *regexp.Regexp, fields map[string]bool) bool {
+	if fields[key] {
+		return true
+	}
+	for f := range fields {
+		if re.MatchString(f) || re.MatchString(strings.TrimPrefix(strings.TrimPrefix(f, "body."), "header.")) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameCurl compares the curl commands ignoring the order of the headers and the indentation.
+func sameCurl(a, b string) bool {
+	split := func(s string) []string {
+		var lines []string
+		for _, l := range strings.Split(s, "\n") {
+			l = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(l), "\\"))
+			if l != "" {
+				lines = append(lines, l)
+			}
+		}
+		sort.Strings(lines)
+		return lines
+	}
+	x, y := split(a), split(b)
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,23 @@
+// Package lint provides the service to validate the recorded testcases and mocks before they are replayed.
+package lint
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+)
+
+type Service interface {
+	// Lint decodes the testcases and mocks of every test set and returns the problems found in them.
+	Lint(ctx context.Context) (*Result, error)
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	GetLocatedTestCases(ctx context.Context, testSetID string) ([]yaml.LocatedDoc[*models.TestCase], error)
+}
+
+type MockDB interface {
+	GetLocatedMocks(ctx context.Context, testSetID string) ([]yaml.LocatedDoc[*models.Mock], error)
+}
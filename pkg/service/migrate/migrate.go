@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Migrator struct {
+	logger *zap.Logger
+	testDB TestDB
+	mockDB MockDB
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB) Service {
+	return &Migrator{
+		logger: logger,
+		testDB: testDB,
+		mockDB: mockDB,
+	}
+}
+
+func (m *Migrator) Migrate(ctx context.Context, dryRun bool) error {
+	testSetIDs, err := m.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(m.logger, err, "failed to get the test sets")
+		return err
+	}
+
+	var testCount, mockCount int
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		tcs, err := m.testDB.MigrateTestCases(ctx, testSetID, dryRun)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to migrate the testcases", zap.String("testSetID", testSetID))
+			return err
+		}
+		mocks, err := m.mockDB.MigrateMocks(ctx, testSetID, dryRun)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to migrate the mocks", zap.String("testSetID", testSetID))
+			return err
+		}
+		if len(tcs) == 0 && len(mocks) == 0 {
+			m.logger.Debug("test set is already at the latest schema version", zap.String("testSetID", testSetID))
+			continue
+		}
+		msg := "upgraded the test set to the latest schema version"
+		if dryRun {
+			msg = "test set would be upgraded to the latest schema version"
+		}
+		m.logger.Info(msg, zap.String("testSetID", testSetID), zap.Strings("testcases", tcs), zap.Strings("mocks", mocks))
+		testCount += len(tcs)
+		mockCount += len(mocks)
+	}
+	// the shared mock pool is not a test set, its mocks are migrated after the ones of the test sets
+	mocks, err := m.mockDB.MigrateMocks(ctx, yaml.SharedMocksDir, dryRun)
+	if err != nil {
+		utils.LogError(m.logger, err, "failed to migrate the shared mocks")
+		return err
+	}
+	mockCount += len(mocks)
+
+	msg := "migrated the testcases and mocks"
+	if dryRun {
+		msg = "dry run of the migration completed, no files were changed"
+	}
+	m.logger.Info(msg, zap.String("schemaVersion", string(yaml.LatestVersion)), zap.Int("testcases", testCount), zap.Int("mocks", mockCount))
+	return nil
+}
@@ -0,0 +1,20 @@
+// Package migrate provides the service to upgrade the recorded testcases and mocks to the latest schema version.
+package migrate
+
+import (
+	"context"
+)
+
+type Service interface {
+	// Migrate upgrades the testcases and mocks of the test sets in place, nothing is written in dry run mode.
+	Migrate(ctx context.Context, dryRun bool) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	MigrateTestCases(ctx context.Context, testSetID string, dryRun bool) ([]string, error)
+}
+
+type MockDB interface {
+	MigrateMocks(ctx context.Context, testSetID string, dryRun bool) ([]string, error)
+}
@@ -0,0 +1,238 @@
+//go:build linux
+
+package record
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// denoiseHeader marks the requests which are replayed to find the noise, they are not recorded as test cases
+const denoiseHeader = "Keploy-Denoise"
+
+// safeDenoiseMethods are the methods of the requests which are replayed to find the noise by default, the
+// replay of the other methods may repeat their side effects
+var safeDenoiseMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// replaysToDenoise tells whether the captured request is replayed to find the noise, by its method.
+func (r *Recorder) replaysToDenoise(tc *models.TestCase) bool {
+	method := string(tc.HTTPReq.Method)
+	for _, m := range append(safeDenoiseMethods, r.config.Record.DenoiseMethods...) {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDenoiseReplay tells whether the captured request is the replay of a test case by denoiseTestCase.
+func isDenoiseReplay(tc *models.TestCase) bool {
+	for k := range tc.HTTPReq.Header {
+		if strings.EqualFold(k, denoiseHeader) {
+			return true
+		}
+	}
+	return false
+}
+
+// denoiseWindow is the time window of a request replayed to find the noise, the end is zero until the replay
+// is done. The mocks recorded during the window are held until it is known whether they are the calls of the
+// replay only.
+type denoiseWindow struct {
+	testCase string
+	start    time.Time
+	end      time.Time
+	held     []*models.Mock
+	// overlapped is set when a recorded request was handled during the window, its calls can't be told apart
+	// from the ones of the replay
+	overlapped bool
+	// dropped is set once the held mocks are dropped as the calls of the replay
+	dropped bool
+}
+
+// denoiseWindows are the windows of the requests replayed to find the noise. The replays are serialized, so
+// that the calls made by the app during a window are the duplicates of the ones of the replayed request unless
+// a recorded request is handled at the same time. The mocks of such a window are kept, as the mocks of the
+// recorded test cases must not be lost.
+type denoiseWindows struct {
+	logger *zap.Logger
+	// settle is how long the recorded requests handled during a window may take to be captured after its end
+	settle  time.Duration
+	replay  sync.Mutex
+	mu      sync.Mutex
+	windows []*denoiseWindow
+}
+
+// open starts the window of the replay of the test case, it waits for the window of the previous replay to be
+// closed.
+func (w *denoiseWindows) open(testCase string) *denoiseWindow {
+	w.replay.Lock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	window := &denoiseWindow{testCase: testCase, start: time.Now()}
+	w.windows = append(w.windows, window)
+	return window
+}
+
+// close ends the window once the response of the replay is received.
+func (w *denoiseWindows) close(window *denoiseWindow) {
+	w.mu.Lock()
+	window.end = time.Now()
+	w.mu.Unlock()
+	w.replay.Unlock()
+}
+
+// recorded marks the windows during which the recorded test case was handled.
+func (w *denoiseWindows) recorded(tc *models.TestCase) {
+	start, end := tc.HTTPReq.Timestamp, tc.HTTPResp.Timestamp
+	if tc.Kind == models.GRPC_EXPORT {
+		start, end = tc.GrpcReq.Timestamp, tc.GrpcResp.Timestamp
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, window := range w.windows {
+		if (!window.end.IsZero() && start.After(window.end)) || end.Before(window.start) {
+			continue
+		}
+		if window.dropped {
+			w.logger.Warn("the mocks recorded during the replay of a test case to find the noise were dropped, but the test case was handled at the same time and may miss some of its mocks. Record it again",
+				zap.String("testcase", tc.Name), zap.String("replayed testcase", window.testCase))
+			continue
+		}
+		window.overlapped = true
+	}
+}
+
+// hold holds the mock when it was recorded during a window which isn't settled.
+func (w *denoiseWindows) hold(mock *models.Mock) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	reqTime := mock.Spec.ReqTimestampMock
+	for _, window := range w.windows {
+		if window.dropped || reqTime.Before(window.start) || (!window.end.IsZero() && reqTime.After(window.end)) {
+			continue
+		}
+		window.held = append(window.held, mock)
+		return true
+	}
+	return false
+}
+
+// release settles the windows which ended long enough ago, or all of them once the recording is done. It
+// returns the held mocks to record: the ones of the windows during which a recorded request was handled, or
+// which didn't end. The mocks of the other windows are the calls of the replays and are dropped.
+func (w *denoiseWindows) release(all bool) []*models.Mock {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var kept []*models.Mock
+	var windows []*denoiseWindow
+	for _, window := range w.windows {
+		settled := !window.end.IsZero() && time.Since(window.end) > w.settle
+		if window.dropped || (!all && !settled) {
+			windows = append(windows, window)
+			continue
+		}
+		if window.overlapped || window.end.IsZero() {
+			if len(window.held) > 0 {
+				w.logger.Warn("keeping the mocks recorded during the replay of a test case to find the noise, as other requests were handled at the same time. The mocks may duplicate the ones of the test case",
+					zap.String("testcase", window.testCase), zap.Int("mocks", len(window.held)))
+			}
+			kept = append(kept, window.held...)
+			continue
+		}
+		w.logger.Debug("dropping the mocks of the request replayed to find the noise", zap.String("testcase", window.testCase), zap.Int("mocks", len(window.held)))
+		window.held = nil
+		window.dropped = true
+		// the dropped windows are kept to warn about the recorded requests captured late
+		windows = append(windows, window)
+	}
+	w.windows = windows
+	return kept
+}
+
+// cloneHTTPTestCase copies the maps of the http test case which are changed by the redaction, so that the
+// request is replayed with its actual values.
+func cloneHTTPTestCase(tc *models.TestCase) models.TestCase {
+	clone := *tc
+	clone.HTTPReq.URLParams = maps.Clone(tc.HTTPReq.URLParams)
+	clone.HTTPReq.Header = maps.Clone(tc.HTTPReq.Header)
+	clone.HTTPResp.Header = maps.Clone(tc.HTTPResp.Header)
+	return clone
+}
+
+// denoiseTestCase sends the request of the recorded test case to the app a second time, and proposes the
+// fields of the response which differ from the recorded one as noise in the config of the test set. The
+// proposals are added to the test cases once reviewed, with keploy denoise --apply. The outgoing calls of
+// the replayed request duplicate the ones of the recorded request, they are not recorded as mocks unless other
+// requests are handled at the same time, see denoiseWindows.
+func (r *Recorder) denoiseTestCase(ctx context.Context, appID uint64, testSetID string, tc models.TestCase) {
+	if utils.IsDockerKind(utils.CmdType(r.config.CommandType)) {
+		userIP, err := r.instrumentation.GetContainerIP(ctx, appID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the app ip")
+			return
+		}
+		tc.HTTPReq.URL, err = utils.ReplaceHostToIP(tc.HTTPReq.URL, userIP)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to replace host to docker container's IP")
+			return
+		}
+	}
+	if tc.HTTPReq.Header == nil {
+		tc.HTTPReq.Header = map[string]string{}
+	}
+	tc.HTTPReq.Header[denoiseHeader] = "true"
+
+	window := r.denoiseWindows.open(tc.Name)
+	resp, err := pkg.SimulateHTTP(ctx, tc, testSetID, r.logger, r.config.Test.APITimeout)
+	r.denoiseWindows.close(window)
+	if err != nil {
+		if ctx.Err() == nil {
+			utils.LogError(r.logger, err, "failed to replay the request to find the noise", zap.String("testcase", tc.Name))
+		}
+		return
+	}
+	noise, err := replay.NoisyFields(&tc, resp)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to find the noisy fields of the response", zap.String("testcase", tc.Name))
+		return
+	}
+	if len(noise) == 0 {
+		return
+	}
+
+	// the test cases are replayed concurrently, the updates of the test set config are serialized
+	r.denoiseMu.Lock()
+	defer r.denoiseMu.Unlock()
+
+	conf, err := r.testSetConf.Read(ctx, testSetID)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			utils.LogError(r.logger, err, "failed to read the test set config", zap.String("testset", testSetID))
+			return
+		}
+		conf = &models.TestSet{}
+	}
+	if conf == nil {
+		conf = &models.TestSet{}
+	}
+	replay.AddNoiseReview(conf, []models.NoiseParams{replay.ProposeNoise(tc.Name, noise)})
+	err = r.testSetConf.Write(ctx, testSetID, conf)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to write the proposed noise for review", zap.String("testset", testSetID))
+		return
+	}
+	r.logger.Info("proposed noise for review", zap.String("testcase", tc.Name), zap.String("testset", testSetID), zap.Strings("fields", utils.Keys(noise)))
+}
@@ -7,6 +7,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"time"
 
@@ -23,19 +24,29 @@ type Recorder struct {
 	logger          *zap.Logger
 	testDB          TestDB
 	mockDB          MockDB
+	testSetConf     TestSetConfig
 	telemetry       Telemetry
 	instrumentation Instrumentation
 	config          *config.Config
+	// denoiseMu serializes the updates of the noise review of the test set
+	denoiseMu sync.Mutex
+	// denoiseWindows are the windows of the requests replayed to find the noise, their mocks are dropped
+	denoiseWindows denoiseWindows
 }
 
-func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, telemetry Telemetry, instrumentation Instrumentation, config *config.Config) Service {
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, testSetConf TestSetConfig, telemetry Telemetry, instrumentation Instrumentation, config *config.Config) Service {
 	return &Recorder{
 		logger:          logger,
 		testDB:          testDB,
 		mockDB:          mockDB,
+		testSetConf:     testSetConf,
 		telemetry:       telemetry,
 		instrumentation: instrumentation,
 		config:          config,
+		denoiseWindows: denoiseWindows{
+			logger: logger,
+			settle: time.Duration(config.Test.APITimeout) * time.Second,
+		},
 	}
 }
 
@@ -110,6 +121,13 @@ func (r *Recorder) Start(ctx context.Context) error {
 
 	newTestSetID = pkg.NextID(testSetIDs, models.TestSetPattern)
 
+	redactor, err := newRedactor(r.config.Record.Redact)
+	if err != nil {
+		stopReason = "failed to parse the redaction rules"
+		utils.LogError(r.logger, err, stopReason)
+		return fmt.Errorf(stopReason)
+	}
+
 	// setting up the environment for recording
 	appID, err = r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay})
 	if err != nil {
@@ -152,6 +170,21 @@ func (r *Recorder) Start(ctx context.Context) error {
 
 	errGrp.Go(func() error {
 		for testCase := range incomingChan {
+			denoise := r.config.Record.Denoise && testCase.Kind == models.HTTP
+			if denoise && isDenoiseReplay(testCase) {
+				continue
+			}
+			if r.config.Record.Denoise {
+				r.denoiseWindows.recorded(testCase)
+			}
+			denoise = denoise && r.replaysToDenoise(testCase)
+			var unredacted models.TestCase
+			if denoise {
+				unredacted = cloneHTTPTestCase(testCase)
+			}
+			if redactor != nil {
+				redactor.redactTestCase(testCase)
+			}
 			err := r.testDB.InsertTestCase(ctx, testCase, newTestSetID)
 			if err != nil {
 				if err == context.Canceled {
@@ -162,6 +195,13 @@ func (r *Recorder) Start(ctx context.Context) error {
 
 				testCount++
 				r.telemetry.RecordedTestAndMocks()
+				if denoise {
+					unredacted.Name = testCase.Name
+					errGrp.Go(func() error {
+						r.denoiseTestCase(ctx, appID, newTestSetID, unredacted)
+						return nil
+					})
+				}
 			}
 		}
 		return nil
@@ -183,11 +223,14 @@ func (r *Recorder) Start(ctx context.Context) error {
 		return fmt.Errorf(stopReason)
 	}
 	errGrp.Go(func() error {
-		for mock := range outgoingChan {
+		insertMock := func(mock *models.Mock) {
+			if redactor != nil {
+				redactor.redactMock(mock)
+			}
 			err := r.mockDB.InsertMock(ctx, mock, newTestSetID)
 			if err != nil {
 				if err == context.Canceled {
-					continue
+					return
 				}
 				insertMockErrChan <- err
 			} else {
@@ -195,6 +238,23 @@ func (r *Recorder) Start(ctx context.Context) error {
 				r.telemetry.RecordedTestCaseMock(mock.GetKind())
 			}
 		}
+		for mock := range outgoingChan {
+			if r.config.Record.Denoise {
+				// the mocks recorded during the replays to find the noise are held until their windows settle
+				for _, kept := range r.denoiseWindows.release(false) {
+					insertMock(kept)
+				}
+				if r.denoiseWindows.hold(mock) {
+					continue
+				}
+			}
+			insertMock(mock)
+		}
+		if r.config.Record.Denoise {
+			for _, kept := range r.denoiseWindows.release(true) {
+				insertMock(kept)
+			}
+		}
 		return nil
 	})
 
@@ -291,6 +351,13 @@ func (r *Recorder) StartMock(ctx context.Context) error {
 	var outgoingChan <-chan *models.Mock
 	var insertMockErrChan = make(chan error)
 
+	redactor, err := newRedactor(r.config.Record.Redact)
+	if err != nil {
+		stopReason = "failed to parse the redaction rules"
+		utils.LogError(r.logger, err, stopReason)
+		return fmt.Errorf(stopReason)
+	}
+
 	appID, err := r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay})
 	if err != nil {
 		stopReason = "failed to exeute mock record due to error while setting up the environment"
@@ -316,6 +383,9 @@ func (r *Recorder) StartMock(ctx context.Context) error {
 	g.Go(func() error {
 		for mock := range outgoingChan {
 			mock := mock // capture range variable
+			if redactor != nil {
+				redactor.redactMock(mock)
+			}
 			g.Go(func() error {
 				err := r.mockDB.InsertMock(ctx, mock, "")
 				if err != nil {
@@ -362,7 +432,7 @@ func (r *Recorder) ReRecord(ctx context.Context, appID uint64) error {
 
 	allTestCasesRecorded := true
 	for _, tc := range tcs {
-		if utils.IsDockerKind(cmdType) {
+		if utils.IsDockerKind(cmdType) && tc.Kind == models.HTTP {
 
 			userIP, err := r.instrumentation.GetContainerIP(ctx, appID)
 			if err != nil {
@@ -378,6 +448,17 @@ func (r *Recorder) ReRecord(ctx context.Context, appID uint64) error {
 			r.logger.Debug("", zap.Any("replaced URL in case of docker env", tc.HTTPReq.URL))
 		}
 
+		if tc.Kind == models.GRPC_EXPORT {
+			resp, err := pkg.SimulateGRPC(ctx, *tc, r.config.Record.ReRecord, r.logger, r.config.Test.APITimeout)
+			if err != nil {
+				r.logger.Error("Failed to simulate gRPC request", zap.Error(err))
+				allTestCasesRecorded = false
+				continue
+			}
+			r.logger.Debug("Re-recorded testcases successfully", zap.String("testcase", tc.Name), zap.Any("response", resp))
+			continue
+		}
+
 		resp, err := pkg.SimulateHTTP(ctx, *tc, r.config.Record.ReRecord, r.logger, r.config.Test.APITimeout)
 		if err != nil {
 			r.logger.Error("Failed to simulate HTTP request", zap.Error(err))
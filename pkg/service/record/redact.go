@@ -0,0 +1,268 @@
+//go:build linux
+
+package record
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgproto3/v2"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// mysqlAuthFields are the fields of the MySQL handshake packets which carry the password of the user
+var mysqlAuthFields = []string{"AuthData", "AuthResponseData"}
+
+// redactor replaces the secrets in the captured testcases and mocks before they are stored. Every distinct
+// secret gets its own placeholder, so a value shared by a testcase and its mocks keeps the same placeholder.
+type redactor struct {
+	headers     map[string]bool
+	paths       [][]string
+	regexes     []*regexp.Regexp
+	dbPasswords bool
+
+	mu           sync.Mutex
+	placeholders map[string]string
+}
+
+// newRedactor returns nil when the redaction is disabled.
+func newRedactor(cfg config.Redact) (*redactor, error) {
+	if !cfg.Enable {
+		return nil, nil
+	}
+	r := &redactor{
+		headers:      map[string]bool{},
+		dbPasswords:  cfg.DBPasswords,
+		placeholders: map[string]string{},
+	}
+	for _, h := range cfg.Headers {
+		r.headers[strings.ToLower(h)] = true
+	}
+	for _, p := range cfg.JSONPaths {
+		r.paths = append(r.paths, strings.Split(strings.TrimPrefix(p, "body."), "."))
+	}
+	for _, expr := range cfg.Regexes {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction regex %q: %v", expr, err)
+		}
+		r.regexes = append(r.regexes, re)
+	}
+	return r, nil
+}
+
+func (r *redactor) placeholder(value string) string {
+	if value == "" || pkg.IsRedacted(value) {
+		return value
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.placeholders[value]
+	if !ok {
+		p = pkg.RedactedPlaceholder(len(r.placeholders) + 1)
+		r.placeholders[value] = p
+	}
+	return p
+}
+
+func (r *redactor) redactTestCase(tc *models.TestCase) {
+	if tc.Kind == models.HTTP {
+		r.redactHTTP(&tc.HTTPReq, &tc.HTTPResp)
+	}
+}
+
+func (r *redactor) redactMock(mock *models.Mock) {
+	switch mock.Kind {
+	case models.HTTP:
+		r.redactHTTP(mock.Spec.HTTPReq, mock.Spec.HTTPResp)
+	case models.Postgres:
+		if r.dbPasswords {
+			r.redactPostgres(mock.Spec.PostgresRequests)
+		}
+	case models.SQL:
+		if r.dbPasswords {
+			for i := range mock.Spec.MySQLRequests {
+				mock.Spec.MySQLRequests[i].Message = r.redactFields(mock.Spec.MySQLRequests[i].Message, mysqlAuthFields)
+			}
+		}
+	}
+}
+
+func (r *redactor) redactHTTP(req *models.HTTPReq, resp *models.HTTPResp) {
+	if req != nil {
+		req.URL = r.redactString(req.URL)
+		for k, v := range req.URLParams {
+			req.URLParams[k] = r.redactString(v)
+		}
+		r.redactHeaders(req.Header)
+		req.Body = r.redactBody(req.Body)
+	}
+	if resp != nil {
+		r.redactHeaders(resp.Header)
+		resp.Body = r.redactBody(resp.Body)
+	}
+}
+
+func (r *redactor) redactHeaders(header map[string]string) {
+	for k, v := range header {
+		if r.headers[strings.ToLower(k)] {
+			header[k] = r.placeholder(v)
+			continue
+		}
+		header[k] = r.redactString(v)
+	}
+}
+
+// redactBody replaces the values at the configured json paths and the matches of the regexes.
+func (r *redactor) redactBody(body string) string {
+	if len(r.paths) > 0 && json.Valid([]byte(body)) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(body), &data); err == nil {
+			redacted := false
+			for _, path := range r.paths {
+				var ok bool
+				data, ok = r.redactPath(data, path)
+				redacted = redacted || ok
+			}
+			if redacted {
+				if b, err := json.Marshal(data); err == nil {
+					body = string(b)
+				}
+			}
+		}
+	}
+	return r.redactString(body)
+}
+
+// redactPath replaces the value at the path in the json document, * matches any key or array index.
+func (r *redactor) redactPath(data interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		if data == nil {
+			return data, false
+		}
+		value, ok := data.(string)
+		if !ok {
+			b, err := json.Marshal(data)
+			if err != nil {
+				return data, false
+			}
+			value = string(b)
+		}
+		return r.placeholder(value), true
+	}
+	redacted := false
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if path[0] != "*" && !strings.EqualFold(path[0], k) {
+				continue
+			}
+			var ok bool
+			v[k], ok = r.redactPath(child, path[1:])
+			redacted = redacted || ok
+		}
+	case []interface{}:
+		for i, child := range v {
+			if path[0] != "*" && path[0] != fmt.Sprint(i) {
+				continue
+			}
+			var ok bool
+			v[i], ok = r.redactPath(child, path[1:])
+			redacted = redacted || ok
+		}
+	}
+	return data, redacted
+}
+
+func (r *redactor) redactString(s string) string {
+	for _, re := range r.regexes {
+		s = re.ReplaceAllStringFunc(s, r.placeholder)
+	}
+	return s
+}
+
+// redactPostgres replaces the password messages of the startup flow along with their raw payload.
+func (r *redactor) redactPostgres(requests []models.Backend) {
+	for i := range requests {
+		req := &requests[i]
+		if req.PasswordMessage.Password == "" {
+			continue
+		}
+		// the decoded password message is carried by the requests following the authentication as well
+		req.PasswordMessage.Password = r.placeholder(req.PasswordMessage.Password)
+		if !slices.Contains(req.PacketTypes, "p") {
+			continue
+		}
+		payload, err := base64.StdEncoding.DecodeString(req.Payload)
+		if err != nil {
+			req.Payload = ""
+			continue
+		}
+		req.Payload = base64.StdEncoding.EncodeToString(replacePasswordMessage(payload, &req.PasswordMessage))
+	}
+}
+
+// replacePasswordMessage rewrites the password messages ('p') present in the buffer of postgres messages.
+func replacePasswordMessage(buf []byte, msg *pgproto3.PasswordMessage) []byte {
+	var out []byte
+	for len(buf) >= 5 {
+		length := int(binary.BigEndian.Uint32(buf[1:5]))
+		if length < 4 || len(buf) < 1+length {
+			break
+		}
+		if buf[0] == 'p' {
+			out = msg.Encode(out)
+		} else {
+			out = append(out, buf[:1+length]...)
+		}
+		buf = buf[1+length:]
+	}
+	return append(out, buf...)
+}
+
+// redactFields replaces the string and byte slice fields with the given names of a protocol message.
+// Messages stored by value are copied as they cannot be changed in place.
+func (r *redactor) redactFields(msg interface{}, names []string) interface{} {
+	v := reflect.ValueOf(msg)
+	if !v.IsValid() {
+		return msg
+	}
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return msg
+		}
+		r.redactStruct(v.Elem(), names)
+		return msg
+	}
+	if v.Kind() != reflect.Struct {
+		return msg
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	r.redactStruct(c, names)
+	return c.Interface()
+}
+
+func (r *redactor) redactStruct(v reflect.Value, names []string) {
+	for _, name := range names {
+		f := v.FieldByName(name)
+		if !f.IsValid() || !f.CanSet() {
+			continue
+		}
+		switch {
+		case f.Kind() == reflect.String && f.String() != "":
+			f.SetString(r.placeholder(f.String()))
+		case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Uint8 && f.Len() > 0:
+			f.SetBytes([]byte(r.placeholder(string(f.Bytes()))))
+		}
+	}
+}
@@ -30,6 +30,11 @@ type TestDB interface {
 	GetTestCases(ctx context.Context, testID string) ([]*models.TestCase, error)
 }
 
+type TestSetConfig interface {
+	Read(ctx context.Context, testSetID string) (*models.TestSet, error)
+	Write(ctx context.Context, testSetID string, testSet *models.TestSet) error
+}
+
 type MockDB interface {
 	InsertMock(ctx context.Context, mock *models.Mock, testSetID string) error
 }
@@ -162,7 +162,7 @@ func CompareHTTPReq(tcs1, tcs2 *models.TestCase, _ models.GlobalNoise, ignoreOrd
 	reqHeaderNoise["Keploy-Test-Id"] = []string{}
 
 	// compare http req headers
-	ok := CompareHeaders(pkg.ToHTTPHeader(tcs1.HTTPReq.Header), pkg.ToHTTPHeader(tcs2.HTTPReq.Header), &reqCompare.HeaderResult, reqHeaderNoise)
+	ok := CompareHeaders(pkg.ToHTTPHeader(tcs1.HTTPReq.Header), pkg.ToHTTPHeader(tcs2.HTTPReq.Header), &reqCompare.HeaderResult, reqHeaderNoise, nil)
 	if !ok {
 		logger.Debug("test case http req headers are not equal", zap.Any("tcs1HttpReqHeaders", tcs1.HTTPReq.Header), zap.Any("tcs2HttpReqHeaders", tcs2.HTTPReq.Header))
 		pass = false
@@ -201,7 +201,7 @@ func CompareHTTPReq(tcs1, tcs2 *models.TestCase, _ models.GlobalNoise, ignoreOrd
 			return false, reqCompare
 		}
 		if validatedJSON.isIdentical {
-			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, reqBodyNoise, ignoreOrdering)
+			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, reqBodyNoise, nil, ignoreOrdering)
 			exact := jsonComparisonResult.isExact
 			if err != nil {
 				logger.Error("failed to compare json", zap.Error(err))
@@ -296,7 +296,7 @@ func CompareHTTPResp(tcs1, tcs2 *models.TestCase, noiseConfig models.GlobalNoise
 	}
 
 	// compare http resp headers
-	ok = CompareHeaders(pkg.ToHTTPHeader(tcs1.HTTPResp.Header), pkg.ToHTTPHeader(tcs2.HTTPResp.Header), &respCompare.HeadersResult, headerNoise)
+	ok = CompareHeaders(pkg.ToHTTPHeader(tcs1.HTTPResp.Header), pkg.ToHTTPHeader(tcs2.HTTPResp.Header), &respCompare.HeadersResult, headerNoise, nil)
 	if !ok {
 		logger.Debug("test case http resp headers are not equal", zap.Any("tcs1HttpRespHeaders", tcs1.HTTPResp.Header), zap.Any("tcs2HttpRespHeaders", tcs2.HTTPResp.Header))
 		pass = false
@@ -334,7 +334,7 @@ func CompareHTTPResp(tcs1, tcs2 *models.TestCase, noiseConfig models.GlobalNoise
 			return false, respCompare
 		}
 		if validatedJSON.isIdentical {
-			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering)
+			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, nil, ignoreOrdering)
 			exact := jsonComparisonResult.isExact
 			if err != nil {
 				logger.Error("failed to compare json", zap.Error(err))
@@ -428,7 +428,7 @@ func CompareCurl(curl1, curl2 string, logger *zap.Logger) bool {
 	curlHeaderNoise["Keploy-Test-Id"] = []string{}
 
 	hres := []models.HeaderResult{}
-	ok := CompareHeaders(pkg.ToHTTPHeader(headers1), pkg.ToHTTPHeader(headers2), &hres, curlHeaderNoise)
+	ok := CompareHeaders(pkg.ToHTTPHeader(headers1), pkg.ToHTTPHeader(headers2), &hres, curlHeaderNoise, nil)
 	if !ok {
 		logger.Debug("test case curl headers are not equal", zap.Any("curlHeaderResult", hres))
 		return false
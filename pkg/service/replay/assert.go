@@ -0,0 +1,281 @@
+//go:build linux
+
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// responseTimePath selects the time taken by the app to respond, in milliseconds
+const responseTimePath = "response_time"
+
+// assertResponse evaluates the assertions of the test case on the actual response, every check of an
+// assertion gets its own result.
+func assertResponse(assertions []models.Assertion, resp *models.HTTPResp, elapsed time.Duration) (bool, []models.AssertionResult) {
+	pass := true
+	var results []models.AssertionResult
+	for _, a := range assertions {
+		for _, res := range evaluateAssertion(a, resp, elapsed) {
+			pass = pass && res.Normal
+			results = append(results, res)
+		}
+	}
+	return pass, results
+}
+
+func evaluateAssertion(a models.Assertion, resp *models.HTTPResp, elapsed time.Duration) []models.AssertionResult {
+	var value interface{}
+	var found bool
+	if a.Path == responseTimePath {
+		value, found = json.Number(strconv.FormatInt(elapsed.Milliseconds(), 10)), true
+	} else {
+		value, found = responseField(resp, a.Path)
+	}
+	actual := "<missing>"
+	if found {
+		actual = fieldString(value)
+	}
+
+	var results []models.AssertionResult
+	check := func(name, expected string, normal bool) {
+		results = append(results, models.AssertionResult{Normal: normal, Path: a.Path, Check: name, Expected: expected, Actual: actual})
+	}
+
+	if a.Exists != nil {
+		check("exists", strconv.FormatBool(*a.Exists), found == *a.Exists)
+	}
+	if a.Equals != nil {
+		check("equals", fieldString(a.Equals), found && equalValues(a.Equals, value))
+	}
+	if a.Regex != "" {
+		re, err := regexp.Compile(a.Regex)
+		if err != nil {
+			check("regex", a.Regex, false)
+			results[len(results)-1].Actual = fmt.Sprintf("invalid regex: %v", err)
+		} else {
+			check("regex", a.Regex, found && re.MatchString(actual))
+		}
+	}
+	if a.Type != "" {
+		check("type", a.Type, found && jsonType(value) == a.Type)
+		if found {
+			results[len(results)-1].Actual = jsonType(value)
+		}
+	}
+	if a.Min != nil || a.Max != nil {
+		n, ok := numberValue(value)
+		ok = ok && found
+		if a.Min != nil {
+			check("min", strconv.FormatFloat(*a.Min, 'f', -1, 64), ok && n >= *a.Min)
+		}
+		if a.Max != nil {
+			check("max", strconv.FormatFloat(*a.Max, 'f', -1, 64), ok && n <= *a.Max)
+		}
+	}
+	if a.Length != nil {
+		l, ok := lengthOf(value)
+		check("length", strconv.Itoa(*a.Length), found && ok && l == *a.Length)
+		if found && ok {
+			results[len(results)-1].Actual = strconv.Itoa(l)
+		}
+	}
+	if len(results) == 0 {
+		// an assertion without any check only asserts that the value is present
+		check("exists", "true", found)
+	}
+	return results
+}
+
+// responseField returns the value at the path of the response: status, header.<name>, body, body.<path> or
+// a JSONPath into the json body. The values of the json body keep their json types, numbers as json.Number.
+func responseField(resp *models.HTTPResp, path string) (interface{}, bool) {
+	switch {
+	case path == "status":
+		return json.Number(strconv.Itoa(resp.StatusCode)), true
+	case path == "body":
+		return resp.Body, true
+	case strings.HasPrefix(path, "header."):
+		name := strings.TrimPrefix(path, "header.")
+		for k, v := range resp.Header {
+			if strings.EqualFold(k, name) {
+				return v, true
+			}
+		}
+		return nil, false
+	}
+	keys, ok := bodyPath(path)
+	if !ok {
+		return nil, false
+	}
+	var data interface{}
+	// numbers are kept as they are, the ids don't fit in a float64
+	dec := json.NewDecoder(strings.NewReader(resp.Body))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return nil, false
+	}
+	for _, key := range keys {
+		switch v := data.(type) {
+		case map[string]interface{}:
+			child, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			data = child
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			data = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return data, true
+}
+
+// bodyPath splits a path into the json body, written as body.items.0.id or as the JSONPath $.items[0].id,
+// into its keys.
+func bodyPath(path string) ([]string, bool) {
+	var rest string
+	switch {
+	case path == "$":
+		return nil, true
+	case strings.HasPrefix(path, "$"):
+		rest = path[1:]
+	case strings.HasPrefix(path, "body.") || strings.HasPrefix(path, "body["):
+		rest = strings.TrimPrefix(path, "body")
+	default:
+		return nil, false
+	}
+	var keys []string
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, false
+			}
+			keys = append(keys, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, false
+			}
+			keys = append(keys, strings.Trim(rest[1:end], `'"`))
+			rest = rest[end+1:]
+		default:
+			return nil, false
+		}
+	}
+	return keys, true
+}
+
+// fieldString returns the value as it is written in the response, strings without their quotes.
+func fieldString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// equalValues compares the expected value of the test case, as decoded from yaml, with the actual value.
+// Scalars are compared by their text when one of them is a string, as headers are always strings.
+func equalValues(expected, actual interface{}) bool {
+	exp, err := normalizeJSON(expected)
+	if err != nil {
+		return false
+	}
+	act, err := normalizeJSON(actual)
+	if err != nil {
+		return false
+	}
+	_, expString := exp.(string)
+	_, actString := act.(string)
+	if expString != actString && isScalar(exp) && isScalar(act) {
+		return fieldString(expected) == fieldString(actual)
+	}
+	return reflect.DeepEqual(exp, act)
+}
+
+func normalizeJSON(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case string, float64, bool:
+		return true
+	}
+	return false
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		n, err := v.Float64()
+		return n, err == nil
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func lengthOf(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return len(v), true
+	case map[string]interface{}:
+		return len(v), true
+	case string:
+		return utf8.RuneCountInString(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,460 @@
+//go:build linux
+
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"golang.org/x/net/html"
+)
+
+// bodyField is a value of a structured body along with its path: the XPath of an element or attribute of
+// xml and html documents, or the key of a form field.
+type bodyField struct {
+	path  string
+	value string
+}
+
+// detectBodyType returns the type of the response body, from its content or its content type.
+func detectBodyType(resp *models.HTTPResp) models.BodyType {
+	if json.Valid([]byte(resp.Body)) {
+		return models.BodyTypeJSON
+	}
+	mediaType, _, err := mime.ParseMediaType(headerValue(resp.Header, "Content-Type"))
+	if err != nil {
+		return models.BodyTypePlain
+	}
+	switch {
+	case mediaType == "text/html":
+		return models.BodyTypeHTML
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return models.BodyTypeXML
+	case mediaType == "application/x-www-form-urlencoded":
+		return models.BodyTypeForm
+	case strings.HasPrefix(mediaType, "multipart/"):
+		return models.BodyTypeMultipart
+	}
+	return models.BodyTypePlain
+}
+
+// isStructuredBody tells whether the body type is compared field by field.
+func isStructuredBody(bodyType models.BodyType) bool {
+	switch bodyType {
+	case models.BodyTypeXML, models.BodyTypeHTML, models.BodyTypeForm, models.BodyTypeMultipart:
+		return true
+	}
+	return false
+}
+
+func headerValue(header map[string]string, key string) string {
+	for k, v := range header {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// bodyFields parses the body of the response into its fields, in the order of the document.
+func bodyFields(bodyType models.BodyType, resp *models.HTTPResp) ([]bodyField, error) {
+	switch bodyType {
+	case models.BodyTypeXML:
+		root, err := parseXML(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return flattenDOM(root), nil
+	case models.BodyTypeHTML:
+		root, err := parseHTML(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return flattenDOM(root), nil
+	case models.BodyTypeForm:
+		return formFields(resp.Body)
+	case models.BodyTypeMultipart:
+		return multipartFields(resp.Header, resp.Body)
+	}
+	return nil, fmt.Errorf("body of type %s is not structured", bodyType)
+}
+
+// compareBodyFields compares the structured bodies field by field. The paths of the fields are looked up in
+// the noise like the keys of the json bodies, the ones of the xml and html documents may also be matched by
+// an XPath noise key like //envelope/header or /html/body/div[2]/@id. The fields with tolerances are looked up
+// in the same way and compared within them. In the schema only mode only the paths of the fields are compared.
+func compareBodyFields(expected, actual []bodyField, noise map[string][]string, tolerances map[string][]config.Tolerance, schemaOnly bool) bool {
+	expValues, paths := groupBodyFields(expected, nil)
+	actValues, paths := groupBodyFields(actual, paths)
+	for _, path := range paths {
+		exp, inExp := expValues[path]
+		act, inAct := actValues[path]
+		rules, isNoisy := fieldNoise(path, noise)
+		if isNoisy && len(rules) == 0 {
+			continue
+		}
+		fieldTols, tolerant := fieldNoise(path, tolerances)
+		if !inExp || !inAct {
+			return false
+		}
+		if schemaOnly {
+			continue
+		}
+		if len(exp) != len(act) {
+			return false
+		}
+		for i := range exp {
+			if exp[i] == act[i] || pkg.MatchesRedacted(exp[i], act[i]) {
+				continue
+			}
+			if isNoisy {
+				if ok, _ := MatchesAnyRegex(exp[i], rules); ok {
+					continue
+				}
+			}
+			if !tolerant || !withinTolerance(exp[i], act[i], fieldTols) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// groupBodyFields groups the values of the fields by path, the new paths are appended to paths.
+func groupBodyFields(fields []bodyField, paths []string) (map[string][]string, []string) {
+	values := map[string][]string{}
+	seen := map[string]bool{}
+	for _, p := range paths {
+		seen[p] = true
+	}
+	for _, f := range fields {
+		values[f.path] = append(values[f.path], f.value)
+		if !seen[f.path] {
+			seen[f.path] = true
+			paths = append(paths, f.path)
+		}
+	}
+	return values, paths
+}
+
+// fieldNoise returns the noise rules or the tolerances of the field. The keys are lower case, as the config
+// keys are.
+func fieldNoise[V any](path string, noise map[string]V) (V, bool) {
+	key := strings.ToLower(path)
+	if rules, ok := noise[key]; ok {
+		return rules, true
+	}
+	for k, rules := range noise {
+		var re *regexp.Regexp
+		var err error
+		if strings.HasPrefix(k, "/") {
+			re, err = xpathRegex(k)
+		} else {
+			re, err = regexp.Compile(k)
+		}
+		if err == nil && re.MatchString(key) {
+			return rules, true
+		}
+	}
+	var none V
+	return none, false
+}
+
+// xpathRegex converts the supported subset of XPath (absolute and // paths, * and positions) into a regex
+// matching the paths of the selected fields and of their descendants.
+func xpathRegex(xpath string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	steps := strings.Split(xpath, "/")
+	for i := 1; i < len(steps); i++ {
+		step := steps[i]
+		if step == "" {
+			// a // selects the descendants at any depth
+			sb.WriteString("(/[^/]+)*")
+			continue
+		}
+		sb.WriteString("/")
+		name, position, hasPosition := strings.Cut(step, "[")
+		if name == "*" {
+			sb.WriteString(`[^/\[]+`)
+		} else {
+			sb.WriteString(regexp.QuoteMeta(name))
+		}
+		if hasPosition {
+			sb.WriteString(regexp.QuoteMeta("[" + position))
+		} else if !strings.HasPrefix(name, "@") {
+			sb.WriteString(`(\[\d+\])?`)
+		}
+	}
+	sb.WriteString("(/.*)?$")
+	return regexp.Compile(sb.String())
+}
+
+// domNode is an element of a canonicalised xml or html document: namespace prefixes, comments and the
+// whitespace around the text are left out, and the attributes are sorted.
+type domNode struct {
+	name     string
+	attrs    []bodyField
+	text     string
+	children []*domNode
+}
+
+func parseXML(body string) (*domNode, error) {
+	dec := xml.NewDecoder(strings.NewReader(body))
+	var root *domNode
+	var stack []*domNode
+	var texts []*strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &domNode{name: t.Name.Local}
+			for _, a := range t.Attr {
+				// the namespace declarations only bind the prefixes, which are not compared
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				n.attrs = append(n.attrs, bodyField{path: "@" + a.Name.Local, value: a.Value})
+			}
+			sort.Slice(n.attrs, func(i, j int) bool { return n.attrs[i].path < n.attrs[j].path })
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root != nil {
+				return nil, errors.New("xml document has more than one root element")
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+			texts = append(texts, &strings.Builder{})
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return nil, errors.New("unexpected end element in the xml document")
+			}
+			stack[len(stack)-1].text = strings.TrimSpace(texts[len(texts)-1].String())
+			stack, texts = stack[:len(stack)-1], texts[:len(texts)-1]
+		case xml.CharData:
+			if len(texts) > 0 {
+				texts[len(texts)-1].Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("no element found in the xml document")
+	}
+	return root, nil
+}
+
+func parseHTML(body string) (*domNode, error) {
+	doc, err := html.Parse(strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	var convert func(n *html.Node) *domNode
+	convert = func(n *html.Node) *domNode {
+		d := &domNode{name: n.Data}
+		for _, a := range n.Attr {
+			d.attrs = append(d.attrs, bodyField{path: "@" + a.Key, value: strings.Join(strings.Fields(a.Val), " ")})
+		}
+		sort.Slice(d.attrs, func(i, j int) bool { return d.attrs[i].path < d.attrs[j].path })
+		var text []string
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			switch c.Type {
+			case html.ElementNode:
+				d.children = append(d.children, convert(c))
+			case html.TextNode:
+				if t := strings.Join(strings.Fields(c.Data), " "); t != "" {
+					text = append(text, t)
+				}
+			}
+		}
+		d.text = strings.Join(text, " ")
+		return d
+	}
+	for c := doc.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return convert(c), nil
+		}
+	}
+	return nil, errors.New("no element found in the html document")
+}
+
+// flattenDOM lists the attributes and the texts of the elements by their XPath. The position of an element
+// is only added when it has siblings of the same name.
+func flattenDOM(root *domNode) []bodyField {
+	var fields []bodyField
+	var walk func(n *domNode, path string)
+	walk = func(n *domNode, path string) {
+		for _, a := range n.attrs {
+			fields = append(fields, bodyField{path: path + "/" + a.path, value: a.value})
+		}
+		if n.text != "" || len(n.children) == 0 {
+			fields = append(fields, bodyField{path: path, value: n.text})
+		}
+		count := map[string]int{}
+		for _, c := range n.children {
+			count[c.name]++
+		}
+		position := map[string]int{}
+		for _, c := range n.children {
+			childPath := path + "/" + c.name
+			if count[c.name] > 1 {
+				position[c.name]++
+				childPath = fmt.Sprintf("%s[%d]", childPath, position[c.name])
+			}
+			walk(c, childPath)
+		}
+	}
+	walk(root, "/"+root.name)
+	return fields
+}
+
+func formFields(body string) ([]bodyField, error) {
+	var fields []bodyField
+	for _, pair := range strings.Split(body, "&") {
+		if pair == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, bodyField{path: key, value: value})
+	}
+	return fields, nil
+}
+
+// multipartFields lists the parts of the multipart body by their form name, the files along with their file
+// name and content type. Binary contents are compared by their digest.
+func multipartFields(header map[string]string, body string) ([]bodyField, error) {
+	_, params, err := mime.ParseMediaType(headerValue(header, "Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return nil, errors.New("no boundary found in the content type of the multipart body")
+	}
+	reader := multipart.NewReader(strings.NewReader(body), boundary)
+	var fields []bodyField
+	for i := 0; ; i++ {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		name := part.FormName()
+		if name == "" {
+			name = fmt.Sprintf("part[%d]", i+1)
+		}
+		value := string(content)
+		if !utf8.Valid(content) {
+			sum := sha256.Sum256(content)
+			value = "sha256:" + hex.EncodeToString(sum[:])
+		}
+		fields = append(fields, bodyField{path: name, value: value})
+		if fileName := part.FileName(); fileName != "" {
+			fields = append(fields, bodyField{path: name + ".filename", value: fileName})
+		}
+		if contentType := part.Header.Get("Content-Type"); contentType != "" {
+			fields = append(fields, bodyField{path: name + ".content-type", value: contentType})
+		}
+	}
+	return fields, nil
+}
+
+// withoutBoundary returns a copy of the header without the boundary parameter of the content type.
+func withoutBoundary(header map[string]string) map[string]string {
+	h := make(map[string]string, len(header))
+	for k, v := range header {
+		if strings.EqualFold(k, "Content-Type") {
+			if mediaType, params, err := mime.ParseMediaType(v); err == nil {
+				delete(params, "boundary")
+				v = mime.FormatMediaType(mediaType, params)
+			}
+		}
+		h[k] = v
+	}
+	return h
+}
+
+// bodyFieldLines renders the fields one per line, for the diff of the structured bodies.
+func bodyFieldLines(fields []bodyField) []string {
+	lines := make([]string, 0, len(fields))
+	for _, f := range fields {
+		lines = append(lines, f.path+" = "+f.value)
+	}
+	return lines
+}
+
+// sprintLinesDiff returns the diff table of the lines of the expected and the actual structured body, the
+// lines missing from the other side are marked and colored.
+func sprintLinesDiff(expect, actual []string, field string) string {
+	// longest common subsequence of the lines
+	lcs := make([][]int, len(expect)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expect) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expect[i] == actual[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	cE, cA := color.FgHiRed, color.FgHiGreen
+	var exp, act strings.Builder
+	i, j := 0, 0
+	for i < len(expect) || j < len(actual) {
+		switch {
+		case i < len(expect) && j < len(actual) && expect[i] == actual[j]:
+			exp.WriteString(breakWithColor("  "+expect[i], nil, 0))
+			act.WriteString(breakWithColor("  "+actual[j], nil, 0))
+			i++
+			j++
+		case j == len(actual) || (i < len(expect) && lcs[i+1][j] >= lcs[i][j+1]):
+			exp.WriteString(breakWithColor("- "+expect[i], &cE, 0))
+			i++
+		default:
+			act.WriteString(breakWithColor("+ "+actual[j], &cA, 0))
+			j++
+		}
+	}
+	return expectActualTable(exp.String(), act.String(), field, false)
+}
@@ -0,0 +1,56 @@
+//go:build linux
+
+package replay
+
+import (
+	"context"
+	"os"
+
+	"go.keploy.io/server/v2/pkg/service/coverage"
+	"go.keploy.io/server/v2/pkg/service/coverage/agent"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// coverageCollector returns the collector of the coverage of the test cases run on the app instance of the
+// worker, it is nil when the coverage of the test cases is not captured. It is captured for the native go
+// apps run with go coverage enabled which start the coverage agent, the coverage of the startup of the app is
+// left out.
+func (r *Replayer) coverageCollector(ctx context.Context, testSetID string, worker int) *coverage.Collector {
+	if !r.config.Test.GoCoverage || utils.CmdType(r.config.CommandType) != utils.Native || r.config.Test.BasePath != "" || r.coverageDB == nil {
+		return nil
+	}
+	// the address is exported to the app by the test command
+	base := os.Getenv(agent.AddrEnv)
+	if base == "" {
+		return nil
+	}
+	addr, err := agent.Addr(base, worker)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the address of the coverage agent of the app")
+		return nil
+	}
+	collector := coverage.NewCollector(r.logger, addr)
+	if err := collector.Reset(ctx); err != nil {
+		r.logger.Warn("failed to capture the coverage of the test cases, only the coverage of the test run is reported", zap.String("testset id", testSetID), zap.Error(err))
+		return nil
+	}
+	return collector
+}
+
+// captureCoverage stores the code covered by the test case, it returns false when the coverage of the
+// following test cases can't be captured either.
+func (r *Replayer) captureCoverage(ctx context.Context, collector *coverage.Collector, testRunID, testSetID, testCaseID string) bool {
+	cov, err := collector.Snapshot(ctx, testSetID, testCaseID)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.logger.Warn("failed to capture the coverage of the test case, the coverage of the remaining test cases of the test set is not captured", zap.String("testcase id", testCaseID), zap.String("testset id", testSetID), zap.Error(err))
+		}
+		return false
+	}
+	err = r.coverageDB.InsertCoverage(ctx, testRunID, testSetID, cov)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to store the coverage of the test case", zap.String("testcase id", testCaseID), zap.String("testset id", testSetID))
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+//go:build linux
+
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// DenoiseEditor is the EditedBy of the noise proposed by replaying the test cases twice
+const DenoiseEditor = "denoise"
+
+// NoisyFields compares the recorded http response of the test case with the one of a second run of the
+// same request, and returns the fields which differ as noise, e.g. header.date or body.data.id. A field
+// which is only in one of the responses is noisy too. The fields which are already noisy are left out.
+func NoisyFields(tc *models.TestCase, actual *models.HTTPResp) (map[string][]string, error) {
+	expFields, err := FlattenHTTPResponse(pkg.ToHTTPHeader(tc.HTTPResp.Header), tc.HTTPResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	actFields, err := FlattenHTTPResponse(pkg.ToHTTPHeader(actual.Header), actual.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	noise := map[string][]string{}
+	for field, expected := range expFields {
+		if value, ok := actFields[field]; !ok || !reflect.DeepEqual(expected, value) {
+			noise[strings.ToLower(field)] = []string{}
+		}
+	}
+	for field := range actFields {
+		if _, ok := expFields[field]; !ok {
+			noise[strings.ToLower(field)] = []string{}
+		}
+	}
+	for field := range noise {
+		if isNoisy(field, tc.Noise) {
+			delete(noise, field)
+		}
+	}
+	return noise, nil
+}
+
+// isNoisy tells whether the field or one of its parents, like body for body.id, is in the noise.
+func isNoisy(field string, noise map[string][]string) bool {
+	for k := range noise {
+		k = strings.ToLower(k)
+		if field == k || strings.HasPrefix(field, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// ProposeNoise returns the noise found for the test case, to be added to it with DenoiseTestCases.
+func ProposeNoise(testCaseID string, noise map[string][]string) models.NoiseParams {
+	return models.NoiseParams{
+		TestCaseIDs: testCaseID,
+		EditedBy:    DenoiseEditor,
+		Assertion:   noise,
+		Ops:         models.OpsAdd,
+	}
+}
+
+// AddNoiseReview adds the proposed noise to the review of the test set config. The noise proposed again for a
+// test case is merged with its pending proposal.
+func AddNoiseReview(conf *models.TestSet, proposals []models.NoiseParams) {
+	for _, proposal := range proposals {
+		merged := false
+		for i, pending := range conf.Denoise {
+			if pending.TestCaseIDs == proposal.TestCaseIDs && pending.Ops == proposal.Ops {
+				if pending.Assertion == nil {
+					conf.Denoise[i].Assertion = map[string][]string{}
+				}
+				for field, values := range proposal.Assertion {
+					if _, ok := pending.Assertion[field]; !ok {
+						conf.Denoise[i].Assertion[field] = values
+					}
+				}
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			conf.Denoise = append(conf.Denoise, proposal)
+		}
+	}
+}
+
+// Denoise replays the test cases and proposes the fields of the responses which differ from the recorded
+// ones as noise. The proposals are written to the config of the test sets for review, and added to the test
+// cases when the apply option is set. The replay is not a test run, no report is written for it.
+func (r *Replayer) Denoise(ctx context.Context) error {
+	if r.config.Denoise.Apply {
+		return r.applyNoiseReview(ctx)
+	}
+	r.denoise = true
+	err := r.Start(ctx)
+	if err != nil {
+		return err
+	}
+	r.logger.Info("the fields of the responses which differ from the recorded ones are proposed as noise in the denoise section of the test set configs, review them and run keploy denoise --apply to add them to the test cases")
+	return nil
+}
+
+// addNoiseReview writes the proposed noise to the config of the test set, the config is created when the
+// test set doesn't have one.
+func (r *Replayer) addNoiseReview(ctx context.Context, testSetID string, proposals []models.NoiseParams) error {
+	if len(proposals) == 0 {
+		return nil
+	}
+	conf, err := r.testSetConf.Read(ctx, testSetID)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read the test set config: %w", err)
+		}
+		conf = &models.TestSet{}
+	}
+	if conf == nil {
+		conf = &models.TestSet{}
+	}
+	AddNoiseReview(conf, proposals)
+	err = r.testSetConf.Write(ctx, testSetID, conf)
+	if err != nil {
+		return fmt.Errorf("failed to write the noise review: %w", err)
+	}
+	for _, proposal := range proposals {
+		fields := utils.Keys(proposal.Assertion)
+		sort.Strings(fields)
+		r.logger.Info("proposed the fields which differ from the recorded response as noise for review", zap.String("testcase id", proposal.TestCaseIDs), zap.String("testset id", testSetID), zap.Strings("fields", fields))
+	}
+	return nil
+}
+
+// applyNoiseReview adds the reviewed noise of the test sets to their test cases and clears the review.
+func (r *Replayer) applyNoiseReview(ctx context.Context) error {
+	testSetIDs, err := r.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		return fmt.Errorf("failed to get all test set ids: %w", err)
+	}
+
+	applied := 0
+	for _, testSetID := range testSetIDs {
+		if _, ok := r.config.Test.SelectedTests[testSetID]; !ok && len(r.config.Test.SelectedTests) != 0 {
+			continue
+		}
+		conf, err := r.testSetConf.Read(ctx, testSetID)
+		if err != nil || conf == nil || len(conf.Denoise) == 0 {
+			continue
+		}
+		err = r.DenoiseTestCases(ctx, testSetID, conf.Denoise)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to add the reviewed noise to the test cases", zap.String("testset id", testSetID))
+			return err
+		}
+		applied += len(conf.Denoise)
+		conf.Denoise = nil
+		err = r.testSetConf.Write(ctx, testSetID, conf)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to clear the applied noise review", zap.String("testset id", testSetID))
+			return err
+		}
+	}
+	if applied == 0 {
+		r.logger.Info("no noise is pending review, run keploy denoise to propose it")
+		return nil
+	}
+	r.logger.Info("added the reviewed noise to the test cases", zap.Int("test cases", applied))
+	return nil
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package replay
+
+import (
+	"strconv"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// depResults checks the outgoing calls of a test case against its mocks. The filtered mocks recorded along
+// with the test case are expected to be consumed by its replay, and the calls which couldn't be served from
+// the mocks are unexpected, they are reported with the closest mock as expected and their payload as actual.
+func depResults(expected []*models.Mock, consumed []string, unmatched []models.UnmatchedCall) []models.DepResult {
+	consumedMocks := ArrayToMap(consumed)
+	var results []models.DepResult
+	for _, mock := range expected {
+		ok := consumedMocks[mock.Name]
+		results = append(results, models.DepResult{
+			Name: mock.Name,
+			Type: string(mock.Kind),
+			Meta: []models.DepMetaResult{{
+				Normal:   ok,
+				Key:      models.DepConsumed,
+				Expected: "true",
+				Actual:   strconv.FormatBool(ok),
+			}},
+		})
+	}
+	for _, call := range unmatched {
+		results = append(results, models.DepResult{
+			Name: call.Destination,
+			Type: call.Kind,
+			Meta: []models.DepMetaResult{{
+				Normal:   false,
+				Key:      models.DepUnmatched,
+				Expected: call.ClosestMock,
+				Actual:   call.Payload,
+			}},
+		})
+	}
+	return results
+}
+
+// depStrictness returns the strictness of the outgoing calls check, the strict mocks mode fails the test cases
+// making unmocked calls whatever the configured strictness.
+func depStrictness(conf config.Test) string {
+	if conf.StrictMocks && conf.DepStrictness != config.DepStrictnessAll {
+		return config.DepStrictnessUnexpected
+	}
+	return conf.DepStrictness
+}
+
+// depsPass tells whether the outgoing calls of a test case pass under the strictness: the unexpected calls
+// fail the test case from the unexpected strictness, and the unconsumed mocks with the all strictness.
+func depsPass(results []models.DepResult, strictness string) bool {
+	for _, result := range results {
+		for _, meta := range result.Meta {
+			if meta.Normal {
+				continue
+			}
+			switch {
+			case meta.Key == models.DepUnmatched && (strictness == config.DepStrictnessUnexpected || strictness == config.DepStrictnessAll):
+				return false
+			case meta.Key == models.DepConsumed && strictness == config.DepStrictnessAll:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// depDiffs returns the names of the mocks which weren't consumed and the destinations of the unexpected calls.
+func depDiffs(results []models.DepResult) (unconsumed, unexpected []string) {
+	for _, result := range results {
+		for _, meta := range result.Meta {
+			if meta.Normal {
+				continue
+			}
+			if meta.Key == models.DepUnmatched {
+				unexpected = append(unexpected, result.Type+" "+result.Name)
+			} else {
+				unconsumed = append(unconsumed, result.Name)
+			}
+		}
+	}
+	return unconsumed, unexpected
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+package replay
+
+import (
+	"reflect"
+	"testing"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+func TestDepResults(t *testing.T) {
+	expected := []*models.Mock{
+		{Name: "mock-0", Kind: models.HTTP},
+		{Name: "mock-1", Kind: models.Mongo},
+	}
+	unmatched := []models.UnmatchedCall{
+		{Kind: "postgres", Destination: "10.0.0.1:5432", Payload: "select 1", ClosestMock: "mock-2"},
+	}
+	want := []models.DepResult{
+		{Name: "mock-0", Type: string(models.HTTP), Meta: []models.DepMetaResult{{Normal: true, Key: models.DepConsumed, Expected: "true", Actual: "true"}}},
+		{Name: "mock-1", Type: string(models.Mongo), Meta: []models.DepMetaResult{{Normal: false, Key: models.DepConsumed, Expected: "true", Actual: "false"}}},
+		{Name: "10.0.0.1:5432", Type: "postgres", Meta: []models.DepMetaResult{{Normal: false, Key: models.DepUnmatched, Expected: "mock-2", Actual: "select 1"}}},
+	}
+	// the consumed mocks of the other test cases are ignored
+	got := depResults(expected, []string{"mock-0", "mock-9"}, unmatched)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("depResults() = %+v, want %+v", got, want)
+	}
+	if got := depResults(nil, []string{"mock-0"}, nil); len(got) != 0 {
+		t.Errorf("depResults() without mocks nor calls = %+v, want none", got)
+	}
+}
+
+func TestDepsPass(t *testing.T) {
+	consumed := models.DepResult{Name: "mock-0", Meta: []models.DepMetaResult{{Normal: true, Key: models.DepConsumed}}}
+	unconsumed := models.DepResult{Name: "mock-1", Meta: []models.DepMetaResult{{Normal: false, Key: models.DepConsumed}}}
+	unexpected := models.DepResult{Name: "10.0.0.1:5432", Meta: []models.DepMetaResult{{Normal: false, Key: models.DepUnmatched}}}
+
+	tests := []struct {
+		name       string
+		results    []models.DepResult
+		strictness string
+		want       bool
+	}{
+		{"no calls", nil, config.DepStrictnessAll, true},
+		{"all consumed", []models.DepResult{consumed}, config.DepStrictnessAll, true},
+		{"unconsumed with none", []models.DepResult{unconsumed}, config.DepStrictnessNone, true},
+		{"unconsumed with unexpected", []models.DepResult{unconsumed}, config.DepStrictnessUnexpected, true},
+		{"unconsumed with all", []models.DepResult{consumed, unconsumed}, config.DepStrictnessAll, false},
+		{"unexpected with none", []models.DepResult{unexpected}, config.DepStrictnessNone, true},
+		{"unexpected with unexpected", []models.DepResult{consumed, unexpected}, config.DepStrictnessUnexpected, false},
+		{"unexpected with all", []models.DepResult{unexpected}, config.DepStrictnessAll, false},
+		{"unknown strictness", []models.DepResult{unconsumed, unexpected}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := depsPass(tt.results, tt.strictness); got != tt.want {
+				t.Errorf("depsPass(%q) = %v, want %v", tt.strictness, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDepStrictness(t *testing.T) {
+	tests := []struct {
+		name string
+		conf config.Test
+		want string
+	}{
+		{"configured", config.Test{DepStrictness: config.DepStrictnessNone}, config.DepStrictnessNone},
+		{"strict mocks", config.Test{DepStrictness: config.DepStrictnessNone, StrictMocks: true}, config.DepStrictnessUnexpected},
+		{"strict mocks keep all", config.Test{DepStrictness: config.DepStrictnessAll, StrictMocks: true}, config.DepStrictnessAll},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := depStrictness(tt.conf); got != tt.want {
+				t.Errorf("depStrictness() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
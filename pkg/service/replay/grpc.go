@@ -0,0 +1,173 @@
+//go:build linux
+
+package replay
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// grpcStatusTrailer carries the status code of a grpc call
+const grpcStatusTrailer = "grpc-status"
+
+// matchGrpc compares the actual grpc response with the one of the test case: the grpc-status, the ordinary
+// headers and trailers, and the message field by field. The header noise applies to both the headers and
+// the trailers. The fields of the message are selected in the body noise by their field numbers, e.g.
+// body.3.1 for the field 1 of the message in the field 3, the noise of a message covers all its fields.
+func matchGrpc(tc *models.TestCase, actualResponse *models.GrpcResp, noiseConfig map[string]map[string][]string, tolerances config.GlobalTolerances, logger *zap.Logger) (bool, *models.Result) {
+	pass := true
+	hRes := &[]models.HeaderResult{}
+
+	expStatus, actStatus := grpcStatus(tc.GrpcResp), grpcStatus(*actualResponse)
+	res := &models.Result{
+		StatusCode: models.IntResult{
+			Normal:   expStatus == actStatus,
+			Expected: expStatus,
+			Actual:   actStatus,
+		},
+		BodyResult: []models.BodyResult{{
+			Normal:   false,
+			Type:     models.BodyTypeProtoscope,
+			Expected: tc.GrpcResp.Body.DecodedData,
+			Actual:   actualResponse.Body.DecodedData,
+		}},
+	}
+	bodyNoise, headerNoise := splitNoise(noiseConfig, tc.Noise)
+
+	var expFields, actFields []bodyField
+	if !Contains(MapToArray(tc.Noise), "body") && tc.GrpcResp.Body.DecodedData != actualResponse.Body.DecodedData {
+		expFields = protoscopeFields(tc.GrpcResp.Body.DecodedData)
+		actFields = protoscopeFields(actualResponse.Body.DecodedData)
+		fieldsNoise := map[string][]string{}
+		for k, v := range bodyNoise {
+			fieldsNoise["^"+regexp.QuoteMeta(k)+`(\..+)?$`] = v
+		}
+		fieldsTolerances := map[string][]config.Tolerance{}
+		for k, v := range tolerances["body"] {
+			fieldsTolerances["^"+regexp.QuoteMeta(k)+`(\..+)?$`] = v
+		}
+		pass = compareBodyFields(expFields, actFields, fieldsNoise, fieldsTolerances, false)
+	}
+	res.BodyResult[0].Normal = pass
+
+	if !CompareHeaders(grpcHeader(tc.GrpcResp.Headers), grpcHeader(actualResponse.Headers), hRes, headerNoise, tolerances["header"]) {
+		pass = false
+	}
+	if !CompareHeaders(grpcHeader(tc.GrpcResp.Trailers), grpcHeader(actualResponse.Trailers), hRes, headerNoise, tolerances["header"]) {
+		pass = false
+	}
+	res.HeadersResult = *hRes
+	if !res.StatusCode.Normal {
+		pass = false
+	}
+
+	newLogger := pp.New()
+	newLogger.WithLineInfo = false
+	if pass {
+		newLogger.SetColorScheme(models.PassingColorScheme)
+		_, err := newLogger.Printf(newLogger.Sprintf("Testrun passed for testcase with id: %s\n\n--------------------------------------------------------------------\n\n", tc.Name))
+		if err != nil {
+			utils.LogError(logger, err, "failed to print the logs")
+		}
+		return pass, res
+	}
+
+	newLogger.SetColorScheme(models.FailingColorScheme)
+	_, err := newLogger.Printf(newLogger.Sprintf("Testrun failed for testcase with id: %s\n\n--------------------------------------------------------------------\n\n", tc.Name))
+	if err != nil {
+		utils.LogError(logger, err, "failed to print the logs")
+	}
+
+	logDiffs := NewDiffsPrinter(tc.Name)
+	if !res.StatusCode.Normal {
+		logDiffs.PushStatusDiff(strconv.Itoa(expStatus), strconv.Itoa(actStatus))
+	}
+	for _, h := range res.HeadersResult {
+		if !h.Normal {
+			logDiffs.PushHeaderDiff(fmt.Sprint(h.Expected.Value), fmt.Sprint(h.Actual.Value), h.Expected.Key, headerNoise)
+		}
+	}
+	if !res.BodyResult[0].Normal {
+		logDiffs.PushBodyLinesDiff(bodyFieldLines(expFields), bodyFieldLines(actFields), models.BodyTypeProtoscope)
+	}
+	err = logDiffs.Render()
+	if err != nil {
+		utils.LogError(logger, err, "failed to render the diffs")
+	}
+	return pass, res
+}
+
+// grpcStatus returns the status code of the grpc response, a missing grpc-status is the OK status.
+func grpcStatus(resp models.GrpcResp) int {
+	status, ok := resp.Trailers.OrdinaryHeaders[grpcStatusTrailer]
+	if !ok {
+		// the status of a failed call can be sent along with the headers
+		status = resp.Headers.OrdinaryHeaders[grpcStatusTrailer]
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(status))
+	if err != nil {
+		return 0
+	}
+	return code
+}
+
+// grpcHeader returns the ordinary headers as an http header, without the grpc-status which is compared as
+// the status code. The pseudo headers are left out, the :status of a grpc response is always 200.
+func grpcHeader(h models.GrpcHeaders) map[string][]string {
+	header := map[string]string{}
+	for k, v := range h.OrdinaryHeaders {
+		if k != grpcStatusTrailer {
+			header[k] = v
+		}
+	}
+	return pkg.ToHTTPHeader(header)
+}
+
+// protoscopeFields parses the protoscope text of a grpc message into its fields. The path of a field is made
+// of its field number, preceded by the ones of the enclosing messages, e.g. 3.1.
+func protoscopeFields(text string) []bodyField {
+	var fields []bodyField
+	var messages []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if line == "}" {
+			if len(messages) > 0 {
+				messages = messages[:len(messages)-1]
+			}
+			continue
+		}
+		number, value, ok := strings.Cut(line, ":")
+		if !ok || strings.ContainsAny(number, " \"{") {
+			// the lines of a long value belong to the enclosing field
+			fields = append(fields, bodyField{path: strings.Join(messages, "."), value: line})
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "{" {
+			messages = append(messages, number)
+			continue
+		}
+		// protoscope comments the raw encoding of some values, like the ones of the floats
+		if i := strings.Index(value, "  #"); i >= 0 && !strings.HasPrefix(value, `{"`) {
+			value = value[:i]
+		}
+		path := number
+		if len(messages) > 0 {
+			path = strings.Join(messages, ".") + "." + number
+		}
+		fields = append(fields, bodyField{path: path, value: value})
+	}
+	return fields
+}
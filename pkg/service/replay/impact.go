@@ -0,0 +1,86 @@
+//go:build linux
+
+package replay
+
+import (
+	"context"
+	"errors"
+
+	"go.keploy.io/server/v2/pkg/service/coverage"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// selectAffectedTests narrows the selected tests down to the test cases covering the code changed since the
+// git ref of --changed-since, along with the test cases whose coverage is unknown. It returns false when no
+// test case is to run.
+func (r *Replayer) selectAffectedTests(ctx context.Context, testSetIDs []string) (bool, error) {
+	ref := r.config.Test.ChangedSince
+	if r.coverageDB == nil {
+		r.logger.Warn("the coverage of the test cases is not available, running all the selected test cases", zap.String("changed since", ref))
+		return true, nil
+	}
+	impact, err := coverage.New(r.logger, r.coverageDB).Impact(ctx, ref, r.config.Test.ChangedSinceIgnore)
+	if errors.Is(err, coverage.ErrNoCoverage) {
+		r.logger.Warn("no coverage of the test cases found, running all the selected test cases. Capture it by running the test cases with --goCoverage", zap.String("changed since", ref))
+		return true, nil
+	}
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to find the test cases affected by the changes", zap.String("changed since", ref))
+		return false, err
+	}
+	if len(impact.Stale) > 0 {
+		r.logger.Warn("the coverage of these test cases was captured on another commit or with uncommitted changes, they are run as it may not match the code of the ref. Capture it again with --goCoverage on a clean checkout of the ref", zap.String("changed since", ref), zap.Strings("test cases", impact.Stale))
+	}
+	if impact.RunAll != "" {
+		r.logger.Info("running all the selected test cases", zap.String("reason", impact.RunAll), zap.String("changed since", ref))
+		return true, nil
+	}
+	if len(impact.Ignored) > 0 {
+		r.logger.Info("the changes of these files are not mapped to the test cases", zap.Strings("files", impact.Ignored))
+	}
+
+	userSelected := r.config.Test.SelectedTests
+	selected := map[string][]string{}
+	testCasesCount := 0
+	for _, testSetID := range testSetIDs {
+		userTestCases, ok := userSelected[testSetID]
+		if !ok && len(userSelected) != 0 {
+			continue
+		}
+		if len(impact.Covered[testSetID]) == 0 {
+			// the coverage of the test set was never captured, it is run as it is
+			selected[testSetID] = userTestCases
+			r.logger.Info("no coverage of the test set found, running all its selected test cases", zap.String("testset id", testSetID))
+			continue
+		}
+		userTests := ArrayToMap(userTestCases)
+		affected := ArrayToMap(impact.Affected[testSetID])
+		testCases, err := r.testDB.GetTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the test cases", zap.String("testset id", testSetID))
+			return false, err
+		}
+		var testCaseIDs []string
+		for _, tc := range testCases {
+			if len(userTests) != 0 && !userTests[tc.Name] {
+				continue
+			}
+			if affected[tc.Name] || !impact.Covered[testSetID][tc.Name] {
+				testCaseIDs = append(testCaseIDs, tc.Name)
+			}
+		}
+		if len(testCaseIDs) == 0 {
+			continue
+		}
+		selected[testSetID] = testCaseIDs
+		testCasesCount += len(testCaseIDs)
+	}
+
+	if len(selected) == 0 {
+		return false, nil
+	}
+	r.config.Test.SelectedTests = selected
+	r.logger.Info("running the test cases affected by the changes", zap.String("changed since", ref), zap.Strings("changed files", impact.Changed), zap.Int("test sets", len(selected)), zap.Int("affected test cases", testCasesCount))
+	return true, nil
+}
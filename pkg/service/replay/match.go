@@ -22,8 +22,7 @@ import (
 	"github.com/k0kubun/pp/v3"
 	"github.com/olekukonko/tablewriter"
 	"github.com/wI2L/jsondiff"
-	"github.com/yudai/gojsondiff"
-	"github.com/yudai/gojsondiff/formatter"
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -41,11 +40,10 @@ type JSONComparisonResult struct {
 	differences []string // Lists the keys or indices of values that are not the same
 }
 
-func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map[string]map[string][]string, ignoreOrdering bool, logger *zap.Logger) (bool, *models.Result) {
-	bodyType := models.BodyTypePlain
-	if json.Valid([]byte(actualResponse.Body)) {
-		bodyType = models.BodyTypeJSON
-	}
+// match compares the response with the recorded one of the test case and prints the verdict, the test case fails
+// as well when one of the results of its assertions failed.
+func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map[string]map[string][]string, tolerances config.GlobalTolerances, assertionResults []models.AssertionResult, ignoreOrdering, schemaOnly bool, logger *zap.Logger) (bool, *models.Result) {
+	bodyType := detectBodyType(actualResponse)
 	pass := true
 	hRes := &[]models.HeaderResult{}
 
@@ -61,34 +59,16 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 			Expected: tc.HTTPResp.Body,
 			Actual:   actualResponse.Body,
 		}},
+		AssertionResult: assertionResults,
 	}
 	noise := tc.Noise
-
-	var (
-		bodyNoise   = noiseConfig["body"]
-		headerNoise = noiseConfig["header"]
-	)
-
-	if bodyNoise == nil {
-		bodyNoise = map[string][]string{}
-	}
-	if headerNoise == nil {
-		headerNoise = map[string][]string{}
-	}
-
-	for field, regexArr := range noise {
-		a := strings.Split(field, ".")
-		if len(a) > 1 && a[0] == "body" {
-			x := strings.Join(a[1:], ".")
-			bodyNoise[x] = regexArr
-		} else if a[0] == "header" {
-			headerNoise[a[len(a)-1]] = regexArr
-		}
-	}
+	bodyNoise, headerNoise := splitNoise(noiseConfig, noise)
 
 	// stores the json body after removing the noise
 	cleanExp, cleanAct := tc.HTTPResp.Body, actualResponse.Body
 	var jsonComparisonResult JSONComparisonResult
+	// the fields of the xml, html, form and multipart bodies
+	var expFields, actFields []bodyField
 	if !Contains(MapToArray(noise), "body") && bodyType == models.BodyTypeJSON {
 		//validate the stored json
 		validatedJSON, err := ValidateAndMarshalJSON(logger, &cleanExp, &cleanAct)
@@ -96,7 +76,11 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 			return false, res
 		}
 		if validatedJSON.isIdentical {
-			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering)
+			if schemaOnly {
+				jsonComparisonResult, err = JSONSchemaDiffWithNoiseControl(validatedJSON, bodyNoise)
+			} else {
+				jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, tolerances["body"], ignoreOrdering)
+			}
 			pass = jsonComparisonResult.isExact
 			if err != nil {
 				return false, res
@@ -108,15 +92,32 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 		// debug log for cleanExp and cleanAct
 		logger.Debug("cleanExp", zap.Any("", cleanExp))
 		logger.Debug("cleanAct", zap.Any("", cleanAct))
+	} else if !Contains(MapToArray(noise), "body") && isStructuredBody(bodyType) {
+		var expErr, actErr error
+		expFields, expErr = bodyFields(bodyType, &tc.HTTPResp)
+		actFields, actErr = bodyFields(bodyType, actualResponse)
+		if expErr != nil || actErr != nil {
+			// the bodies which can't be parsed are compared as they are
+			logger.Debug("failed to parse the response bodies, comparing them as text", zap.Any("type", bodyType), zap.Error(errors.Join(expErr, actErr)))
+			expFields, actFields = nil, nil
+			pass = tc.HTTPResp.Body == actualResponse.Body || pkg.MatchesRedacted(tc.HTTPResp.Body, actualResponse.Body)
+		} else {
+			pass = compareBodyFields(expFields, actFields, bodyNoise, tolerances["body"], schemaOnly)
+		}
 	} else {
-		if !Contains(MapToArray(noise), "body") && tc.HTTPResp.Body != actualResponse.Body {
+		if !Contains(MapToArray(noise), "body") && tc.HTTPResp.Body != actualResponse.Body && !pkg.MatchesRedacted(tc.HTTPResp.Body, actualResponse.Body) {
 			pass = false
 		}
 	}
 
 	res.BodyResult[0].Normal = pass
 
-	if !CompareHeaders(pkg.ToHTTPHeader(tc.HTTPResp.Header), pkg.ToHTTPHeader(actualResponse.Header), hRes, headerNoise) {
+	expHeader, actHeader := tc.HTTPResp.Header, actualResponse.Header
+	if bodyType == models.BodyTypeMultipart {
+		// the boundaries of the multipart bodies are random, they are not compared
+		expHeader, actHeader = withoutBoundary(expHeader), withoutBoundary(actHeader)
+	}
+	if !CompareHeaders(pkg.ToHTTPHeader(expHeader), pkg.ToHTTPHeader(actHeader), hRes, headerNoise, tolerances["header"]) {
 
 		pass = false
 	}
@@ -129,6 +130,12 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 		pass = false
 	}
 
+	for _, a := range assertionResults {
+		if !a.Normal {
+			pass = false
+		}
+	}
+
 	if !pass {
 		logDiffs := NewDiffsPrinter(tc.Name)
 
@@ -165,8 +172,15 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 		}
 
 		if !res.BodyResult[0].Normal {
-			if json.Valid([]byte(actualResponse.Body)) {
-				patch, err := jsondiff.Compare(tc.HTTPResp.Body, actualResponse.Body)
+			expBody, actBody := tc.HTTPResp.Body, actualResponse.Body
+			if schemaOnly && bodyType == models.BodyTypeJSON {
+				// the values are not compared in the schema only mode, the diff shows the mismatched types
+				expBody, actBody = jsonSchemaString(logger, expBody), jsonSchemaString(logger, actBody)
+			}
+			if expFields != nil || actFields != nil {
+				logDiffs.PushBodyLinesDiff(bodyFieldLines(expFields), bodyFieldLines(actFields), bodyType)
+			} else if json.Valid([]byte(actualResponse.Body)) {
+				patch, err := jsondiff.Compare(expBody, actBody)
 				if err != nil {
 					logger.Warn("failed to compute json diff", zap.Error(err))
 				}
@@ -191,6 +205,11 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 		if err != nil {
 			utils.LogError(logger, err, "failed to render the diffs")
 		}
+		for _, a := range assertionResults {
+			if !a.Normal {
+				logger.Info("assertion failed", zap.String("testcase", tc.Name), zap.String("path", a.Path), zap.String("check", a.Check), zap.String("expected", a.Expected), zap.String("actual", a.Actual))
+			}
+		}
 	} else {
 		newLogger := pp.New()
 		newLogger.WithLineInfo = false
@@ -205,6 +224,29 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 	return pass, res
 }
 
+// splitNoise returns the body and header noise of the config along with the noise of the test case, in new
+// maps as the noise of the config is shared by the test cases.
+func splitNoise(noiseConfig map[string]map[string][]string, noise map[string][]string) (bodyNoise, headerNoise map[string][]string) {
+	bodyNoise, headerNoise = map[string][]string{}, map[string][]string{}
+	for field, regexArr := range noiseConfig["body"] {
+		bodyNoise[field] = regexArr
+	}
+	for field, regexArr := range noiseConfig["header"] {
+		headerNoise[field] = regexArr
+	}
+
+	for field, regexArr := range noise {
+		a := strings.Split(field, ".")
+		if len(a) > 1 && a[0] == "body" {
+			x := strings.Join(a[1:], ".")
+			bodyNoise[x] = regexArr
+		} else if a[0] == "header" {
+			headerNoise[a[len(a)-1]] = regexArr
+		}
+	}
+	return bodyNoise, headerNoise
+}
+
 func FlattenHTTPResponse(h http.Header, body string) (map[string][]string, error) {
 	m := map[string][]string{}
 	for k, v := range h {
@@ -250,9 +292,11 @@ func InterfaceToString(val interface{}) string {
 	}
 }
 
-func JSONDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]string, ignoreOrdering bool) (JSONComparisonResult, error) {
+// JSONDiffWithNoiseControl compares the json documents without the noisy fields, the fields with tolerances
+// are compared within them.
+func JSONDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]string, tolerances map[string][]config.Tolerance, ignoreOrdering bool) (JSONComparisonResult, error) {
 	var matchJSONComparisonResult JSONComparisonResult
-	matchJSONComparisonResult, err := matchJSONWithNoiseHandling("", validatedJSON.expected, validatedJSON.actual, noise, ignoreOrdering)
+	matchJSONComparisonResult, err := matchJSONWithNoiseHandling("", validatedJSON.expected, validatedJSON.actual, noise, tolerances, ignoreOrdering, false)
 	if err != nil {
 		return matchJSONComparisonResult, err
 	}
@@ -260,6 +304,13 @@ func JSONDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]st
 	return matchJSONComparisonResult, nil
 }
 
+// JSONSchemaDiffWithNoiseControl compares only the structure of the json documents: the keys of the objects
+// and the types of the values. The arrays may differ in length, as long as every actual element has the
+// structure of one of the expected elements.
+func JSONSchemaDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]string) (JSONComparisonResult, error) {
+	return matchJSONWithNoiseHandling("", validatedJSON.expected, validatedJSON.actual, noise, nil, true, true)
+}
+
 func ValidateAndMarshalJSON(log *zap.Logger, exp, act *string) (ValidatedJSON, error) {
 	var validatedJSON ValidatedJSON
 	expected, err := UnmarshallJSON(*exp, log)
@@ -291,8 +342,15 @@ func ValidateAndMarshalJSON(log *zap.Logger, exp, act *string) (ValidatedJSON, e
 }
 
 // matchJSONWithNoiseHandling returns strcut if expected and actual JSON objects matches(are equal) and in exact order(isExact).
-func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseMap map[string][]string, ignoreOrdering bool) (JSONComparisonResult, error) {
+// In the schema only mode the values are not compared, only the keys of the objects and the types of the values.
+func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseMap map[string][]string, tolerances map[string][]config.Tolerance, ignoreOrdering, schemaOnly bool) (JSONComparisonResult, error) {
 	var matchJSONComparisonResult JSONComparisonResult
+	// values redacted at record time match any actual value
+	if exp, ok := expected.(string); ok && pkg.MatchesRedacted(exp, InterfaceToString(actual)) {
+		matchJSONComparisonResult.isExact = true
+		matchJSONComparisonResult.matches = true
+		return matchJSONComparisonResult, nil
+	}
 	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
 		return matchJSONComparisonResult, errors.New("type not matched")
 	}
@@ -308,10 +366,17 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 	}
 	switch x.Kind() {
 	case reflect.Float64, reflect.String, reflect.Bool:
+		if schemaOnly {
+			break
+		}
 		regexArr, isNoisy := CheckStringExist(key, noiseMap)
 		if isNoisy && len(regexArr) != 0 {
 			isNoisy, _ = MatchesAnyRegex(InterfaceToString(expected), regexArr)
 		}
+		// the fields with tolerances are still compared, within the tolerances
+		if fieldTols, ok := fieldNoise(key, tolerances); ok && !isNoisy {
+			isNoisy = withinTolerance(expected, actual, fieldTols)
+		}
 		if expected != actual && !isNoisy {
 			return matchJSONComparisonResult, nil
 		}
@@ -338,7 +403,7 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 			if !ok {
 				return matchJSONComparisonResult, nil
 			}
-			if valueMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(strings.ToLower(prefix+k), v, val, noiseMap, ignoreOrdering); !valueMatchJSONComparisonResult.matches || er != nil {
+			if valueMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(strings.ToLower(prefix+k), v, val, noiseMap, tolerances, ignoreOrdering, schemaOnly); !valueMatchJSONComparisonResult.matches || er != nil {
 				return valueMatchJSONComparisonResult, nil
 			} else if !valueMatchJSONComparisonResult.isExact {
 				isExact = false
@@ -370,6 +435,9 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 		}
 		expSlice := reflect.ValueOf(expected)
 		actSlice := reflect.ValueOf(actual)
+		if schemaOnly {
+			return matchJSONSchemaSlice(key, expSlice, actSlice, noiseMap)
+		}
 		if expSlice.Len() != actSlice.Len() {
 			return matchJSONComparisonResult, nil
 		}
@@ -378,7 +446,7 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 		for i := 0; i < expSlice.Len(); i++ {
 			matched := false
 			for j := 0; j < actSlice.Len(); j++ {
-				if valMatchJSONComparisonResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, ignoreOrdering); err == nil && valMatchJSONComparisonResult.matches {
+				if valMatchJSONComparisonResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, tolerances, ignoreOrdering, schemaOnly); err == nil && valMatchJSONComparisonResult.matches {
 					if !valMatchJSONComparisonResult.isExact {
 						for _, val := range valMatchJSONComparisonResult.differences {
 							prefixedVal := key + "[" + fmt.Sprint(j) + "]." + val // Prefix the value
@@ -403,7 +471,7 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 		}
 		if !ignoreOrdering {
 			for i := 0; i < expSlice.Len(); i++ {
-				if valMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(i).Interface(), noiseMap, ignoreOrdering); er != nil || !valMatchJSONComparisonResult.isExact {
+				if valMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(i).Interface(), noiseMap, tolerances, ignoreOrdering, schemaOnly); er != nil || !valMatchJSONComparisonResult.isExact {
 					isExact = false
 					break
 				}
@@ -421,6 +489,78 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 	return matchJSONComparisonResult, nil
 }
 
+// matchJSONSchemaSlice matches every element of the actual array with the structure of one of the elements
+// of the expected array. Any actual array matches an empty expected array, it has nothing to compare with.
+func matchJSONSchemaSlice(key string, expSlice, actSlice reflect.Value, noiseMap map[string][]string) (JSONComparisonResult, error) {
+	var matchJSONComparisonResult JSONComparisonResult
+	if expSlice.Len() > 0 {
+		for j := 0; j < actSlice.Len(); j++ {
+			matched := false
+			for i := 0; i < expSlice.Len(); i++ {
+				if valMatchJSONComparisonResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, nil, true, true); err == nil && valMatchJSONComparisonResult.matches {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return matchJSONComparisonResult, nil
+			}
+		}
+	}
+	matchJSONComparisonResult.matches = true
+	matchJSONComparisonResult.isExact = true
+	return matchJSONComparisonResult, nil
+}
+
+// jsonSchema replaces the values of the json document with the names of their types, so that the diff of two
+// schemas only shows the mismatched keys and types. The distinct structures of the elements of an array are
+// kept once.
+func jsonSchema(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		schema := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			schema[k] = jsonSchema(val)
+		}
+		return schema
+	case []interface{}:
+		schema := []interface{}{}
+		seen := map[string]bool{}
+		for _, val := range v {
+			elem := jsonSchema(val)
+			b, err := json.Marshal(elem)
+			if err != nil || seen[string(b)] {
+				continue
+			}
+			seen[string(b)] = true
+			schema = append(schema, elem)
+		}
+		return schema
+	case string:
+		return "<string>"
+	case float64:
+		return "<number>"
+	case bool:
+		return "<boolean>"
+	case nil:
+		return "<null>"
+	}
+	return fmt.Sprintf("<%T>", v)
+}
+
+// jsonSchemaString returns the schema of the json body as json, the body itself when it isn't valid json.
+func jsonSchemaString(logger *zap.Logger, body string) string {
+	v, err := UnmarshallJSON(body, logger)
+	if err != nil {
+		return body
+	}
+	b, err := json.Marshal(jsonSchema(v))
+	if err != nil {
+		return body
+	}
+	return string(b)
+}
+
 // MAX_LINE_LENGTH is chars PER expected/actual string. Can be changed no problem
 const MAX_LINE_LENGTH = 50
 
@@ -436,10 +576,14 @@ type DiffsPrinter struct {
 	headNoise             map[string][]string
 	hasarrayIndexMismatch bool
 	text                  string
+	// the fields of the structured bodies other than json, one per line
+	bodyType     models.BodyType
+	bodyExpLines []string
+	bodyActLines []string
 }
 
 func NewDiffsPrinter(testCase string) DiffsPrinter {
-	return DiffsPrinter{testCase, "", "", map[string]string{}, map[string]string{}, "", "", map[string][]string{}, map[string][]string{}, false, ""}
+	return DiffsPrinter{testCase, "", "", map[string]string{}, map[string]string{}, "", "", map[string][]string{}, map[string][]string{}, false, "", "", nil, nil}
 }
 
 func (d *DiffsPrinter) PushStatusDiff(exp, act string) {
@@ -459,6 +603,11 @@ func (d *DiffsPrinter) PushBodyDiff(exp, act string, noise map[string][]string)
 	d.bodyExp, d.bodyAct, d.bodyNoise = exp, act, noise
 }
 
+// PushBodyLinesDiff sets the fields of the expected and actual structured bodies, rendered one per line.
+func (d *DiffsPrinter) PushBodyLinesDiff(exp, act []string, bodyType models.BodyType) {
+	d.bodyExpLines, d.bodyActLines, d.bodyType = exp, act, bodyType
+}
+
 // Render will display and colorize diffs side-by-side
 func (d *DiffsPrinter) Render() error {
 	diffs := []string{}
@@ -469,7 +618,9 @@ func (d *DiffsPrinter) Render() error {
 
 	diffs = append(diffs, sprintDiffHeader(d.headerExp, d.headerAct))
 
-	if len(d.bodyExp) != 0 || len(d.bodyAct) != 0 {
+	if len(d.bodyExpLines) != 0 || len(d.bodyActLines) != 0 {
+		diffs = append(diffs, sprintLinesDiff(d.bodyExpLines, d.bodyActLines, fmt.Sprintf("body (%s)", d.bodyType)))
+	} else if len(d.bodyExp) != 0 || len(d.bodyAct) != 0 {
 		bE, bA := []byte(d.bodyExp), []byte(d.bodyAct)
 		if json.Valid(bE) && json.Valid(bA) {
 			difference, err := sprintJSONDiff(bE, bA, "body", d.bodyNoise)
@@ -568,7 +719,7 @@ func sprintDiff(expect, actual, field string) string {
  * its better to use a generic diff output as the SprintDiff.
  */
 func sprintJSONDiff(json1 []byte, json2 []byte, field string, noise map[string][]string) (string, error) {
-	diffString, err := calculateJSONDiffs(json1, json2)
+	diffString, err := pkg.CalculateJSONDiffs(json1, json2)
 	if err != nil {
 		return "", err
 	}
@@ -602,93 +753,40 @@ func diffIndex(s1, s2 string) (int, bool) {
 	return i, diff
 }
 
-/* Will perform the calculation of the diffs, returning a string that
- * containes the lines that does not match represented by either a
- * minus or add symbol followed by the respective line.
- */
-func calculateJSONDiffs(json1 []byte, json2 []byte) (string, error) {
-	var diff = gojsondiff.New()
-	dObj, err := diff.Compare(json1, json2)
-	if err != nil {
-		return "", err
-	}
-
-	var jsonObject map[string]interface{}
-	err = json.Unmarshal([]byte(json1), &jsonObject)
-	if err != nil {
-		return "", err
-	}
-
-	diffString, _ := formatter.NewAsciiFormatter(jsonObject, formatter.AsciiFormatterConfig{
-		ShowArrayIndex: true,
-		Coloring:       false, // We will color our way
-	}).Format(dObj)
-
-	return diffString, nil
-}
-
 // Will receive a string that has the differences represented
 // by a plus or a minus sign and separate it. Just works with json
 func separateAndColorize(diffStr string, noise map[string][]string) (string, string) {
 	expect, actual := "", ""
 
-	diffLines := strings.Split(diffStr, "\n")
-
-	for i, line := range diffLines {
-		if len(line) > 0 {
-			noised := false
-
-			for e := range noise {
-				// If contains noise remove diff flag
-				if strings.Contains(line, e) {
-
-					if line[0] == '-' {
-						line = " " + line[1:]
-						expect += breakWithColor(line, nil, 0)
-					} else if line[0] == '+' {
-						line = " " + line[1:]
-						actual += breakWithColor(line, nil, 0)
-					}
-					noised = true
-				}
+	red, green := color.FgRed, color.FgGreen
+	for _, line := range pkg.SplitJSONDiff(diffStr, noise) {
+		switch {
+		case line.Kind == pkg.DiffSame:
+			expect += breakWithColor(line.Expected, nil, 0)
+			actual += breakWithColor(line.Actual, nil, 0)
+		case line.Noisy:
+			// If contains noise remove diff flag
+			if line.Kind != pkg.DiffAdded {
+				expect += breakWithColor(" "+line.Expected, nil, 0)
 			}
-
-			if noised {
-				continue
-			}
-
-			if line[0] == '-' {
-				c := color.FgRed
-
-				// Workaround to get the exact index where the diff begins
-				if diffLines[i+1][0] == '+' {
-
-					/* As we want to get the exact difference where the line's
-					 * diff begin we must to, first, get the expect (this) and
-					 * the actual (next) line. Then we must to espace the first
-					 * char that is an "+" or "-" symbol so we end up having
-					 * just the contents of the line we want to compare */
-					offset, _ := diffIndex(line[1:], diffLines[i+1][1:])
-					expect += breakWithColor(line, &c, offset+1)
-				} else {
-					// In the case where there isn't in fact an actual
-					// version to compare, it was just expect to have this
-					expect += breakWithColor(line, &c, 0)
-				}
-			} else if line[0] == '+' {
-				c := color.FgGreen
-
-				// Here we do the same thing as above, just inverted
-				if diffLines[i-1][0] == '-' {
-					offset, _ := diffIndex(line[1:], diffLines[i-1][1:])
-					actual += breakWithColor(line, &c, offset+1)
-				} else {
-					actual += breakWithColor(line, &c, 0)
-				}
-			} else {
-				expect += breakWithColor(line, nil, 0)
-				actual += breakWithColor(line, nil, 0)
+			if line.Kind != pkg.DiffRemoved {
+				actual += breakWithColor(" "+line.Actual, nil, 0)
 			}
+		case line.Kind == pkg.DiffChanged:
+			/* As we want to get the exact difference where the line's
+			 * diff begin we must to, first, get the expect and the
+			 * actual line without their "+" or "-" symbol. Then the
+			 * offset is shifted by one for the symbol */
+			offset, _ := diffIndex(line.Expected, line.Actual)
+			expect += breakWithColor("-"+line.Expected, &red, offset+1)
+			offset, _ = diffIndex(line.Actual, line.Expected)
+			actual += breakWithColor("+"+line.Actual, &green, offset+1)
+		case line.Kind == pkg.DiffRemoved:
+			// In the case where there isn't in fact an actual
+			// version to compare, it was just expect to have this
+			expect += breakWithColor("-"+line.Expected, &red, 0)
+		case line.Kind == pkg.DiffAdded:
+			actual += breakWithColor("+"+line.Actual, &green, 0)
 		}
 	}
 
@@ -774,19 +872,29 @@ func checkKey(res *[]models.HeaderResult, key string) bool {
 	return true
 }
 
-func CompareHeaders(h1 http.Header, h2 http.Header, res *[]models.HeaderResult, noise map[string][]string) bool {
+// CompareHeaders compares the expected headers h1 with the actual headers h2 without the noisy ones, the headers
+// with tolerances are compared within them.
+func CompareHeaders(h1 http.Header, h2 http.Header, res *[]models.HeaderResult, noise map[string][]string, tolerances map[string][]config.Tolerance) bool {
 	if res == nil {
 		return false
 	}
 	match := true
 	_, isHeaderNoisy := noise["header"]
 	for k, v := range h1 {
+		val, ok := h2[k]
 		regexArr, isNoisy := CheckStringExist(strings.ToLower(k), noise)
 		if isNoisy && len(regexArr) != 0 {
 			isNoisy, _ = MatchesAnyRegex(v[0], regexArr)
 		}
+		// the headers with tolerances are still compared, within the tolerances
+		if headerTols, tolerant := fieldNoise(strings.ToLower(k), tolerances); tolerant && ok && !isNoisy {
+			isNoisy = withinTolerance(strings.Join(v, ","), strings.Join(val, ","), headerTols)
+		}
 		isNoisy = isNoisy || isHeaderNoisy
-		val, ok := h2[k]
+		// values redacted at record time match any actual value
+		if ok && pkg.MatchesRedacted(strings.Join(v, ","), strings.Join(val, ",")) {
+			isNoisy = true
+		}
 		if !isNoisy {
 			if !ok {
 				if checkKey(res, k) {
@@ -858,12 +966,16 @@ func CompareHeaders(h1 http.Header, h2 http.Header, res *[]models.HeaderResult,
 		}
 	}
 	for k, v := range h2 {
+		val, ok := h1[k]
 		regexArr, isNoisy := CheckStringExist(strings.ToLower(k), noise)
 		if isNoisy && len(regexArr) != 0 {
 			isNoisy, _ = MatchesAnyRegex(v[0], regexArr)
 		}
+		// an actual header with tolerances matches only when the expected one is within them
+		if headerTols, tolerant := fieldNoise(strings.ToLower(k), tolerances); tolerant && ok && !isNoisy {
+			isNoisy = withinTolerance(strings.Join(val, ","), strings.Join(v, ","), headerTols)
+		}
 		isNoisy = isNoisy || isHeaderNoisy
-		val, ok := h1[k]
 		if isNoisy && checkKey(res, k) {
 			*res = append(*res, models.HeaderResult{
 				Normal: true,
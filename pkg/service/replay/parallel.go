@@ -0,0 +1,162 @@
+//go:build linux
+
+package replay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// WorkerIDEnv is exported to every app instance of the parallel mode with the index of its worker. The
+// instance of worker N is sent the requests on the recorded ports + N, so it has to listen on them.
+const WorkerIDEnv = "KEPLOY_WORKER_ID"
+
+// testSetRun is the outcome of a test set run by a worker of the parallel mode.
+type testSetRun struct {
+	testSetID string
+	status    models.TestSetStatus
+	err       error
+}
+
+func workerEnv(worker int) []string {
+	return []string{fmt.Sprintf("%s=%d", WorkerIDEnv, worker)}
+}
+
+// parallelism returns the number of test sets to run at once. Every test set needs its own instance of the
+// app, so the parallel mode is only available for the native apps started and hooked by keploy.
+func (r *Replayer) parallelism(testSets int) int {
+	n := r.config.Test.Parallel
+	if n <= 1 || testSets <= 1 {
+		return 1
+	}
+	switch {
+	case r.config.Test.BasePath != "":
+		r.logger.Warn("test sets can't run in parallel when base path is provided, running them one after another")
+		return 1
+	case utils.IsDockerKind(utils.CmdType(r.config.CommandType)):
+		r.logger.Warn("test sets can't run in parallel for docker apps, running them one after another")
+		return 1
+	case r.config.EnableTesting:
+		return 1
+	}
+	return min(n, testSets)
+}
+
+// selectedTestSets returns the test sets selected for the test run.
+func (r *Replayer) selectedTestSets(testSetIDs []string) []string {
+	if len(r.config.Test.SelectedTests) == 0 {
+		return testSetIDs
+	}
+	var selected []string
+	for _, testSetID := range testSetIDs {
+		if _, ok := r.config.Test.SelectedTests[testSetID]; ok {
+			selected = append(selected, testSetID)
+		}
+	}
+	return selected
+}
+
+// testSetVerdict tells whether the test set passed and whether the test run has to stop after it.
+func testSetVerdict(status models.TestSetStatus) (passed bool, abort bool) {
+	switch status {
+	case models.TestSetStatusAppHalted, models.TestSetStatusInternalErr, models.TestSetStatusFaultUserApp:
+		return false, true
+	case models.TestSetStatusPassed:
+		return true, false
+	}
+	return false, false
+}
+
+// runTestSetsParallel runs the test sets on several instances of the app at once, the first instance is
+// the one already hooked. Every instance gets its own mocks in the proxy as they are keyed by the app id,
+// and the test sets write their reports to the same test run. The runs are returned in the order of the
+// test sets, the test sets not started because the test run was stopped are left out.
+func (r *Replayer) runTestSetsParallel(ctx context.Context, testSetIDs []string, testRunID string, appID uint64, parallel int) ([]testSetRun, error) {
+	appIDs := []uint64{appID}
+	// the cancel of the instances of the workers, the first instance is stopped by the caller. The hooks and the
+	// proxy are shared with the first instance, they are stopped along with it
+	hookCancels := []context.CancelFunc{nil}
+	for worker := 1; worker < parallel; worker++ {
+		inst, err := r.instrument(ctx, workerEnv(worker))
+		if err != nil {
+			for _, cancel := range hookCancels[1:] {
+				cancel()
+			}
+			return nil, err
+		}
+		appIDs = append(appIDs, inst.AppID)
+		hookCancels = append(hookCancels, inst.HookCancel)
+	}
+	r.logger.Info("running the test sets in parallel", zap.Int("workers", len(appIDs)), zap.Int("test sets", len(testSetIDs)))
+
+	var mu sync.Mutex
+	runs := map[string]testSetRun{}
+	stopped := false
+	testSets := make(chan string)
+
+	var wg sync.WaitGroup
+	for worker, appID := range appIDs {
+		wg.Add(1)
+		go func(worker int, appID uint64) {
+			defer wg.Done()
+			defer utils.Recover(r.logger)
+			if cancel := hookCancels[worker]; cancel != nil {
+				defer cancel()
+			}
+			for testSetID := range testSets {
+				requestMockemulator.ProcessMockFile(ctx, testSetID)
+				status, err := r.runTestSet(ctx, testSetID, testRunID, appID, false, worker)
+				passed, abort := testSetVerdict(status)
+
+				mu.Lock()
+				runs[testSetID] = testSetRun{testSetID: testSetID, status: status, err: err}
+				// the remaining test sets are not started once a test set fails to run or halts its app
+				if err != nil || abort || status == models.TestSetStatusUserAbort {
+					stopped = true
+				}
+				mu.Unlock()
+
+				if err != nil || abort {
+					continue
+				}
+				if passed {
+					requestMockemulator.ProcessTestRunStatus(ctx, passed, testSetID)
+				}
+				_, err = requestMockemulator.AfterTestHook(ctx, testRunID, testSetID, len(testSetIDs))
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get after test hook")
+				}
+			}
+		}(worker, appID)
+	}
+
+dispatch:
+	for _, testSetID := range testSetIDs {
+		mu.Lock()
+		stop := stopped
+		mu.Unlock()
+		if stop {
+			break
+		}
+		select {
+		case testSets <- testSetID:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(testSets)
+	wg.Wait()
+
+	ordered := make([]testSetRun, 0, len(runs))
+	for _, testSetID := range testSetIDs {
+		if run, ok := runs[testSetID]; ok {
+			ordered = append(ordered, run)
+		}
+	}
+	return ordered, nil
+}
@@ -6,12 +6,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,6 +22,7 @@ import (
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/report"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -29,6 +33,10 @@ var totalTests int
 var totalTestPassed int
 var totalTestFailed int
 
+// reportMu guards the verdicts of the test sets and their summaries, which are written by every worker in
+// parallel mode
+var reportMu sync.Mutex
+
 // emulator contains the struct instance that implements RequestEmulator interface. This is done for
 // attaching the objects dynamically as plugins.
 var requestMockemulator RequestMockHandler
@@ -42,13 +50,19 @@ type Replayer struct {
 	testDB          TestDB
 	mockDB          MockDB
 	reportDB        ReportDB
+	coverageDB      CoverageDB
 	testSetConf     Config
 	telemetry       Telemetry
 	instrumentation Instrumentation
 	config          *config.Config
+	// denoise is set when the test cases are replayed to propose the fields which differ as noise
+	denoise bool
+	// missingSecrets are the env variables of the redacted request headers which are not set, they are
+	// reported once
+	missingSecrets sync.Map
 }
 
-func NewReplayer(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB ReportDB, testSetConf Config, telemetry Telemetry, instrumentation Instrumentation, config *config.Config) Service {
+func NewReplayer(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB ReportDB, coverageDB CoverageDB, testSetConf Config, telemetry Telemetry, instrumentation Instrumentation, config *config.Config) Service {
 	// set the request emulator for simulating test case requests, if not set
 	if requestMockemulator == nil {
 		SetTestUtilInstance(NewRequestMockUtil(logger, config.Path, "mocks", config.Test.APITimeout, config.Test.BasePath))
@@ -58,6 +72,7 @@ func NewReplayer(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB Repo
 		testDB:          testDB,
 		mockDB:          mockDB,
 		reportDB:        reportDB,
+		coverageDB:      coverageDB,
 		testSetConf:     testSetConf,
 		telemetry:       telemetry,
 		instrumentation: instrumentation,
@@ -111,6 +126,23 @@ func (r *Replayer) Start(ctx context.Context) error {
 		return fmt.Errorf(errMsg)
 	}
 
+	if r.config.Test.ChangedSince != "" {
+		affected, err := r.selectAffectedTests(ctx, testSetIDs)
+		if err != nil {
+			stopReason = fmt.Sprintf("failed to select the test cases affected by the changes: %v", err)
+			utils.LogError(r.logger, err, stopReason)
+			if err == context.Canceled {
+				return err
+			}
+			return fmt.Errorf(stopReason)
+		}
+		if !affected {
+			stopReason = fmt.Sprintf("no test case covers the code changed since %s", r.config.Test.ChangedSince)
+			r.logger.Info(stopReason)
+			return nil
+		}
+	}
+
 	testRunID, err := r.GetNextTestRunID(ctx)
 	if err != nil {
 		stopReason = fmt.Sprintf("failed to get next test run id: %v", err)
@@ -121,8 +153,15 @@ func (r *Replayer) Start(ctx context.Context) error {
 		return fmt.Errorf(stopReason)
 	}
 
+	parallel := r.parallelism(len(testSetIDs))
+
 	// Instrument will load the hooks and start the proxy
-	inst, err := r.Instrument(ctx)
+	var inst *InstrumentState
+	if parallel > 1 {
+		inst, err = r.instrument(ctx, workerEnv(0))
+	} else {
+		inst, err = r.Instrument(ctx)
+	}
 	if err != nil {
 		stopReason = fmt.Sprintf("failed to instrument: %v", err)
 		utils.LogError(r.logger, err, stopReason)
@@ -137,46 +176,77 @@ func (r *Replayer) Start(ctx context.Context) error {
 	testSetResult := false
 	testRunResult := true
 	abortTestRun := false
-	for _, testSetID := range testSetIDs {
-		if _, ok := r.config.Test.SelectedTests[testSetID]; !ok && len(r.config.Test.SelectedTests) != 0 {
-			continue
-		}
-		requestMockemulator.ProcessMockFile(ctx, testSetID)
-		testSetStatus, err := r.RunTestSet(ctx, testSetID, testRunID, inst.AppID, false)
+	var ranTestSetIDs []string
+	if parallel > 1 {
+		runs, err := r.runTestSetsParallel(ctx, r.selectedTestSets(testSetIDs), testRunID, inst.AppID, parallel)
 		if err != nil {
-			stopReason = fmt.Sprintf("failed to run test set: %v", err)
+			stopReason = fmt.Sprintf("failed to run the test sets in parallel: %v", err)
 			utils.LogError(r.logger, err, stopReason)
 			if err == context.Canceled {
 				return err
 			}
 			return fmt.Errorf(stopReason)
 		}
-		switch testSetStatus {
-		case models.TestSetStatusAppHalted:
-			testSetResult = false
-			abortTestRun = true
-		case models.TestSetStatusInternalErr:
-			testSetResult = false
-			abortTestRun = true
-		case models.TestSetStatusFaultUserApp:
-			testSetResult = false
-			abortTestRun = true
-		case models.TestSetStatusUserAbort:
-			return nil
-		case models.TestSetStatusFailed:
-			testSetResult = false
-		case models.TestSetStatusPassed:
-			testSetResult = true
-			requestMockemulator.ProcessTestRunStatus(ctx, testSetResult, testSetID)
-		}
-		testRunResult = testRunResult && testSetResult
-		if abortTestRun {
-			break
+		for _, run := range runs {
+			ranTestSetIDs = append(ranTestSetIDs, run.testSetID)
+			if run.err != nil {
+				stopReason = fmt.Sprintf("failed to run test set: %v", run.err)
+				utils.LogError(r.logger, run.err, stopReason)
+				if run.err == context.Canceled {
+					return run.err
+				}
+				return fmt.Errorf(stopReason)
+			}
+			if run.status == models.TestSetStatusUserAbort {
+				return nil
+			}
+			passed, abort := testSetVerdict(run.status)
+			testRunResult = testRunResult && passed
+			abortTestRun = abortTestRun || abort
 		}
+	} else {
+		for _, testSetID := range testSetIDs {
+			if _, ok := r.config.Test.SelectedTests[testSetID]; !ok && len(r.config.Test.SelectedTests) != 0 {
+				continue
+			}
+			requestMockemulator.ProcessMockFile(ctx, testSetID)
+			ranTestSetIDs = append(ranTestSetIDs, testSetID)
+			testSetStatus, err := r.RunTestSet(ctx, testSetID, testRunID, inst.AppID, false)
+			if err != nil {
+				stopReason = fmt.Sprintf("failed to run test set: %v", err)
+				utils.LogError(r.logger, err, stopReason)
+				if err == context.Canceled {
+					return err
+				}
+				return fmt.Errorf(stopReason)
+			}
+			switch testSetStatus {
+			case models.TestSetStatusAppHalted:
+				testSetResult = false
+				abortTestRun = true
+			case models.TestSetStatusInternalErr:
+				testSetResult = false
+				abortTestRun = true
+			case models.TestSetStatusFaultUserApp:
+				testSetResult = false
+				abortTestRun = true
+			case models.TestSetStatusUserAbort:
+				return nil
+			case models.TestSetStatusFailed:
+				testSetResult = false
+			case models.TestSetStatusPassed:
+				testSetResult = true
+				requestMockemulator.ProcessTestRunStatus(ctx, testSetResult, testSetID)
+			}
+			testRunResult = testRunResult && testSetResult
+			if abortTestRun {
+				break
+			}
 
-		_, err = requestMockemulator.AfterTestHook(ctx, testRunID, testSetID, len(testSetIDs))
-		if err != nil {
-			utils.LogError(r.logger, err, "failed to get after test hook")
+			_, err = requestMockemulator.AfterTestHook(ctx, testRunID, testSetID, len(testSetIDs))
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to get after test hook")
+			}
 		}
 	}
 
@@ -187,19 +257,49 @@ func (r *Replayer) Start(ctx context.Context) error {
 
 	r.telemetry.TestRun(totalTestPassed, totalTestFailed, len(testSetIDs), testRunStatus)
 
+	if len(r.config.Test.ReportFormats) > 0 && !r.denoise {
+		r.exportReports(ctx, testRunID, ranTestSetIDs)
+	}
+
 	if !abortTestRun {
 		r.printSummary(ctx, testRunResult)
 	}
 	return nil
 }
 
+// exportReports writes the reports of the test run in the formats given by --report-format
+func (r *Replayer) exportReports(ctx context.Context, testRunID string, testSetIDs []string) {
+	ctx = context.WithoutCancel(ctx)
+	reports := make([]*models.TestReport, 0, len(testSetIDs))
+	for _, testSetID := range testSetIDs {
+		testReport, err := r.reportDB.GetReport(ctx, testRunID, testSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the report for exporting", zap.String("testSetID", testSetID))
+			continue
+		}
+		if testReport.TestSet == "" {
+			testReport.TestSet = testSetID
+		}
+		reports = append(reports, testReport)
+	}
+	err := report.WriteReports(ctx, r.logger, filepath.Join(r.config.Path, "reports", testRunID), testRunID, reports, r.config.Test.ReportFormats)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to export the test run report")
+	}
+}
+
 func (r *Replayer) Instrument(ctx context.Context) (*InstrumentState, error) {
+	return r.instrument(ctx, nil)
+}
+
+// instrument sets up an instance of the application with the env variables exported to its command.
+func (r *Replayer) instrument(ctx context.Context, env []string) (*InstrumentState, error) {
 	if r.config.Test.BasePath != "" {
 		r.logger.Info("Keploy will not mock the outgoing calls when base path is provided", zap.Any("base path", r.config.Test.BasePath))
 		return &InstrumentState{}, nil
 	}
 
-	appID, err := r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay})
+	appID, err := r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay, Env: env})
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return &InstrumentState{}, err
@@ -243,6 +343,11 @@ func (r *Replayer) GetAllTestSetIDs(ctx context.Context) ([]string, error) {
 }
 
 func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID string, appID uint64, serveTest bool) (models.TestSetStatus, error) {
+	return r.runTestSet(ctx, testSetID, testRunID, appID, serveTest, 0)
+}
+
+// runTestSet runs the test set on the app, the requests are sent to the recorded ports shifted by portOffset.
+func (r *Replayer) runTestSet(ctx context.Context, testSetID string, testRunID string, appID uint64, serveTest bool, portOffset int) (models.TestSetStatus, error) {
 	// creating error group to manage proper shutdown of all the go routines and to propagate the error to the caller
 	runTestSetErrGrp, runTestSetCtx := errgroup.WithContext(ctx)
 	runTestSetCtx = context.WithValue(runTestSetCtx, models.ErrGroupKey, runTestSetErrGrp)
@@ -263,10 +368,20 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	var err error
 	var postscript string
 
+	// the test set config is optional, it holds the templates of the requests, the chaos config and the
+	// pre/post scripts, a config which exists but can't be read fails the test set
+	conf, err = r.testSetConf.Read(runTestSetCtx, testSetID)
+	if err != nil && r.config.Test.BasePath == "" {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return models.TestSetStatusFailed, fmt.Errorf("failed to read test set config: %w", err)
+		}
+		r.logger.Debug("no config found for the test set", zap.String("test-set", testSetID))
+		conf = nil
+	}
+
 	// Pre/Post script will be executed only if the base path is provided
 	if r.config.Test.BasePath != "" {
 		//Execute the Pre-script before each test-set if provided
-		conf, err = r.testSetConf.Read(runTestSetCtx, testSetID)
 		if err != nil {
 			return models.TestSetStatusFailed, fmt.Errorf("failed to read test set config: %w", err)
 		}
@@ -286,6 +401,7 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	var appErr models.AppError
 	var success int
 	var failure int
+	var flaky []string
 	var totalConsumedMocks = map[string]bool{}
 
 	testSetStatus := models.TestSetStatusPassed
@@ -298,6 +414,11 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		return models.TestSetStatusFailed, fmt.Errorf("failed to get test cases: %w", err)
 	}
 
+	vars, err := newTemplateVars(r.logger, conf)
+	if err != nil {
+		return models.TestSetStatusFailed, fmt.Errorf("invalid templates in the test set config: %w", err)
+	}
+
 	if len(testCases) == 0 {
 		return models.TestSetStatusPassed, nil
 	}
@@ -305,11 +426,28 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	cmdType := utils.CmdType(r.config.CommandType)
 	var userIP string
 
-	err = r.SetupOrUpdateMocks(runTestSetCtx, appID, testSetID, models.BaseTime, time.Now(), Start)
+	_, err = r.SetupOrUpdateMocks(runTestSetCtx, appID, testSetID, models.BaseTime, time.Now(), Start)
 	if err != nil {
 		return models.TestSetStatusFailed, err
 	}
 
+	if r.config.Test.BasePath == "" && r.config.Test.Mocking {
+		// the faults of the previous test set are cleared when the test set has no chaos config
+		var faults []models.Fault
+		if conf != nil {
+			faults = conf.Chaos
+		}
+		err = r.instrumentation.SetFaults(runTestSetCtx, appID, faults)
+		if err != nil {
+			return models.TestSetStatusFailed, fmt.Errorf("invalid chaos config of the test set: %w", err)
+		}
+		if len(faults) > 0 {
+			r.logger.Info("injecting the faults of the chaos config into the mocks", zap.String("test-set", testSetID), zap.Int("faults", len(faults)))
+		}
+	} else if conf != nil && len(conf.Chaos) > 0 {
+		r.logger.Warn("the faults of the chaos config are not injected, they are injected only into the mocks of a mocked run", zap.String("test-set", testSetID), zap.Int("faults", len(conf.Chaos)))
+	}
+
 	if r.config.Test.BasePath == "" {
 		if !serveTest {
 			runTestSetErrGrp.Go(func() error {
@@ -381,16 +519,23 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		Status:  string(models.TestStatusRunning),
 	}
 
-	err = r.reportDB.InsertReport(runTestSetCtx, testRunID, testSetID, testReport)
-	if err != nil {
-		utils.LogError(r.logger, err, "failed to insert report")
-		return models.TestSetStatusFailed, err
+	// the test cases replayed to propose the noise are not a test run, their reports are not written
+	if !r.denoise {
+		err = r.reportDB.InsertReport(runTestSetCtx, testRunID, testSetID, testReport)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to insert report")
+			return models.TestSetStatusFailed, err
+		}
 	}
 
 	// var to exit the loop
 	var exitLoop bool
 	// var to store the error in the loop
 	var loopErr error
+	// noise proposed for the test cases in the denoise mode
+	var proposals []models.NoiseParams
+	// the code covered by every test case is captured for the go apps
+	collector := r.coverageCollector(runTestSetCtx, testSetID, portOffset)
 
 	for _, testCase := range testCases {
 
@@ -398,15 +543,37 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 			continue
 		}
 
+		// the placeholders are resolved before the url is rewritten, as rewriting it escapes the braces
+		vars.apply(testCase)
+
+		reqURL := &testCase.HTTPReq.URL
+		reqTime, respTime := testCase.HTTPReq.Timestamp, testCase.HTTPResp.Timestamp
+		if testCase.Kind == models.GRPC_EXPORT {
+			// the grpc requests are sent to their authority, which is rewritten like the url of the http requests
+			authorityURL := "http://" + testCase.GrpcReq.Headers.PseudoHeaders[":authority"]
+			reqURL = &authorityURL
+			reqTime, respTime = testCase.GrpcReq.Timestamp, testCase.GrpcResp.Timestamp
+		}
+
 		// replace the request URL's BasePath/origin if provided
 		if r.config.Test.BasePath != "" {
-			newURL, err := ReplaceBaseURL(r.config.Test.BasePath, testCase.HTTPReq.URL)
+			newURL, err := ReplaceBaseURL(r.config.Test.BasePath, *reqURL)
 			if err != nil {
 				r.logger.Warn("failed to replace the request basePath", zap.String("testcase", testCase.Name), zap.String("basePath", r.config.Test.BasePath), zap.Error(err))
 			} else {
-				testCase.HTTPReq.URL = newURL
+				*reqURL = newURL
+			}
+			r.logger.Debug("test case request origin", zap.String("testcase", testCase.Name), zap.String("TestCaseURL", *reqURL), zap.String("basePath", r.config.Test.BasePath))
+		}
+
+		// every app instance of the parallel mode listens on its own port
+		if portOffset != 0 {
+			newURL, err := ShiftPort(*reqURL, portOffset)
+			if err != nil {
+				r.logger.Warn("failed to shift the port of the request", zap.String("testcase", testCase.Name), zap.Int("offset", portOffset), zap.Error(err))
+			} else {
+				*reqURL = newURL
 			}
-			r.logger.Debug("test case request origin", zap.String("testcase", testCase.Name), zap.String("TestCaseURL", testCase.HTTPReq.URL), zap.String("basePath", r.config.Test.BasePath))
 		}
 
 		// Checking for errors in the mocking and application
@@ -427,7 +594,7 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		var loopErr error
 
 		//No need to handle mocking when basepath is provided
-		err := r.SetupOrUpdateMocks(runTestSetCtx, appID, testSetID, testCase.HTTPReq.Timestamp, testCase.HTTPResp.Timestamp, Update)
+		expectedMocks, err := r.SetupOrUpdateMocks(runTestSetCtx, appID, testSetID, reqTime, respTime, Update)
 		if err != nil {
 			utils.LogError(r.logger, err, "failed to update mocks")
 			break
@@ -435,36 +602,118 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 
 		if utils.IsDockerKind(cmdType) && r.config.Test.BasePath == "" {
 
-			testCase.HTTPReq.URL, err = utils.ReplaceHostToIP(testCase.HTTPReq.URL, userIP)
+			*reqURL, err = utils.ReplaceHostToIP(*reqURL, userIP)
 			if err != nil {
 				utils.LogError(r.logger, err, "failed to replace host to docker container's IP")
 				break
 			}
-			r.logger.Debug("", zap.Any("replaced URL in case of docker env", testCase.HTTPReq.URL))
+			r.logger.Debug("", zap.Any("replaced URL in case of docker env", *reqURL))
+		}
+
+		if testCase.Kind == models.GRPC_EXPORT {
+			authority, err := url.Parse(*reqURL)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to parse the rewritten authority of the grpc request")
+				break
+			}
+			testCase.GrpcReq.Headers.PseudoHeaders[":authority"] = authority.Host
 		}
 
 		started := time.Now().UTC()
-		resp, loopErr := requestMockemulator.SimulateRequest(runTestSetCtx, appID, testCase, testSetID)
+		var resp *models.HTTPResp
+		var grpcResp *models.GrpcResp
+		var consumedMocks []string
+		var injectedFaults []models.InjectedFault
+		// a failing test case is re-run up to the configured number of retries
+		retries := 0
+		// the outgoing calls are checked against the mocks only when they are served from the mocks
+		checkDeps := r.config.Test.BasePath == "" && r.config.Test.Mocking
+		for {
+			if checkDeps {
+				// the calls which were made before the request, e.g. by the previous test case, are left out
+				_, err = r.instrumentation.GetUnmatchedCalls(runTestSetCtx, appID)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get the unmatched outgoing calls")
+				}
+				_, err = r.instrumentation.GetInjectedFaults(runTestSetCtx, appID)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to get the injected faults")
+				}
+			}
+			requested := time.Now()
+			if testCase.Kind == models.GRPC_EXPORT {
+				grpcResp, loopErr = requestMockemulator.SimulateGrpcRequest(runTestSetCtx, appID, testCase, testSetID)
+			} else {
+				resp, loopErr = requestMockemulator.SimulateRequest(runTestSetCtx, appID, r.withSecrets(testCase), testSetID)
+			}
+			elapsed := time.Since(requested)
+			if loopErr == nil {
+				if r.config.Test.BasePath == "" {
+					consumedMocks, err = r.instrumentation.GetConsumedMocks(runTestSetCtx, appID)
+					if err != nil {
+						utils.LogError(r.logger, err, "failed to get consumed filtered mocks")
+					}
+					if r.config.Test.RemoveUnusedMocks {
+						for _, mockName := range consumedMocks {
+							totalConsumedMocks[mockName] = true
+						}
+					}
+				}
+				if testCase.Kind == models.GRPC_EXPORT {
+					testPass, testResult = r.compareGrpcResp(testCase, grpcResp, testSetID)
+				} else {
+					testPass, testResult = r.compareResp(testCase, resp, testSetID, elapsed)
+				}
+				if checkDeps {
+					unmatched, err := r.instrumentation.GetUnmatchedCalls(runTestSetCtx, appID)
+					if err != nil {
+						utils.LogError(r.logger, err, "failed to get the unmatched outgoing calls")
+					}
+					testResult.DepResult = depResults(expectedMocks, consumedMocks, unmatched)
+					injectedFaults, err = r.instrumentation.GetInjectedFaults(runTestSetCtx, appID)
+					if err != nil {
+						utils.LogError(r.logger, err, "failed to get the injected faults")
+					}
+					if !depsPass(testResult.DepResult, depStrictness(r.config.Test)) {
+						unconsumed, unexpected := depDiffs(testResult.DepResult)
+						r.logger.Warn("the outgoing calls of the test case differ from the recorded ones", zap.String("testcase id", testCase.Name), zap.String("testset id", testSetID), zap.Strings("unconsumed mocks", unconsumed), zap.Strings("unexpected calls", unexpected))
+						testPass = false
+					}
+				}
+			}
+			if testPass || retries >= r.config.Test.Retries || runTestSetCtx.Err() != nil {
+				break
+			}
+			retries++
+			r.logger.Info("retrying the failed test case", zap.Any("testcase id", testCase.Name), zap.Any("testset id", testSetID), zap.Int("retry", retries))
+			// the mocks consumed by the failed attempt are restored so that the retry runs against the same mocks
+			expectedMocks, err = r.SetupOrUpdateMocks(runTestSetCtx, appID, testSetID, reqTime, respTime, Update)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to reset the mocks for the retry")
+				break
+			}
+		}
+		if collector != nil && !r.captureCoverage(runTestSetCtx, collector, testRunID, testSetID, testCase.Name) {
+			collector = nil
+		}
 		if loopErr != nil {
-			utils.LogError(r.logger, err, "failed to simulate request")
+			utils.LogError(r.logger, loopErr, "failed to simulate request")
 			failure++
 			continue
 		}
 
-		var consumedMocks []string
-		if r.config.Test.BasePath == "" {
-			consumedMocks, err = r.instrumentation.GetConsumedMocks(runTestSetCtx, appID)
+		// the values of the response are available to the requests of the following test cases
+		vars.extract(testCase.Name, resp)
+
+		if r.denoise && testCase.Kind == models.HTTP {
+			noise, err := NoisyFields(testCase, resp)
 			if err != nil {
-				utils.LogError(r.logger, err, "failed to get consumed filtered mocks")
-			}
-			if r.config.Test.RemoveUnusedMocks {
-				for _, mockName := range consumedMocks {
-					totalConsumedMocks[mockName] = true
-				}
+				utils.LogError(r.logger, err, "failed to find the noisy fields of the response", zap.String("testcase id", testCase.Name))
+			} else if len(noise) > 0 {
+				proposals = append(proposals, ProposeNoise(testCase.Name, noise))
 			}
 		}
 
-		testPass, testResult = r.compareResp(testCase, resp, testSetID)
 		if !testPass {
 			// log the consumed mocks during the test run of the test case for test set
 			r.logger.Info("result", zap.Any("testcase id", models.HighlightFailingString(testCase.Name)), zap.Any("testset id", models.HighlightFailingString(testSetID)), zap.Any("passed", models.HighlightFailingString(testPass)))
@@ -472,7 +721,15 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		} else {
 			r.logger.Info("result", zap.Any("testcase id", models.HighlightPassingString(testCase.Name)), zap.Any("testset id", models.HighlightPassingString(testSetID)), zap.Any("passed", models.HighlightPassingString(testPass)))
 		}
-		if testPass {
+		if len(injectedFaults) > 0 {
+			r.logger.Info("injected faults into the mocks of the test case", zap.String("testcase id", testCase.Name), zap.String("testset id", testSetID), zap.Any("faults", injectedFaults))
+		}
+		if testPass && retries > 0 {
+			r.logger.Warn("test case passed only on a retry, marking it as flaky", zap.String("testcase id", testCase.Name), zap.String("testset id", testSetID), zap.Int("retries", retries))
+			testStatus = models.TestStatusFlaky
+			success++
+			flaky = append(flaky, testCase.Name)
+		} else if testPass {
 			testStatus = models.TestStatusPassed
 			success++
 		} else {
@@ -483,13 +740,24 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 
 		if testResult != nil {
 			testCaseResult := &models.TestResult{
-				Kind:       models.HTTP,
-				Name:       testSetID,
-				Status:     testStatus,
-				Started:    started.Unix(),
-				Completed:  time.Now().UTC().Unix(),
-				TestCaseID: testCase.Name,
-				Req: models.HTTPReq{
+				Kind:         testCase.Kind,
+				Name:         testSetID,
+				Status:       testStatus,
+				Started:      started.Unix(),
+				Completed:    time.Now().UTC().Unix(),
+				TestCaseID:   testCase.Name,
+				TestCasePath: filepath.Join(r.config.Path, testSetID),
+				MockPath:     filepath.Join(r.config.Path, testSetID, requestMockemulator.FetchMockName()),
+				Noise:        testCase.Noise,
+				Result:       *testResult,
+				Retries:      retries,
+				Faults:       injectedFaults,
+			}
+			if testCase.Kind == models.GRPC_EXPORT {
+				testCaseResult.GrpcReq = testCase.GrpcReq
+				testCaseResult.GrpcRes = *grpcResp
+			} else {
+				testCaseResult.Req = models.HTTPReq{
 					Method:     testCase.HTTPReq.Method,
 					ProtoMajor: testCase.HTTPReq.ProtoMajor,
 					ProtoMinor: testCase.HTTPReq.ProtoMinor,
@@ -500,12 +768,11 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 					Binary:     testCase.HTTPReq.Binary,
 					Form:       testCase.HTTPReq.Form,
 					Timestamp:  testCase.HTTPReq.Timestamp,
-				},
-				Res:          *resp,
-				TestCasePath: filepath.Join(r.config.Path, testSetID),
-				MockPath:     filepath.Join(r.config.Path, testSetID, requestMockemulator.FetchMockName()),
-				Noise:        testCase.Noise,
-				Result:       *testResult,
+				}
+				testCaseResult.Res = *resp
+			}
+			if !testPass {
+				testCaseResult.ConsumedMocks = consumedMocks
 			}
 			loopErr = r.reportDB.InsertTestCaseResult(runTestSetCtx, testRunID, testSetID, testCaseResult)
 			if loopErr != nil {
@@ -524,6 +791,13 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		}
 	}
 
+	if r.denoise {
+		err = r.addNoiseReview(context.WithoutCancel(runTestSetCtx), testSetID, proposals)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to write the proposed noise for review", zap.String("testset id", testSetID))
+		}
+	}
+
 	//Execute the Post-script after each test-set if provided
 	if r.config.Test.BasePath != "" {
 		r.logger.Info("Running Post-script", zap.String("script", postscript), zap.String("test-set", testSetID))
@@ -561,14 +835,17 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		Success: success,
 		Failure: failure,
 		Tests:   testCaseResults,
+		Flaky:   flaky,
 	}
 
 	// final report should have reason for sudden stop of the test run so this should get canceled
 	reportCtx := context.WithoutCancel(runTestSetCtx)
-	err = r.reportDB.InsertReport(reportCtx, testRunID, testSetID, testReport)
-	if err != nil {
-		utils.LogError(r.logger, err, "failed to insert report")
-		return models.TestSetStatusInternalErr, fmt.Errorf("failed to insert report")
+	if !r.denoise {
+		err = r.reportDB.InsertReport(reportCtx, testRunID, testSetID, testReport)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to insert report")
+			return models.TestSetStatusInternalErr, fmt.Errorf("failed to insert report")
+		}
 	}
 
 	// remove the unused mocks by the test cases of a testset (if the base path is not provided )
@@ -589,6 +866,7 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		status: testSetStatus == models.TestSetStatusPassed,
 	}
 
+	reportMu.Lock()
 	completeTestReport[testSetID] = verdict
 	totalTests += testReport.Total
 	totalTestPassed += testReport.Success
@@ -604,6 +882,7 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 			utils.LogError(r.logger, err, "failed to print testrun summary")
 		}
 	}
+	reportMu.Unlock()
 
 	r.telemetry.TestSetRun(testReport.Success, testReport.Failure, testSetID, string(testSetStatus))
 	return testSetStatus, nil
@@ -628,16 +907,18 @@ func (r *Replayer) GetMocks(ctx context.Context, testSetID string, afterTime tim
 	return filtered, unfiltered, err
 }
 
-func (r *Replayer) SetupOrUpdateMocks(ctx context.Context, appID uint64, testSetID string, afterTime, beforeTime time.Time, action MockAction) error {
+// SetupOrUpdateMocks sets the mocks of the time window for the app and returns the filtered ones, which are
+// the mocks recorded along with the test cases of the window.
+func (r *Replayer) SetupOrUpdateMocks(ctx context.Context, appID uint64, testSetID string, afterTime, beforeTime time.Time, action MockAction) ([]*models.Mock, error) {
 
 	if r.config.Test.BasePath != "" {
 		r.logger.Debug("Keploy will not setup or update the mocks when base path is provided", zap.Any("base path", r.config.Test.BasePath))
-		return nil
+		return nil, nil
 	}
 
 	filteredMocks, unfilteredMocks, err := r.GetMocks(ctx, testSetID, afterTime, beforeTime)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if action == Start {
@@ -647,19 +928,21 @@ func (r *Replayer) SetupOrUpdateMocks(ctx context.Context, appID uint64, testSet
 			SQLDelay:       time.Duration(r.config.Test.Delay),
 			FallBackOnMiss: r.config.Test.FallBackOnMiss,
 			Mocking:        r.config.Test.Mocking,
+			StrictMocks:    r.config.Test.StrictMocks,
+			Latency:        r.config.Test.Latency,
 		})
 		if err != nil {
 			utils.LogError(r.logger, err, "failed to mock outgoing")
-			return err
+			return nil, err
 		}
 	}
 
 	err = r.instrumentation.SetMocks(ctx, appID, filteredMocks, unfilteredMocks)
 	if err != nil {
 		utils.LogError(r.logger, err, "failed to set mocks")
-		return err
+		return nil, err
 	}
-	return nil
+	return filteredMocks, nil
 }
 
 func (r *Replayer) GetTestSetStatus(ctx context.Context, testRunID string, testSetID string) (models.TestSetStatus, error) {
@@ -674,13 +957,57 @@ func (r *Replayer) GetTestSetStatus(ctx context.Context, testRunID string, testS
 	return status, nil
 }
 
-func (r *Replayer) compareResp(tc *models.TestCase, actualResponse *models.HTTPResp, testSetID string) (bool, *models.Result) {
+func (r *Replayer) compareResp(tc *models.TestCase, actualResponse *models.HTTPResp, testSetID string, elapsed time.Duration) (bool, *models.Result) {
+
+	noiseConfig := LeftJoinNoise(r.config.Test.GlobalNoise.Global, r.config.Test.GlobalNoise.Testsets[testSetID])
+	expected := tc
+	if tc.AssertionsOnly {
+		// the body of an assertion-only test case is checked by its assertions, it is compared as noise
+		assertionsOnly := *tc
+		assertionsOnly.Noise = map[string][]string{"body": {}}
+		for k, v := range tc.Noise {
+			assertionsOnly.Noise[k] = v
+		}
+		expected = &assertionsOnly
+	}
+	tolerances := LeftJoinTolerances(r.config.Test.GlobalNoise.Tolerances.Global, r.config.Test.GlobalNoise.Tolerances.Testsets[testSetID])
+	tolerances = withTestCaseTolerances(tolerances, tc.Tolerances)
+	// the assertions are evaluated first so that match prints a single verdict for the test case
+	var assertionResults []models.AssertionResult
+	if len(tc.Assertions) > 0 {
+		_, assertionResults = assertResponse(tc.Assertions, actualResponse, elapsed)
+	}
+	return match(expected, actualResponse, noiseConfig, tolerances, assertionResults, r.config.Test.IgnoreOrdering, r.schemaMatch(tc), r.logger)
+}
+
+// compareGrpcResp compares the response of a grpc test case with the recorded one, with the noise of the
+// config and of the test case.
+func (r *Replayer) compareGrpcResp(tc *models.TestCase, actualResponse *models.GrpcResp, testSetID string) (bool, *models.Result) {
+	noiseConfig := LeftJoinNoise(r.config.Test.GlobalNoise.Global, r.config.Test.GlobalNoise.Testsets[testSetID])
+	tolerances := LeftJoinTolerances(r.config.Test.GlobalNoise.Tolerances.Global, r.config.Test.GlobalNoise.Tolerances.Testsets[testSetID])
+	tolerances = withTestCaseTolerances(tolerances, tc.Tolerances)
+	return matchGrpc(tc, actualResponse, noiseConfig, tolerances, r.logger)
+}
 
-	noiseConfig := r.config.Test.GlobalNoise.Global
-	if tsNoise, ok := r.config.Test.GlobalNoise.Testsets[testSetID]; ok {
-		noiseConfig = LeftJoinNoise(r.config.Test.GlobalNoise.Global, tsNoise)
+// schemaMatch tells whether the json response of the test case is compared by its schema only, as selected
+// globally, for the url path prefix of the test case or in the test case itself.
+func (r *Replayer) schemaMatch(tc *models.TestCase) bool {
+	if tc.SchemaMatch || r.config.Test.SchemaMatch {
+		return true
+	}
+	if len(r.config.Test.SchemaMatchPaths) == 0 {
+		return false
+	}
+	u, err := url.Parse(tc.HTTPReq.URL)
+	if err != nil {
+		return false
 	}
-	return match(tc, actualResponse, noiseConfig, r.config.Test.IgnoreOrdering, r.logger)
+	for _, prefix := range r.config.Test.SchemaMatchPaths {
+		if strings.HasPrefix(u.Path, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 func (r *Replayer) printSummary(ctx context.Context, testRunResult bool) {
@@ -765,6 +1092,9 @@ func (r *Replayer) DenoiseTestCases(ctx context.Context, testSetID string, noise
 
 				// append the noise map
 				if noiseParam.Ops == string(models.OpsAdd) {
+					if v.Noise == nil {
+						v.Noise = map[string][]string{}
+					}
 					v.Noise = mergeMaps(v.Noise, noiseParam.Assertion)
 				} else {
 					// remove from the original noise map
@@ -858,10 +1188,14 @@ func (r *Replayer) NormalizeTestCases(ctx context.Context, testRun string, testS
 			r.logger.Info("test case not found in the test report", zap.String("test-case-id", testCase.Name), zap.String("test-set-id", testSetID))
 			continue
 		}
-		if testCaseResultMap[testCase.Name].Status == models.TestStatusPassed {
+		if status := testCaseResultMap[testCase.Name].Status; status == models.TestStatusPassed || status == models.TestStatusFlaky {
 			continue
 		}
-		testCase.HTTPResp = testCaseResultMap[testCase.Name].Res
+		if testCase.Kind == models.GRPC_EXPORT {
+			testCase.GrpcResp = testCaseResultMap[testCase.Name].GrpcRes
+		} else {
+			testCase.HTTPResp = testCaseResultMap[testCase.Name].Res
+		}
 		err = r.testDB.UpdateTestCase(ctx, testCase, testSetID)
 		if err != nil {
 			return fmt.Errorf("failed to update test case: %w", err)
@@ -0,0 +1,55 @@
+//go:build linux
+
+package replay
+
+import (
+	"maps"
+	"os"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// secretEnvPrefix prefixes the env variables holding the values of the request headers redacted at record time
+const secretEnvPrefix = "KEPLOY_SECRET_"
+
+// secretEnv returns the env variable holding the value of the header, e.g. KEPLOY_SECRET_PROXY_AUTHORIZATION
+// for Proxy-Authorization.
+func secretEnv(header string) string {
+	return secretEnvPrefix + strings.ToUpper(strings.ReplaceAll(header, "-", "_"))
+}
+
+// withSecrets returns the test case to send to the app. The request headers redacted at record time, like the
+// credentials, are set to the values of their env variables so that the authenticated endpoints can be
+// replayed. The test case is copied as the secrets must not end up in the reports.
+func (r *Replayer) withSecrets(tc *models.TestCase) *models.TestCase {
+	if tc.Kind != models.HTTP {
+		return tc
+	}
+	var header map[string]string
+	for k, v := range tc.HTTPReq.Header {
+		if !pkg.IsRedacted(v) {
+			continue
+		}
+		env := secretEnv(k)
+		secret, ok := os.LookupEnv(env)
+		if !ok {
+			if _, warned := r.missingSecrets.LoadOrStore(env, true); !warned {
+				r.logger.Warn("the request header was redacted at record time and is sent with its placeholder, set the env variable to send its value", zap.String("header", k), zap.String("env", env))
+			}
+			continue
+		}
+		if header == nil {
+			header = maps.Clone(tc.HTTPReq.Header)
+		}
+		header[k] = secret
+	}
+	if header == nil {
+		return tc
+	}
+	withSecrets := *tc
+	withSecrets.HTTPReq.Header = header
+	return &withSecrets
+}
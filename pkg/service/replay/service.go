@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/coverage"
 )
 
 type Instrumentation interface {
@@ -17,6 +18,12 @@ type Instrumentation interface {
 	SetMocks(ctx context.Context, id uint64, filtered []*models.Mock, unFiltered []*models.Mock) error
 	// GetConsumedMocks to log the names of the mocks that were consumed during the test run of failed test cases
 	GetConsumedMocks(ctx context.Context, id uint64) ([]string, error)
+	// GetUnmatchedCalls to check the outgoing calls of the test cases which couldn't be served from the mocks
+	GetUnmatchedCalls(ctx context.Context, id uint64) ([]models.UnmatchedCall, error)
+	// SetFaults sets the faults of the chaos config of the test set which are injected into the mocks
+	SetFaults(ctx context.Context, id uint64, faults []models.Fault) error
+	// GetInjectedFaults to report the faults injected into the mocks of the test cases
+	GetInjectedFaults(ctx context.Context, id uint64) ([]models.InjectedFault, error)
 	// Run is blocking call and will execute until error
 	Run(ctx context.Context, id uint64, opts models.RunOptions) models.AppError
 
@@ -32,6 +39,7 @@ type Service interface {
 	GetTestSetStatus(ctx context.Context, testRunID string, testSetID string) (models.TestSetStatus, error)
 	RunApplication(ctx context.Context, appID uint64, opts models.RunOptions) models.AppError
 	Normalize(ctx context.Context) error
+	Denoise(ctx context.Context) error
 	DenoiseTestCases(ctx context.Context, testSetID string, noiseParams []models.NoiseParams) error
 	NormalizeTestCases(ctx context.Context, testRun string, testSetID string, selectedTestCaseIDs []string, testResult []models.TestResult) error
 }
@@ -56,6 +64,13 @@ type ReportDB interface {
 	InsertReport(ctx context.Context, testRunID string, testSetID string, testReport *models.TestReport) error
 }
 
+// CoverageDB stores the code covered by each test case, it is captured for the go apps with go coverage enabled
+// and read to run only the test cases affected by the changes
+type CoverageDB interface {
+	coverage.CoverageDB
+	InsertCoverage(ctx context.Context, testRunID string, testSetID string, coverage *models.TestCaseCoverage) error
+}
+
 type Config interface {
 	Read(ctx context.Context, testSetID string) (*models.TestSet, error)
 	Write(ctx context.Context, testSetID string, testSet *models.TestSet) error
@@ -73,6 +88,7 @@ type Telemetry interface {
 // test status processing, and post-test actions.
 type RequestMockHandler interface {
 	SimulateRequest(ctx context.Context, appID uint64, tc *models.TestCase, testSetID string) (*models.HTTPResp, error)
+	SimulateGrpcRequest(ctx context.Context, appID uint64, tc *models.TestCase, testSetID string) (*models.GrpcResp, error)
 	ProcessTestRunStatus(ctx context.Context, status bool, testSetID string)
 	FetchMockName() string
 	ProcessMockFile(ctx context.Context, testSetID string)
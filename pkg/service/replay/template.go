@@ -0,0 +1,146 @@
+//go:build linux
+
+package replay
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// placeholderRegex matches the {{ name }} placeholders in the recorded requests
+var placeholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.\-]*)\s*\}\}`)
+
+// envPrefix selects an environment variable in a placeholder, e.g. {{ env.API_KEY }}
+const envPrefix = "env."
+
+// templateVars resolves the placeholders in the requests of a test set. The values come from the template
+// of the test set config, from the environment and from the responses of the earlier test cases, the
+// extracted values take precedence over the template.
+type templateVars struct {
+	logger      *zap.Logger
+	values      map[string]string
+	extractions []extraction
+}
+
+type extraction struct {
+	models.Extraction
+	regex *regexp.Regexp
+}
+
+func newTemplateVars(logger *zap.Logger, conf *models.TestSet) (*templateVars, error) {
+	t := &templateVars{logger: logger, values: map[string]string{}}
+	if conf == nil {
+		return t, nil
+	}
+	for k, v := range conf.Template {
+		t.values[k] = v
+	}
+	for _, e := range conf.Extract {
+		if e.Name == "" || e.Path == "" {
+			return nil, fmt.Errorf("extraction rules need a name and a path, found name %q and path %q", e.Name, e.Path)
+		}
+		ex := extraction{Extraction: e}
+		if e.Regex != "" {
+			re, err := regexp.Compile(e.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex of the extraction rule %s: %v", e.Name, err)
+			}
+			ex.regex = re
+		}
+		t.extractions = append(t.extractions, ex)
+	}
+	return t, nil
+}
+
+// apply resolves the placeholders in the url, the headers and the body of the request of the test case.
+// Placeholders which cannot be resolved are left as they are.
+func (t *templateVars) apply(tc *models.TestCase) {
+	unresolved := map[string]bool{}
+	resolve := func(s string) string {
+		if !strings.Contains(s, "{{") {
+			return s
+		}
+		return placeholderRegex.ReplaceAllStringFunc(s, func(placeholder string) string {
+			name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+			if value, ok := t.lookup(name); ok {
+				return value
+			}
+			unresolved[name] = true
+			return placeholder
+		})
+	}
+
+	tc.HTTPReq.URL = resolve(tc.HTTPReq.URL)
+	for k, v := range tc.HTTPReq.URLParams {
+		tc.HTTPReq.URLParams[k] = resolve(v)
+	}
+	for k, v := range tc.HTTPReq.Header {
+		tc.HTTPReq.Header[k] = resolve(v)
+	}
+	tc.HTTPReq.Body = resolve(tc.HTTPReq.Body)
+
+	if len(unresolved) > 0 {
+		names := make([]string, 0, len(unresolved))
+		for name := range unresolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.logger.Warn("placeholders of the request could not be resolved", zap.String("testcase", tc.Name), zap.Strings("placeholders", names))
+	}
+}
+
+func (t *templateVars) lookup(name string) (string, bool) {
+	if env, ok := strings.CutPrefix(name, envPrefix); ok {
+		return os.LookupEnv(env)
+	}
+	value, ok := t.values[name]
+	return value, ok
+}
+
+// extract captures the values declared for the test case from its actual response.
+func (t *templateVars) extract(testCase string, resp *models.HTTPResp) {
+	if resp == nil {
+		return
+	}
+	for _, e := range t.extractions {
+		if e.TestCase != "" && e.TestCase != testCase {
+			continue
+		}
+		value, ok := responseValue(resp, e.Path)
+		if ok && e.regex != nil {
+			m := e.regex.FindStringSubmatch(value)
+			switch {
+			case m == nil:
+				ok = false
+			case len(m) > 1:
+				value = m[1]
+			default:
+				value = m[0]
+			}
+		}
+		if !ok {
+			// rules without a test case are tried on every response, missing values are expected
+			if e.TestCase != "" {
+				t.logger.Warn("value to be extracted not found in the response", zap.String("testcase", testCase), zap.String("name", e.Name), zap.String("path", e.Path))
+			}
+			continue
+		}
+		t.logger.Debug("extracted value from the response", zap.String("testcase", testCase), zap.String("name", e.Name))
+		t.values[e.Name] = value
+	}
+}
+
+// responseValue returns the value at the path (body.<json path>, header.<name> or status) of the response.
+func responseValue(resp *models.HTTPResp, path string) (string, bool) {
+	value, ok := responseField(resp, path)
+	if !ok || value == nil {
+		return "", false
+	}
+	return fieldString(value), true
+}
@@ -0,0 +1,117 @@
+//go:build linux
+
+package replay
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+)
+
+// withinTolerance tells whether the actual value is within any of the tolerances of the expected value. The
+// tolerances with a window compare timestamps, the other ones compare numbers.
+func withinTolerance(expected, actual interface{}, tolerances []config.Tolerance) bool {
+	for _, t := range tolerances {
+		if t.Window > 0 || t.FromNow {
+			if withinWindow(t, expected, actual) {
+				return true
+			}
+			continue
+		}
+		exp, ok1 := toleranceNumber(expected)
+		act, ok2 := toleranceNumber(actual)
+		if !ok1 || !ok2 {
+			continue
+		}
+		diff := math.Abs(act - exp)
+		if t.Percent > 0 {
+			if diff <= math.Abs(exp)*t.Percent/100 {
+				return true
+			}
+			continue
+		}
+		if diff <= math.Abs(t.Delta) {
+			return true
+		}
+	}
+	return false
+}
+
+func withinWindow(t config.Tolerance, expected, actual interface{}) bool {
+	act, ok := toleranceTime(actual)
+	if !ok {
+		return false
+	}
+	ref := time.Now()
+	if !t.FromNow {
+		if ref, ok = toleranceTime(expected); !ok {
+			return false
+		}
+	}
+	d := act.Sub(ref)
+	if d < 0 {
+		d = -d
+	}
+	return d <= t.Window
+}
+
+func toleranceNumber(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toleranceTime(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	return pkg.ParseTime(s)
+}
+
+// withTestCaseTolerances adds the tolerances of the test case to the joined tolerances of the config, the
+// fields of the test case are split into the body and the header ones like its noise.
+func withTestCaseTolerances(tolerances config.GlobalTolerances, tcTolerances map[string][]config.Tolerance) config.GlobalTolerances {
+	for field, t := range tcTolerances {
+		a := strings.Split(field, ".")
+		part, key := "", ""
+		if len(a) > 1 && a[0] == "body" {
+			part, key = "body", strings.Join(a[1:], ".")
+		} else if a[0] == "header" {
+			part, key = "header", a[len(a)-1]
+		} else {
+			continue
+		}
+		if tolerances[part] == nil {
+			tolerances[part] = map[string][]config.Tolerance{}
+		}
+		tolerances[part][key] = t
+	}
+	return tolerances
+}
+
+// LeftJoinTolerances returns the global tolerances overridden by the tolerances of the test set, in a new map
+// like LeftJoinNoise.
+func LeftJoinTolerances(global config.GlobalTolerances, ts config.GlobalTolerances) config.GlobalTolerances {
+	tolerances := config.GlobalTolerances{}
+	for _, joined := range []config.GlobalTolerances{global, ts} {
+		for part, fields := range joined {
+			if tolerances[part] == nil {
+				tolerances[part] = map[string][]config.Tolerance{}
+			}
+			for field, t := range fields {
+				tolerances[part][field] = t
+			}
+		}
+	}
+	return tolerances
+}
@@ -0,0 +1,71 @@
+//go:build linux
+
+package replay
+
+import (
+	"testing"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+)
+
+func TestWithinTolerance(t *testing.T) {
+	tests := []struct {
+		name       string
+		expected   interface{}
+		actual     interface{}
+		tolerances []config.Tolerance
+		want       bool
+	}{
+		{"no tolerance", 10.0, 10.0, nil, false},
+		{"within delta", 10.0, 10.4, []config.Tolerance{{Delta: 0.5}}, true},
+		{"on the delta", 10.0, 9.5, []config.Tolerance{{Delta: 0.5}}, true},
+		{"beyond delta", 10.0, 10.6, []config.Tolerance{{Delta: 0.5}}, false},
+		{"negative delta", 10.0, 10.4, []config.Tolerance{{Delta: -0.5}}, true},
+		{"within percent", 200.0, 209.0, []config.Tolerance{{Percent: 5}}, true},
+		{"beyond percent", 200.0, 211.0, []config.Tolerance{{Percent: 5}}, false},
+		{"percent of a negative value", -200.0, -209.0, []config.Tolerance{{Percent: 5}}, true},
+		{"percent takes over delta", 100.0, 104.0, []config.Tolerance{{Delta: 10, Percent: 1}}, false},
+		{"numeric strings", "10", " 10.3 ", []config.Tolerance{{Delta: 0.5}}, true},
+		{"not a number", "ten", "10", []config.Tolerance{{Delta: 0.5}}, false},
+		{"any tolerance matches", 10.0, 12.0, []config.Tolerance{{Delta: 1}, {Percent: 25}}, true},
+		{"within window", "2024-01-02T15:04:05Z", "2024-01-02T15:04:30Z", []config.Tolerance{{Window: time.Minute}}, true},
+		{"before within window", "2024-01-02T15:04:05Z", "2024-01-02T15:03:30Z", []config.Tolerance{{Window: time.Minute}}, true},
+		{"beyond window", "2024-01-02T15:04:05Z", "2024-01-02T15:06:05Z", []config.Tolerance{{Window: time.Minute}}, false},
+		{"window of numbers", 10.0, 10.0, []config.Tolerance{{Window: time.Minute}}, false},
+		{"window of a second", "2024-01-02T15:04:05Z", "2024-01-02T15:04:06Z", []config.Tolerance{{Window: time.Second}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withinTolerance(tt.expected, tt.actual, tt.tolerances); got != tt.want {
+				t.Errorf("withinTolerance(%v, %v, %+v) = %v, want %v", tt.expected, tt.actual, tt.tolerances, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithinWindow(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name      string
+		tolerance config.Tolerance
+		expected  interface{}
+		actual    interface{}
+		want      bool
+	}{
+		{"around the expected value", config.Tolerance{Window: time.Hour}, "2024-01-02T15:04:05Z", "2024-01-02T15:34:05Z", true},
+		{"another timezone", config.Tolerance{Window: time.Minute}, "2024-01-02T15:04:05Z", "2024-01-02T16:04:05+01:00", true},
+		{"expected is not a time", config.Tolerance{Window: time.Hour}, "yesterday", "2024-01-02T15:04:05Z", false},
+		{"actual is not a time", config.Tolerance{Window: time.Hour}, "2024-01-02T15:04:05Z", 42.0, false},
+		{"from now", config.Tolerance{Window: time.Minute, FromNow: true}, "2001-01-01T00:00:00Z", now.Add(-10 * time.Second).Format(time.RFC3339), true},
+		{"from now ignores the expected value", config.Tolerance{Window: time.Minute, FromNow: true}, "not a time", now.Format(time.RFC3339), true},
+		{"too far from now", config.Tolerance{Window: time.Minute, FromNow: true}, "2001-01-01T00:00:00Z", "2001-01-01T00:00:00Z", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withinWindow(tt.tolerance, tt.expected, tt.actual); got != tt.want {
+				t.Errorf("withinWindow(%+v, %v, %v) = %v, want %v", tt.tolerance, tt.expected, tt.actual, got, tt.want)
+			}
+		})
+	}
+}
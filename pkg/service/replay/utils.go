@@ -5,7 +5,9 @@ package replay
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
@@ -20,8 +22,16 @@ type TestReportVerdict struct {
 	status bool
 }
 
+// LeftJoinNoise returns the global noise overridden by the noise of the test set. The result is a new map, the
+// noise of the config is shared by the test sets run in parallel and is never modified.
 func LeftJoinNoise(globalNoise config.GlobalNoise, tsNoise config.GlobalNoise) config.GlobalNoise {
-	noise := globalNoise
+	noise := config.GlobalNoise{}
+	for part, fields := range globalNoise {
+		noise[part] = make(map[string][]string, len(fields))
+		for field, regexArr := range fields {
+			noise[part][field] = regexArr
+		}
+	}
 
 	if _, ok := noise["body"]; !ok {
 		noise["body"] = make(map[string][]string)
@@ -76,6 +86,27 @@ func ReplaceBaseURL(newURL, oldURL string) (string, error) {
 	return replacedURL, nil
 }
 
+// ShiftPort adds the offset to the port of the URL, the default port of the scheme is shifted when the URL
+// has no port.
+func ShiftPort(rawURL string, offset int) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the URL: %v", err)
+	}
+	port := 80
+	if parsedURL.Scheme == "https" {
+		port = 443
+	}
+	if parsedURL.Port() != "" {
+		port, err = strconv.Atoi(parsedURL.Port())
+		if err != nil {
+			return "", fmt.Errorf("invalid port in the URL: %v", err)
+		}
+	}
+	parsedURL.Host = net.JoinHostPort(parsedURL.Hostname(), strconv.Itoa(port+offset))
+	return parsedURL.String(), nil
+}
+
 type requestMockUtil struct {
 	logger     *zap.Logger
 	path       string
@@ -104,6 +135,14 @@ func (t *requestMockUtil) SimulateRequest(ctx context.Context, _ uint64, tc *mod
 	return nil, nil
 }
 
+// SimulateGrpcRequest sends the request of a grpc test case to the app.
+func (t *requestMockUtil) SimulateGrpcRequest(ctx context.Context, _ uint64, tc *models.TestCase, testSetID string) (*models.GrpcResp, error) {
+	t.logger.Debug("Before simulating the grpc request", zap.Any("Test case", tc))
+	resp, err := pkg.SimulateGRPC(ctx, *tc, testSetID, t.logger, t.apiTimeout)
+	t.logger.Debug("After simulating the grpc request", zap.Any("test case id", tc.Name))
+	return resp, err
+}
+
 func (t *requestMockUtil) AfterTestHook(_ context.Context, testRunID, testSetID string, tsCnt int) (*models.TestReport, error) {
 	t.logger.Debug("AfterTestHook", zap.Any("testRunID", testRunID), zap.Any("testSetID", testSetID), zap.Any("totalTestSetCount", tsCnt))
 	return nil, nil
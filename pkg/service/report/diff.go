@@ -0,0 +1,217 @@
+package report
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// DiffStatus is the classification of a test case when two test runs are compared.
+type DiffStatus string
+
+// constants for the diff status of a test case
+const (
+	DiffNewlyFailing  DiffStatus = "NEWLY_FAILING"
+	DiffNewlyPassing  DiffStatus = "NEWLY_PASSING"
+	DiffFailureChange DiffStatus = "FAILURE_CHANGED" // still failing but with a different diff
+	DiffAdded         DiffStatus = "ADDED"
+	DiffRemoved       DiffStatus = "REMOVED"
+)
+
+// RunDiff holds the differences between the results of two test runs.
+type RunDiff struct {
+	Base    string      `json:"base"`
+	Head    string      `json:"head"`
+	Summary DiffSummary `json:"summary"`
+	Tests   []TestDiff  `json:"tests"`
+}
+
+type DiffSummary struct {
+	NewlyFailing   int `json:"newlyFailing"`
+	NewlyPassing   int `json:"newlyPassing"`
+	FailureChanged int `json:"failureChanged"`
+	Added          int `json:"added"`
+	Removed        int `json:"removed"`
+	Unchanged      int `json:"unchanged"`
+}
+
+// TestDiff is a test case whose result differs between the two test runs.
+type TestDiff struct {
+	TestSet    string            `json:"testSet"`
+	TestCaseID string            `json:"testCaseID"`
+	Status     DiffStatus        `json:"status"`
+	BaseStatus models.TestStatus `json:"baseStatus,omitempty"`
+	HeadStatus models.TestStatus `json:"headStatus,omitempty"`
+	Failure    *Failure          `json:"failure,omitempty"`
+}
+
+// HasRegressions reports whether any test case which passed in the base run fails in the head run.
+func (d *RunDiff) HasRegressions() bool {
+	return d.Summary.NewlyFailing > 0
+}
+
+// WriteSummary writes the human readable summary of the diff.
+func (d *RunDiff) WriteSummary(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	fmt.Fprintf(&sb, " COMPARISON OF %s WITH %s\n", d.Head, d.Base)
+	fmt.Fprintf(&sb, "\tnewly failing: %d\n", d.Summary.NewlyFailing)
+	fmt.Fprintf(&sb, "\tnewly passing: %d\n", d.Summary.NewlyPassing)
+	fmt.Fprintf(&sb, "\tfailing with a different diff: %d\n", d.Summary.FailureChanged)
+	fmt.Fprintf(&sb, "\tadded: %d\n", d.Summary.Added)
+	fmt.Fprintf(&sb, "\tremoved: %d\n", d.Summary.Removed)
+	fmt.Fprintf(&sb, "\tunchanged: %d\n", d.Summary.Unchanged)
+	if len(d.Tests) > 0 {
+		sb.WriteString("\n")
+		tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "\tTest Set\tTest Case\tStatus\tBase\tHead\n")
+		for _, t := range d.Tests {
+			fmt.Fprintf(tw, "\t%s\t%s\t%s\t%s\t%s\n", t.TestSet, t.TestCaseID, t.Status, statusOrNone(t.BaseStatus), statusOrNone(t.HeadStatus))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r *Report) Diff(ctx context.Context, baseRunID, headRunID string) (*RunDiff, error) {
+	if baseRunID == headRunID {
+		return nil, fmt.Errorf("cannot compare the test run %s with itself", baseRunID)
+	}
+	base, err := r.getRunResults(ctx, baseRunID)
+	if err != nil {
+		return nil, err
+	}
+	head, err := r.getRunResults(ctx, headRunID)
+	if err != nil {
+		return nil, err
+	}
+	diff := compareRuns(baseRunID, headRunID, base, head)
+
+	dir := filepath.Join(r.reportPath, headRunID)
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to create the report directory", zap.String("path", dir))
+		return nil, err
+	}
+	path := filepath.Join(dir, "diff-"+baseRunID+".json")
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(path, data, 0777)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to write the comparison of the test runs", zap.String("path", path))
+		return nil, err
+	}
+	r.logger.Info("written the comparison of the test runs", zap.String("path", path))
+	return diff, nil
+}
+
+// compareRuns classifies the test cases of the head run against the base run. Test cases are
+// identified by their test set and test case id.
+func compareRuns(baseRunID, headRunID string, base, head map[testKey]models.TestResult) *RunDiff {
+	diff := &RunDiff{Base: baseRunID, Head: headRunID, Tests: []TestDiff{}}
+	for key, h := range head {
+		b, ok := base[key]
+		if !ok {
+			diff.Summary.Added++
+			diff.Tests = append(diff.Tests, TestDiff{
+				TestSet:    key.testSet,
+				TestCaseID: key.testCaseID,
+				Status:     DiffAdded,
+				HeadStatus: h.Status,
+				Failure:    GetFailure(h),
+			})
+			continue
+		}
+		t := TestDiff{
+			TestSet:    key.testSet,
+			TestCaseID: key.testCaseID,
+			BaseStatus: b.Status,
+			HeadStatus: h.Status,
+		}
+		baseFailed := b.Status == models.TestStatusFailed
+		headFailed := h.Status == models.TestStatusFailed
+		switch {
+		case !baseFailed && headFailed:
+			t.Status = DiffNewlyFailing
+			t.Failure = GetFailure(h)
+			diff.Summary.NewlyFailing++
+		case baseFailed && !headFailed:
+			t.Status = DiffNewlyPassing
+			diff.Summary.NewlyPassing++
+		case baseFailed && headFailed && GetFailure(b).String() != GetFailure(h).String():
+			t.Status = DiffFailureChange
+			t.Failure = GetFailure(h)
+			diff.Summary.FailureChanged++
+		default:
+			diff.Summary.Unchanged++
+			continue
+		}
+		diff.Tests = append(diff.Tests, t)
+	}
+	for key, b := range base {
+		if _, ok := head[key]; ok {
+			continue
+		}
+		diff.Summary.Removed++
+		diff.Tests = append(diff.Tests, TestDiff{
+			TestSet:    key.testSet,
+			TestCaseID: key.testCaseID,
+			Status:     DiffRemoved,
+			BaseStatus: b.Status,
+		})
+	}
+	sort.Slice(diff.Tests, func(i, j int) bool {
+		if diff.Tests[i].TestSet != diff.Tests[j].TestSet {
+			return diff.Tests[i].TestSet < diff.Tests[j].TestSet
+		}
+		return diff.Tests[i].TestCaseID < diff.Tests[j].TestCaseID
+	})
+	return diff
+}
+
+type testKey struct {
+	testSet    string
+	testCaseID string
+}
+
+// getRunResults returns the test case results of the test run keyed by their test set and test case id.
+func (r *Report) getRunResults(ctx context.Context, testRunID string) (map[testKey]models.TestResult, error) {
+	testRunID, err := r.getTestRunID(ctx, testRunID)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := r.getReports(ctx, testRunID)
+	if err != nil {
+		return nil, err
+	}
+	results := map[testKey]models.TestResult{}
+	for _, report := range reports {
+		for _, t := range report.Tests {
+			results[testKey{testSet: report.TestSet, testCaseID: t.TestCaseID}] = t
+		}
+	}
+	return results, nil
+}
+
+func statusOrNone(status models.TestStatus) string {
+	if status == "" {
+		return "-"
+	}
+	return string(status)
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// exporters holds the registered exporters by their format
+var exporters = map[string]Exporter{}
+
+// RegisterExporter makes the exporter available to `keploy test --report-format` and `keploy report export`.
+func RegisterExporter(e Exporter) {
+	exporters[e.Format()] = e
+}
+
+// Formats returns the names of the registered exporters.
+func Formats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// ValidateFormats checks that every given format has a registered exporter.
+func ValidateFormats(formats []string) error {
+	for _, format := range formats {
+		if _, ok := exporters[strings.TrimSpace(format)]; !ok {
+			return fmt.Errorf("unsupported report format: %s, supported formats are %s", format, strings.Join(Formats(), ", "))
+		}
+	}
+	return nil
+}
+
+// WriteReports exports the reports of the test run into dir, one file per format.
+func WriteReports(ctx context.Context, logger *zap.Logger, dir string, testRunID string, reports []*models.TestReport, formats []string) error {
+	err := ValidateFormats(formats)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		utils.LogError(logger, err, "failed to create the report directory", zap.String("path", dir))
+		return err
+	}
+	for _, format := range formats {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		exporter := exporters[strings.TrimSpace(format)]
+		path := filepath.Join(dir, exporter.FileName())
+		err := writeReport(path, exporter, testRunID, reports)
+		if err != nil {
+			utils.LogError(logger, err, "failed to export the report", zap.String("format", exporter.Format()), zap.String("path", path))
+			return err
+		}
+		logger.Info("exported the test run report", zap.String("format", exporter.Format()), zap.String("path", path))
+	}
+	return nil
+}
+
+func writeReport(path string, exporter Exporter, testRunID string, reports []*models.TestReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = exporter.Export(file, testRunID, reports)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
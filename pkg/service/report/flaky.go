@@ -0,0 +1,165 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// FlakyReport lists the test cases whose result is unstable across the inspected test runs.
+type FlakyReport struct {
+	TestRuns []string    `json:"testRuns"`
+	Tests    []FlakyTest `json:"tests"`
+}
+
+// FlakyTest is a test case which flips between passing and failing across the test runs, or which passed
+// only on a retry in some of them.
+type FlakyTest struct {
+	TestSet    string `json:"testSet"`
+	TestCaseID string `json:"testCaseID"`
+	Runs       int    `json:"runs"`
+	Passed     int    `json:"passed"`
+	Failed     int    `json:"failed"`
+	Flaky      int    `json:"flaky"`
+	// Flips is the number of times the result changed between two consecutive runs of the test case
+	Flips      int               `json:"flips"`
+	LastStatus models.TestStatus `json:"lastStatus"`
+	// History has one character per test run, oldest first: P passed, F failed, ~ flaky, - not run
+	History string `json:"history"`
+}
+
+// WriteSummary writes the human readable list of the flaky test cases.
+func (f *FlakyReport) WriteSummary(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	fmt.Fprintf(&sb, " FLAKY TESTS OF THE LAST %d TEST RUNS\n", len(f.TestRuns))
+	if len(f.TestRuns) > 0 {
+		fmt.Fprintf(&sb, "\tfrom %s to %s\n", f.TestRuns[0], f.TestRuns[len(f.TestRuns)-1])
+	}
+	fmt.Fprintf(&sb, "\tflaky tests: %d\n", len(f.Tests))
+	if len(f.Tests) > 0 {
+		sb.WriteString("\n")
+		tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "\tTest Set\tTest Case\tFlips\tPassed\tFailed\tFlaky\tLast\tHistory\n")
+		for _, t := range f.Tests {
+			fmt.Fprintf(tw, "\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\n", t.TestSet, t.TestCaseID, t.Flips, t.Passed, t.Failed, t.Flaky, t.LastStatus, t.History)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(&sb, "<=========================================>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (r *Report) Flaky(ctx context.Context, runs int) (*FlakyReport, error) {
+	testRunIDs, err := r.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(testRunIDs) == 0 {
+		return nil, fmt.Errorf("no test runs found, please run the testcases using keploy test command")
+	}
+	SortTestRuns(testRunIDs)
+	if runs > 0 && len(testRunIDs) > runs {
+		testRunIDs = testRunIDs[len(testRunIDs)-runs:]
+	}
+
+	history := map[testKey][]models.TestStatus{}
+	var keys []testKey
+	for i, testRunID := range testRunIDs {
+		reports, err := r.getReports(ctx, testRunID)
+		if err != nil {
+			return nil, err
+		}
+		for _, report := range reports {
+			for _, result := range report.Tests {
+				key := testKey{testSet: report.TestSet, testCaseID: result.TestCaseID}
+				statuses, ok := history[key]
+				if !ok {
+					statuses = make([]models.TestStatus, len(testRunIDs))
+					keys = append(keys, key)
+				}
+				statuses[i] = result.Status
+				history[key] = statuses
+			}
+		}
+	}
+
+	report := &FlakyReport{TestRuns: testRunIDs, Tests: []FlakyTest{}}
+	for _, key := range keys {
+		t := flakyTest(key, history[key])
+		if t.Flips > 0 || t.Flaky > 0 {
+			report.Tests = append(report.Tests, t)
+		}
+	}
+	sort.Slice(report.Tests, func(i, j int) bool {
+		a, b := report.Tests[i], report.Tests[j]
+		if a.Flips+a.Flaky != b.Flips+b.Flaky {
+			return a.Flips+a.Flaky > b.Flips+b.Flaky
+		}
+		if a.TestSet != b.TestSet {
+			return a.TestSet < b.TestSet
+		}
+		return a.TestCaseID < b.TestCaseID
+	})
+	return report, nil
+}
+
+// flakyTest summarises the statuses of the test case in the test runs, an empty status means that the test
+// case was not run.
+func flakyTest(key testKey, statuses []models.TestStatus) FlakyTest {
+	t := FlakyTest{TestSet: key.testSet, TestCaseID: key.testCaseID}
+	var history strings.Builder
+	var last models.TestStatus
+	for _, status := range statuses {
+		switch status {
+		case models.TestStatusPassed:
+			t.Passed++
+			history.WriteString("P")
+		case models.TestStatusFailed:
+			t.Failed++
+			history.WriteString("F")
+		case models.TestStatusFlaky:
+			t.Flaky++
+			history.WriteString("~")
+		default:
+			history.WriteString("-")
+			continue
+		}
+		t.Runs++
+		// a flaky result passed in the end, it flips the same way as a passed one
+		if last != "" && (last == models.TestStatusFailed) != (status == models.TestStatusFailed) {
+			t.Flips++
+		}
+		last = status
+	}
+	t.LastStatus = last
+	t.History = history.String()
+	return t
+}
+
+// SortTestRuns orders the test runs by their number, oldest first.
+func SortTestRuns(testRunIDs []string) {
+	number := func(id string) int {
+		n, err := strconv.Atoi(id[strings.LastIndex(id, "-")+1:])
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+	sort.SliceStable(testRunIDs, func(i, j int) bool {
+		ni, nj := number(testRunIDs[i]), number(testRunIDs[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return testRunIDs[i] < testRunIDs[j]
+	})
+}
@@ -0,0 +1,294 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+func init() {
+	RegisterExporter(&HTML{})
+}
+
+// HTML exports the test run as a single self-contained html page which can be opened offline.
+type HTML struct{}
+
+type htmlReport struct {
+	TestRunID string
+	Status    string
+	Generated string
+	Summary   JSONSummary
+	TestSets  []htmlTestSet
+}
+
+type htmlTestSet struct {
+	Name    string
+	Status  string
+	Summary JSONSummary
+	Tests   []htmlTest
+}
+
+type htmlTest struct {
+	Name          string
+	Status        string
+	Duration      string
+	Request       models.HTTPReq
+	Response      models.HTTPResp
+	ReqHeaders    []headerLine
+	ResHeaders    []headerLine
+	Failure       *Failure
+	BodyDiffs     []htmlBodyDiff
+	ConsumedMocks []string
+	MockPath      string
+	Faults        []models.InjectedFault
+}
+
+type headerLine struct {
+	Key   string
+	Value string
+}
+
+type htmlBodyDiff struct {
+	Type models.BodyType
+	Rows []DiffRow
+}
+
+// DiffRow is a line of the side-by-side diff, Kind is one of same, changed, removed, added and noise.
+type DiffRow struct {
+	Expected string
+	Actual   string
+	Kind     string
+}
+
+func (h *HTML) Format() string {
+	return "html"
+}
+
+func (h *HTML) FileName() string {
+	return "report.html"
+}
+
+func (h *HTML) Export(w io.Writer, testRunID string, reports []*models.TestReport) error {
+	page := htmlReport{
+		TestRunID: testRunID,
+		Status:    testRunStatus(reports),
+		Generated: time.Now().UTC().Format(time.RFC1123),
+	}
+	for _, r := range reports {
+		ts := htmlTestSet{
+			Name:   r.TestSet,
+			Status: r.Status,
+			Summary: JSONSummary{
+				Total:  r.Total,
+				Passed: r.Success,
+				Failed: r.Failure,
+				Flaky:  len(r.Flaky),
+			},
+		}
+		for _, t := range r.Tests {
+			test := htmlTest{
+				Name:       t.TestCaseID,
+				Status:     string(t.Status),
+				Duration:   fmt.Sprintf("%ds", t.Completed-t.Started),
+				Request:    t.Req,
+				Response:   t.Res,
+				ReqHeaders: sortedHeaders(t.Req.Header),
+				ResHeaders: sortedHeaders(t.Res.Header),
+				Failure:    GetFailure(t),
+				MockPath:   t.MockPath,
+				Faults:     t.Faults,
+			}
+			if test.Failure != nil {
+				test.ConsumedMocks = t.ConsumedMocks
+				noise := bodyNoise(t.Noise)
+				for _, b := range test.Failure.Body {
+					test.BodyDiffs = append(test.BodyDiffs, htmlBodyDiff{
+						Type: b.Type,
+						Rows: SideBySideDiff(b.Expected, b.Actual, noise),
+					})
+				}
+			}
+			ts.Tests = append(ts.Tests, test)
+		}
+		page.Summary.Total += r.Total
+		page.Summary.Passed += r.Success
+		page.Summary.Failed += r.Failure
+		page.Summary.Flaky += len(r.Flaky)
+		page.TestSets = append(page.TestSets, ts)
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+// SideBySideDiff returns the rows of the side-by-side diff of the expected and the actual body. Json bodies
+// are compared with pkg.CalculateJSONDiffs and split in the same way as the diff printed by replay.
+func SideBySideDiff(expected, actual string, noise map[string][]string) []DiffRow {
+	if json.Valid([]byte(expected)) && json.Valid([]byte(actual)) {
+		diff, err := pkg.CalculateJSONDiffs([]byte(expected), []byte(actual))
+		if err == nil {
+			return splitJSONDiff(diff, noise)
+		}
+	}
+	return []DiffRow{{Expected: expected, Actual: actual, Kind: "changed"}}
+}
+
+// splitJSONDiff returns the rows of the json diff, split in the same way as the diff printed by replay.
+func splitJSONDiff(diff string, noise map[string][]string) []DiffRow {
+	var rows []DiffRow
+	for _, line := range pkg.SplitJSONDiff(diff, noise) {
+		kind := string(line.Kind)
+		if line.Noisy {
+			kind = "noise"
+		}
+		switch line.Kind {
+		case pkg.DiffSame:
+			rows = append(rows, DiffRow{Expected: line.Expected, Actual: line.Actual, Kind: kind})
+		case pkg.DiffChanged:
+			rows = append(rows, DiffRow{Expected: " " + line.Expected, Actual: " " + line.Actual, Kind: kind})
+		case pkg.DiffRemoved:
+			rows = append(rows, DiffRow{Expected: " " + line.Expected, Kind: kind})
+		case pkg.DiffAdded:
+			rows = append(rows, DiffRow{Actual: " " + line.Actual, Kind: kind})
+		}
+	}
+	return rows
+}
+
+// bodyNoise returns the noisy body fields of the test case without the "body." prefix
+func bodyNoise(noise models.Noise) map[string][]string {
+	res := map[string][]string{}
+	for field, regexArr := range noise {
+		if strings.HasPrefix(field, "body.") {
+			res[strings.TrimPrefix(field, "body.")] = regexArr
+		}
+	}
+	return res
+}
+
+func sortedHeaders(h map[string]string) []headerLine {
+	lines := make([]headerLine, 0, len(h))
+	for k, v := range h {
+		lines = append(lines, headerLine{Key: k, Value: v})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Key < lines[j].Key
+	})
+	return lines
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"lower":        strings.ToLower,
+	"join":         strings.Join,
+	"depMessage":   depMessage,
+	"depPayload":   depPayload,
+	"faultMessage": faultMessage,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Keploy report - {{.TestRunID}}</title>
+<style>
+body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Roboto,sans-serif;margin:0;background:#f6f7f9;color:#1f2328}
+header{background:#ff914d;color:#fff;padding:16px 24px}
+header h1{margin:0;font-size:22px}
+main{padding:16px 24px}
+.summary{display:flex;gap:12px;margin:12px 0}
+.card{background:#fff;border-radius:6px;padding:10px 16px;box-shadow:0 1px 2px rgba(0,0,0,.1)}
+.card b{display:block;font-size:20px}
+.status{font-weight:600;padding:2px 8px;border-radius:4px;font-size:12px}
+.status.passed{background:#dafbe1;color:#1a7f37}
+.status.failed{background:#ffebe9;color:#cf222e}
+.status.other,.status.flaky{background:#fff8c5;color:#9a6700}
+section.testset{background:#fff;border-radius:6px;margin:16px 0;padding:12px 16px;box-shadow:0 1px 2px rgba(0,0,0,.1)}
+section.testset h2{font-size:18px;margin:0 0 8px}
+details.test{border-top:1px solid #eaeef2;padding:6px 0}
+details.test summary{cursor:pointer;display:flex;gap:12px;align-items:center}
+details.test summary .name{font-weight:600;min-width:90px}
+details.test summary .req{font-family:monospace;color:#57606a;overflow:hidden;text-overflow:ellipsis;white-space:nowrap}
+.pair{display:grid;grid-template-columns:1fr 1fr;gap:12px;margin:8px 0}
+pre{background:#f6f8fa;border-radius:4px;padding:8px;margin:4px 0;overflow:auto;font-size:12px;white-space:pre-wrap;word-break:break-all}
+h4{margin:8px 0 4px;font-size:13px}
+table{border-collapse:collapse;width:100%;font-size:12px}
+td,th{border:1px solid #eaeef2;padding:3px 6px;text-align:left;vertical-align:top}
+table.diff td{font-family:monospace;white-space:pre-wrap;word-break:break-all;width:50%}
+tr.changed td.exp,tr.removed td.exp{background:#ffebe9}
+tr.changed td.act,tr.added td.act{background:#dafbe1}
+tr.noise td{color:#8c959f}
+</style>
+</head>
+<body>
+<header><h1>Keploy test report &middot; {{.TestRunID}}</h1><small>Generated {{.Generated}}</small></header>
+<main>
+<div class="summary">
+<div class="card">Status<b><span class="status {{if eq .Status "PASSED"}}passed{{else}}failed{{end}}">{{.Status}}</span></b></div>
+<div class="card">Total<b>{{.Summary.Total}}</b></div>
+<div class="card">Passed<b>{{.Summary.Passed}}</b></div>
+<div class="card">Failed<b>{{.Summary.Failed}}</b></div>
+{{if .Summary.Flaky}}<div class="card">Flaky<b>{{.Summary.Flaky}}</b></div>{{end}}
+</div>
+{{range .TestSets}}
+<section class="testset">
+<h2>{{.Name}} <span class="status {{if eq .Status "PASSED"}}passed{{else if eq .Status "FAILED"}}failed{{else}}other{{end}}">{{.Status}}</span></h2>
+<div>Total: {{.Summary.Total}} &middot; Passed: {{.Summary.Passed}} &middot; Failed: {{.Summary.Failed}}{{if .Summary.Flaky}} &middot; Flaky: {{.Summary.Flaky}}{{end}}</div>
+{{range .Tests}}
+<details class="test"{{if .Failure}} open{{end}}>
+<summary><span class="status {{lower .Status}}">{{.Status}}</span><span class="name">{{.Name}}</span><span class="req">{{.Request.Method}} {{.Request.URL}}</span><span>{{.Duration}}</span></summary>
+<div class="pair">
+<div>
+<h4>Request</h4>
+<pre>{{.Request.Method}} {{.Request.URL}}
+{{range .ReqHeaders}}{{.Key}}: {{.Value}}
+{{end}}</pre>
+{{if .Request.Body}}<pre>{{.Request.Body}}</pre>{{end}}
+</div>
+<div>
+<h4>Response</h4>
+<pre>{{.Response.StatusCode}} {{.Response.StatusMessage}}
+{{range .ResHeaders}}{{.Key}}: {{.Value}}
+{{end}}</pre>
+{{if .Response.Body}}<pre>{{.Response.Body}}</pre>{{end}}
+</div>
+</div>
+{{with .Failure}}
+{{if .StatusCode}}<h4>Status code</h4>
+<table class="diff"><tr><th>Expected</th><th>Actual</th></tr><tr class="changed"><td class="exp">{{.StatusCode.Expected}}</td><td class="act">{{.StatusCode.Actual}}</td></tr></table>{{end}}
+{{if .Headers}}<h4>Headers</h4>
+<table class="diff"><tr><th>Expected</th><th>Actual</th></tr>
+{{range .Headers}}<tr class="changed"><td class="exp">{{.Key}}: {{join .Expected ", "}}</td><td class="act">{{.Key}}: {{join .Actual ", "}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Assertions}}<h4>Assertions</h4>
+<table class="diff"><tr><th>Expected</th><th>Actual</th></tr>
+{{range .Assertions}}<tr class="changed"><td class="exp">{{.Path}} {{.Check}} {{.Expected}}</td><td class="act">{{.Actual}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Dependencies}}<h4>Outgoing calls</h4>
+<table><tr><th>Difference</th></tr>
+{{range .Dependencies}}<tr class="changed"><td>{{depMessage .}}{{with depPayload .}}<pre>{{.}}</pre>{{end}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}
+{{range .BodyDiffs}}<h4>Body ({{.Type}})</h4>
+<table class="diff"><tr><th>Expected</th><th>Actual</th></tr>
+{{range .Rows}}<tr class="{{.Kind}}"><td class="exp">{{.Expected}}</td><td class="act">{{.Actual}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Faults}}<h4>Injected faults</h4>
+<table><tr><th>Fault</th></tr>
+{{range .Faults}}<tr><td>{{faultMessage .}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Failure}}<h4>Consumed mocks</h4>
+{{if .ConsumedMocks}}<table><tr><th>Mock</th><th>Mock file</th></tr>{{$path := .MockPath}}
+{{range .ConsumedMocks}}<tr><td>{{.}}</td><td>{{$path}}</td></tr>
+{{end}}</table>{{else}}<div>No mocks were consumed by this test case.</div>{{end}}{{end}}
+</details>
+{{end}}
+</section>
+{{end}}
+</main>
+</body>
+</html>
+`))
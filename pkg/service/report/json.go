@@ -0,0 +1,115 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+func init() {
+	RegisterExporter(&JSON{})
+}
+
+// JSONSchemaVersion is bumped whenever a breaking change is made to the exported json report.
+const JSONSchemaVersion = "v1"
+
+// JSON exports the test run as a json document with a stable schema.
+type JSON struct{}
+
+type JSONReport struct {
+	SchemaVersion string        `json:"schemaVersion"`
+	TestRunID     string        `json:"testRunId"`
+	Status        string        `json:"status"`
+	Summary       JSONSummary   `json:"summary"`
+	TestSets      []JSONTestSet `json:"testSets"`
+}
+
+type JSONSummary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+	// Flaky counts the tests which passed only on a retry, they are included in Passed
+	Flaky int `json:"flaky"`
+}
+
+type JSONTestSet struct {
+	Name    string      `json:"name"`
+	Status  string      `json:"status"`
+	Summary JSONSummary `json:"summary"`
+	Tests   []JSONTest  `json:"tests"`
+}
+
+type JSONTest struct {
+	Name        string            `json:"name"`
+	Kind        models.Kind       `json:"kind"`
+	Status      models.TestStatus `json:"status"`
+	StartedAt   int64             `json:"startedAt"`
+	CompletedAt int64             `json:"completedAt"`
+	Request     JSONRequest       `json:"request"`
+	Failure     *Failure          `json:"failure,omitempty"`
+	// Faults are the faults of the chaos config of the test set injected into the mocks of the test
+	Faults []models.InjectedFault `json:"faults,omitempty"`
+}
+
+type JSONRequest struct {
+	Method models.Method `json:"method"`
+	URL    string        `json:"url"`
+}
+
+func (j *JSON) Format() string {
+	return "json"
+}
+
+func (j *JSON) FileName() string {
+	return "report.json"
+}
+
+func (j *JSON) Export(w io.Writer, testRunID string, reports []*models.TestReport) error {
+	report := JSONReport{
+		SchemaVersion: JSONSchemaVersion,
+		TestRunID:     testRunID,
+		Status:        testRunStatus(reports),
+		TestSets:      []JSONTestSet{},
+	}
+	for _, r := range reports {
+		testSet := JSONTestSet{
+			Name:   r.TestSet,
+			Status: r.Status,
+			Summary: JSONSummary{
+				Total:  r.Total,
+				Passed: r.Success,
+				Failed: r.Failure,
+				Flaky:  len(r.Flaky),
+			},
+			Tests: []JSONTest{},
+		}
+		if testSet.Name == "" {
+			testSet.Name = r.Name
+		}
+		for _, t := range r.Tests {
+			testSet.Tests = append(testSet.Tests, JSONTest{
+				Name:        t.TestCaseID,
+				Kind:        t.Kind,
+				Status:      t.Status,
+				StartedAt:   t.Started,
+				CompletedAt: t.Completed,
+				Request: JSONRequest{
+					Method: t.Req.Method,
+					URL:    t.Req.URL,
+				},
+				Failure: GetFailure(t),
+				Faults:  t.Faults,
+			})
+		}
+		report.Summary.Total += r.Total
+		report.Summary.Passed += r.Success
+		report.Summary.Failed += r.Failure
+		report.Summary.Flaky += len(r.Flaky)
+		report.TestSets = append(report.TestSets, testSet)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
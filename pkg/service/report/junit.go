@@ -0,0 +1,135 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+func init() {
+	RegisterExporter(&JUnit{})
+}
+
+// JUnit exports the test run as a JUnit XML report, one testsuite per test set.
+type JUnit struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemErr string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Type     string `xml:"type,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func (j *JUnit) Format() string {
+	return "junit"
+}
+
+func (j *JUnit) FileName() string {
+	return "junit.xml"
+}
+
+func (j *JUnit) Export(w io.Writer, testRunID string, reports []*models.TestReport) error {
+	suites := junitTestSuites{Name: testRunID}
+	var runTime int64
+	for _, r := range reports {
+		suite := junitTestSuite{
+			Name:  r.TestSet,
+			Tests: r.Total,
+		}
+		if suite.Name == "" {
+			suite.Name = r.Name
+		}
+		var suiteTime, started int64
+		for _, t := range r.Tests {
+			duration := t.Completed - t.Started
+			suiteTime += duration
+			if started == 0 || t.Started < started {
+				started = t.Started
+			}
+			tc := junitTestCase{
+				Name:      t.TestCaseID,
+				ClassName: suite.Name,
+				Time:      seconds(duration),
+			}
+			if f := GetFailure(t); f != nil {
+				tc.Failure = &junitFailure{
+					Message:  f.Message(),
+					Type:     string(t.Status),
+					Contents: fmt.Sprintf("%s %s\n%s", t.Req.Method, t.Req.URL, f.String()),
+				}
+				suite.Failures++
+			}
+			for _, fault := range t.Faults {
+				tc.SystemOut += faultMessage(fault) + "\n"
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		// tests which were not run (eg: the application halted) are reported as skipped
+		if suite.Tests > len(r.Tests) {
+			suite.Skipped = suite.Tests - len(r.Tests)
+		}
+		if r.Status != string(models.TestSetStatusPassed) && r.Status != string(models.TestSetStatusFailed) {
+			suite.Errors = 1
+			suite.SystemErr = fmt.Sprintf("test set finished with status %s", r.Status)
+		}
+		if started != 0 {
+			suite.Timestamp = time.Unix(started, 0).UTC().Format(time.RFC3339)
+		}
+		suite.Time = seconds(suiteTime)
+		runTime += suiteTime
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+	suites.Time = seconds(runTime)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// seconds formats the duration in seconds as expected by the junit schema
+func seconds(s int64) string {
+	return fmt.Sprintf("%.3f", float64(s))
+}
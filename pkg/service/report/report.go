@@ -0,0 +1,80 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Report struct {
+	logger     *zap.Logger
+	reportDB   ReportDB
+	reportPath string
+}
+
+func New(logger *zap.Logger, reportDB ReportDB, reportPath string) Service {
+	return &Report{
+		logger:     logger,
+		reportDB:   reportDB,
+		reportPath: reportPath,
+	}
+}
+
+func (r *Report) Export(ctx context.Context, testRunID string, formats []string) error {
+	testRunID, err := r.getTestRunID(ctx, testRunID)
+	if err != nil {
+		return err
+	}
+	reports, err := r.getReports(ctx, testRunID)
+	if err != nil {
+		return err
+	}
+	return WriteReports(ctx, r.logger, filepath.Join(r.reportPath, testRunID), testRunID, reports, formats)
+}
+
+// getTestRunID returns the given test run id or the latest test run when it is empty
+func (r *Report) getTestRunID(ctx context.Context, testRunID string) (string, error) {
+	testRunIDs, err := r.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the test runs")
+		return "", err
+	}
+	if len(testRunIDs) == 0 {
+		return "", fmt.Errorf("no test runs found, please run the testcases using keploy test command")
+	}
+	if testRunID == "" {
+		return pkg.LastID(testRunIDs, models.TestRunTemplateName), nil
+	}
+	for _, id := range testRunIDs {
+		if id == testRunID {
+			return testRunID, nil
+		}
+	}
+	return "", fmt.Errorf("test run %s not found", testRunID)
+}
+
+func (r *Report) getReports(ctx context.Context, testRunID string) ([]*models.TestReport, error) {
+	testSetIDs, err := r.reportDB.GetAllTestSetIDs(ctx, testRunID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the test sets of the test run", zap.String("testRunID", testRunID))
+		return nil, err
+	}
+	reports := make([]*models.TestReport, 0, len(testSetIDs))
+	for _, testSetID := range testSetIDs {
+		report, err := r.reportDB.GetReport(ctx, testRunID, testSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the report", zap.String("testRunID", testRunID), zap.String("testSetID", testSetID))
+			return nil, err
+		}
+		if report.TestSet == "" {
+			report.TestSet = testSetID
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
@@ -0,0 +1,35 @@
+// Package report provides the services to export and inspect the reports of the test runs.
+package report
+
+import (
+	"context"
+	"io"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Export writes the reports of the test run in the given formats (eg: junit, json).
+	Export(ctx context.Context, testRunID string, formats []string) error
+	// Diff compares the results of the head test run with the base test run and writes the comparison
+	// as json in the report directory of the head test run.
+	Diff(ctx context.Context, baseRunID, headRunID string) (*RunDiff, error)
+	// Flaky lists the test cases whose result flips across the last runs test runs (all of them when runs
+	// is 0) along with the ones which passed only on a retry.
+	Flaky(ctx context.Context, runs int) (*FlakyReport, error)
+}
+
+type ReportDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetAllTestSetIDs(ctx context.Context, testRunID string) ([]string, error)
+	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
+}
+
+// Exporter converts the reports of a test run into a format which can be consumed by other tools (eg: CI).
+type Exporter interface {
+	// Format is the name with which the exporter is selected
+	Format() string
+	// FileName is the name of the file written in the report directory of the test run
+	FileName() string
+	Export(w io.Writer, testRunID string, reports []*models.TestReport) error
+}
@@ -0,0 +1,227 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// Failure holds the differences between the expected and the actual response of a failed test case.
+type Failure struct {
+	StatusCode *StatusDiff  `json:"statusCode,omitempty"`
+	Headers    []HeaderDiff `json:"headers,omitempty"`
+	Body       []BodyDiff   `json:"body,omitempty"`
+	// Assertions are the failed checks of the assertions of the test case
+	Assertions []models.AssertionResult `json:"assertions,omitempty"`
+	// Dependencies are the recorded mocks which were not consumed and the outgoing calls matching no mock
+	Dependencies []models.DepResult `json:"dependencies,omitempty"`
+}
+
+type StatusDiff struct {
+	Expected int `json:"expected"`
+	Actual   int `json:"actual"`
+}
+
+type HeaderDiff struct {
+	Key      string   `json:"key"`
+	Expected []string `json:"expected"`
+	Actual   []string `json:"actual"`
+}
+
+type BodyDiff struct {
+	Type     models.BodyType `json:"type"`
+	Expected string          `json:"expected"`
+	Actual   string          `json:"actual"`
+	// Diff is the line based diff of the json bodies, lines prefixed by - are expected and + are actual.
+	Diff string `json:"diff,omitempty"`
+}
+
+// GetFailure returns the differences of the test result, nil when the test did not fail.
+func GetFailure(result models.TestResult) *Failure {
+	if result.Status != models.TestStatusFailed {
+		return nil
+	}
+	f := &Failure{}
+	if !result.Result.StatusCode.Normal {
+		f.StatusCode = &StatusDiff{
+			Expected: result.Result.StatusCode.Expected,
+			Actual:   result.Result.StatusCode.Actual,
+		}
+	}
+	for _, h := range result.Result.HeadersResult {
+		if h.Normal {
+			continue
+		}
+		f.Headers = append(f.Headers, HeaderDiff{
+			Key:      h.Expected.Key,
+			Expected: h.Expected.Value,
+			Actual:   h.Actual.Value,
+		})
+	}
+	sort.SliceStable(f.Headers, func(i, j int) bool {
+		return f.Headers[i].Key < f.Headers[j].Key
+	})
+	for _, b := range result.Result.BodyResult {
+		if b.Normal {
+			continue
+		}
+		body := BodyDiff{
+			Type:     b.Type,
+			Expected: b.Expected,
+			Actual:   b.Actual,
+		}
+		if json.Valid([]byte(b.Expected)) && json.Valid([]byte(b.Actual)) {
+			if diff, err := pkg.CalculateJSONDiffs([]byte(b.Expected), []byte(b.Actual)); err == nil {
+				body.Diff = diff
+			}
+		}
+		f.Body = append(f.Body, body)
+	}
+	for _, a := range result.Result.AssertionResult {
+		if !a.Normal {
+			f.Assertions = append(f.Assertions, a)
+		}
+	}
+	for _, d := range result.Result.DepResult {
+		for _, m := range d.Meta {
+			if !m.Normal {
+				f.Dependencies = append(f.Dependencies, d)
+				break
+			}
+		}
+	}
+	return f
+}
+
+// Message returns a one line summary of the failure.
+func (f *Failure) Message() string {
+	var parts []string
+	if f.StatusCode != nil {
+		parts = append(parts, fmt.Sprintf("status code mismatch (expected %d, actual %d)", f.StatusCode.Expected, f.StatusCode.Actual))
+	}
+	if len(f.Headers) > 0 {
+		keys := make([]string, 0, len(f.Headers))
+		for _, h := range f.Headers {
+			keys = append(keys, h.Key)
+		}
+		parts = append(parts, "header mismatch ("+strings.Join(keys, ", ")+")")
+	}
+	if len(f.Body) > 0 {
+		parts = append(parts, "body mismatch")
+	}
+	if len(f.Assertions) > 0 {
+		parts = append(parts, fmt.Sprintf("%d assertions failed", len(f.Assertions)))
+	}
+	if len(f.Dependencies) > 0 {
+		unmocked := 0
+		for _, d := range f.Dependencies {
+			if isUnmocked(d) {
+				unmocked++
+			}
+		}
+		if unmocked > 0 {
+			parts = append(parts, fmt.Sprintf("%d unmocked dependency calls", unmocked))
+		}
+		if len(f.Dependencies) > unmocked {
+			parts = append(parts, fmt.Sprintf("%d dependency mismatches", len(f.Dependencies)-unmocked))
+		}
+	}
+	if len(parts) == 0 {
+		return "test failed"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// String returns the complete differences of the failure in plain text.
+func (f *Failure) String() string {
+	var sb strings.Builder
+	if f.StatusCode != nil {
+		fmt.Fprintf(&sb, "status code:\n  expected: %d\n  actual:   %d\n", f.StatusCode.Expected, f.StatusCode.Actual)
+	}
+	if len(f.Headers) > 0 {
+		sb.WriteString("headers:\n")
+		for _, h := range f.Headers {
+			fmt.Fprintf(&sb, "  %s:\n    expected: %s\n    actual:   %s\n", h.Key, strings.Join(h.Expected, ", "), strings.Join(h.Actual, ", "))
+		}
+	}
+	for _, b := range f.Body {
+		fmt.Fprintf(&sb, "body (%s):\n", b.Type)
+		if b.Diff != "" {
+			sb.WriteString(b.Diff)
+			if !strings.HasSuffix(b.Diff, "\n") {
+				sb.WriteString("\n")
+			}
+			continue
+		}
+		fmt.Fprintf(&sb, "  expected: %s\n  actual:   %s\n", b.Expected, b.Actual)
+	}
+	if len(f.Assertions) > 0 {
+		sb.WriteString("assertions:\n")
+		for _, a := range f.Assertions {
+			fmt.Fprintf(&sb, "  %s %s:\n    expected: %s\n    actual:   %s\n", a.Path, a.Check, a.Expected, a.Actual)
+		}
+	}
+	if len(f.Dependencies) > 0 {
+		sb.WriteString("dependencies:\n")
+		for _, d := range f.Dependencies {
+			fmt.Fprintf(&sb, "  %s\n", depMessage(d))
+			if payload := depPayload(d); payload != "" {
+				fmt.Fprintf(&sb, "    request: %s\n", strings.ReplaceAll(payload, "\n", "\n             "))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// depMessage describes the difference of an outgoing call of the test case.
+func depMessage(d models.DepResult) string {
+	for _, m := range d.Meta {
+		if m.Key == models.DepUnmatched {
+			if m.Expected == "" {
+				return fmt.Sprintf("unmocked dependency call: %s call to %s matched no mock", d.Type, d.Name)
+			}
+			return fmt.Sprintf("unmocked dependency call: %s call to %s matched no mock, the closest one is %s", d.Type, d.Name, m.Expected)
+		}
+	}
+	return fmt.Sprintf("%s mock %s was not consumed", d.Type, d.Name)
+}
+
+// isUnmocked tells whether the outgoing call of the test case matched no mock.
+func isUnmocked(d models.DepResult) bool {
+	for _, m := range d.Meta {
+		if m.Key == models.DepUnmatched {
+			return true
+		}
+	}
+	return false
+}
+
+// depPayload returns the decoded request of the outgoing call which matched no mock.
+func depPayload(d models.DepResult) string {
+	for _, m := range d.Meta {
+		if m.Key == models.DepUnmatched {
+			return strings.TrimRight(strings.ReplaceAll(m.Actual, "\r\n", "\n"), "\n")
+		}
+	}
+	return ""
+}
+
+// faultMessage describes a fault injected into a mock served to the test case.
+func faultMessage(f models.InjectedFault) string {
+	return fmt.Sprintf("%s fault %s injected into the %s mock %s of %s", f.Type, f.Fault, f.Kind, f.Mock, f.Destination)
+}
+
+// testRunStatus derives the status of the test run from the status of its test sets.
+func testRunStatus(reports []*models.TestReport) string {
+	status := string(models.TestSetStatusPassed)
+	for _, r := range reports {
+		if r.Status != string(models.TestSetStatusPassed) {
+			return string(models.TestSetStatusFailed)
+		}
+	}
+	return status
+}
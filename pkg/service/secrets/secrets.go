@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Secrets struct {
+	logger *zap.Logger
+	testDB TestDB
+	mockDB MockDB
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB) Service {
+	return &Secrets{
+		logger: logger,
+		testDB: testDB,
+		mockDB: mockDB,
+	}
+}
+
+func (s *Secrets) Rotate(ctx context.Context, newKeyFile string) error {
+	next, err := s.loadNewKey(newKeyFile)
+	if err != nil {
+		utils.LogError(s.logger, err, "failed to load the new encryption key", zap.String("keyFile", newKeyFile))
+		return err
+	}
+
+	testSetIDs, err := s.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(s.logger, err, "failed to get the test sets")
+		return err
+	}
+
+	var testCount, mockCount int
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		tcs, err := s.testDB.RotateTestCases(ctx, testSetID, next)
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to re-encrypt the testcases", zap.String("testSetID", testSetID))
+			return err
+		}
+		mocks, err := s.mockDB.RotateMocks(ctx, testSetID, next)
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to re-encrypt the mocks", zap.String("testSetID", testSetID))
+			return err
+		}
+		if len(tcs) == 0 && len(mocks) == 0 {
+			s.logger.Debug("test set is already encrypted with the new key", zap.String("testSetID", testSetID))
+			continue
+		}
+		s.logger.Info("re-encrypted the test set with the new key", zap.String("testSetID", testSetID), zap.Int("testcases", len(tcs)), zap.Int("mocks", len(mocks)))
+		testCount += len(tcs)
+		mockCount += len(mocks)
+	}
+	// the shared mock pool is not a test set, its mocks are re-encrypted after the ones of the test sets
+	mocks, err := s.mockDB.RotateMocks(ctx, yaml.SharedMocksDir, next)
+	if err != nil {
+		utils.LogError(s.logger, err, "failed to re-encrypt the shared mocks")
+		return err
+	}
+	mockCount += len(mocks)
+
+	s.logger.Info("rotated the encryption key of the testcases and mocks, configure the new key to keep reading them",
+		zap.String("keyId", next.KeyID()), zap.String("keyFile", newKeyFile), zap.Int("testcases", testCount), zap.Int("mocks", mockCount))
+	return nil
+}
+
+// loadNewKey reads the key file, a new random key is written to the file when it does not exist.
+func (s *Secrets) loadNewKey(keyFile string) (*yaml.Cipher, error) {
+	if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+		key, err := yaml.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the encryption key: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(keyFile), 0o700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(keyFile, []byte(key+"\n"), 0o600); err != nil {
+			return nil, fmt.Errorf("failed to write the encryption key file: %v", err)
+		}
+		s.logger.Info("generated a new encryption key", zap.String("keyFile", keyFile))
+	}
+	key, err := yaml.LoadKey("", keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.NewCipher(key)
+}
@@ -0,0 +1,23 @@
+// Package secrets provides the service to manage the key which encrypts the recorded testcases and mocks.
+package secrets
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+)
+
+type Service interface {
+	// Rotate re-encrypts the testcases and mocks of the test sets with the key stored in newKeyFile.
+	// A new key is generated into the file when it does not exist.
+	Rotate(ctx context.Context, newKeyFile string) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	RotateTestCases(ctx context.Context, testSetID string, next *yaml.Cipher) ([]string, error)
+}
+
+type MockDB interface {
+	RotateMocks(ctx context.Context, testSetID string, next *yaml.Cipher) ([]string, error)
+}
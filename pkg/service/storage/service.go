@@ -0,0 +1,38 @@
+// Package storage provides the services to manage the storage backends of the recorded data.
+package storage
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Convert copies the testcases, mocks and reports from one storage backend to the other.
+	Convert(ctx context.Context, from string, to string) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	UpdateTestCase(ctx context.Context, testCase *models.TestCase, testSetID string) error
+}
+
+type MockDB interface {
+	GetAllMocks(ctx context.Context, testSetID string) ([]*models.Mock, error)
+	ReplaceMocks(ctx context.Context, testSetID string, mocks []*models.Mock) error
+}
+
+type ReportDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetAllTestSetIDs(ctx context.Context, testRunID string) ([]string, error)
+	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
+	InsertReport(ctx context.Context, testRunID string, testSetID string, testReport *models.TestReport) error
+}
+
+// Store groups the databases of a storage backend.
+type Store struct {
+	TestDB   TestDB
+	MockDB   MockDB
+	ReportDB ReportDB
+}
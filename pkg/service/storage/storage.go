@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Storage struct {
+	logger *zap.Logger
+	stores map[string]Store
+}
+
+func New(logger *zap.Logger, stores map[string]Store) Service {
+	return &Storage{
+		logger: logger,
+		stores: stores,
+	}
+}
+
+func (s *Storage) Convert(ctx context.Context, from string, to string) error {
+	if from == to {
+		return fmt.Errorf("source and destination storage are the same: %s", from)
+	}
+	src, ok := s.stores[from]
+	if !ok {
+		return fmt.Errorf("unsupported source storage: %s", from)
+	}
+	dst, ok := s.stores[to]
+	if !ok {
+		return fmt.Errorf("unsupported destination storage: %s", to)
+	}
+
+	testSetIDs, err := src.TestDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(s.logger, err, "failed to get the test sets", zap.String("storage", from))
+		return err
+	}
+
+	var testCount, mockCount, reportCount int
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		tcs, err := src.TestDB.GetTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to get the testcases", zap.String("testSetID", testSetID))
+			return err
+		}
+		for _, tc := range tcs {
+			// the name of the testcase is preserved by the destination storage
+			err = dst.TestDB.UpdateTestCase(ctx, tc, testSetID)
+			if err != nil {
+				utils.LogError(s.logger, err, "failed to write the testcase", zap.String("testSetID", testSetID), zap.String("testcase", tc.Name))
+				return err
+			}
+		}
+		testCount += len(tcs)
+
+		mocks, err := src.MockDB.GetAllMocks(ctx, testSetID)
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to get the mocks", zap.String("testSetID", testSetID))
+			return err
+		}
+		if len(mocks) > 0 {
+			err = dst.MockDB.ReplaceMocks(ctx, testSetID, mocks)
+			if err != nil {
+				utils.LogError(s.logger, err, "failed to write the mocks", zap.String("testSetID", testSetID))
+				return err
+			}
+		}
+		mockCount += len(mocks)
+	}
+
+	testRunIDs, err := src.ReportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		utils.LogError(s.logger, err, "failed to get the test runs", zap.String("storage", from))
+		return err
+	}
+	for _, testRunID := range testRunIDs {
+		reportSetIDs, err := src.ReportDB.GetAllTestSetIDs(ctx, testRunID)
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to get the reports of test run", zap.String("testRunID", testRunID))
+			return err
+		}
+		for _, testSetID := range reportSetIDs {
+			report, err := src.ReportDB.GetReport(ctx, testRunID, testSetID)
+			if err != nil {
+				utils.LogError(s.logger, err, "failed to get the report", zap.String("testRunID", testRunID), zap.String("testSetID", testSetID))
+				return err
+			}
+			err = dst.ReportDB.InsertReport(ctx, testRunID, testSetID, report)
+			if err != nil {
+				utils.LogError(s.logger, err, "failed to write the report", zap.String("testRunID", testRunID), zap.String("testSetID", testSetID))
+				return err
+			}
+			reportCount++
+		}
+	}
+
+	s.logger.Info("converted the recorded data", zap.String("from", from), zap.String("to", to), zap.Int("testsets", len(testSetIDs)), zap.Int("testcases", testCount), zap.Int("mocks", mockCount), zap.Int("reports", reportCount))
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
@@ -14,6 +15,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/yudai/gojsondiff"
+	"github.com/yudai/gojsondiff/formatter"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
@@ -77,6 +80,18 @@ func IsTime(stringDate string) bool {
 	return false
 }
 
+// ParseTime parses a date in any of the formats recognised by IsTime.
+func ParseTime(stringDate string) (time.Time, bool) {
+	date := strings.TrimSpace(stringDate)
+	for _, dateFormat := range dateFormats {
+		t, err := time.Parse(dateFormat, date)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
 func SimulateHTTP(ctx context.Context, tc models.TestCase, testSet string, logger *zap.Logger, apiTimeout uint64) (*models.HTTPResp, error) {
 	var resp *models.HTTPResp
 
@@ -271,3 +286,82 @@ var (
 		time.TimeOnly,
 	}
 )
+
+/* CalculateJSONDiffs will perform the calculation of the diffs, returning a string that
+ * containes the lines that does not match represented by either a
+ * minus or add symbol followed by the respective line.
+ */
+func CalculateJSONDiffs(json1 []byte, json2 []byte) (string, error) {
+	var diff = gojsondiff.New()
+	dObj, err := diff.Compare(json1, json2)
+	if err != nil {
+		return "", err
+	}
+
+	var jsonObject map[string]interface{}
+	err = json.Unmarshal([]byte(json1), &jsonObject)
+	if err != nil {
+		return "", err
+	}
+
+	diffString, _ := formatter.NewAsciiFormatter(jsonObject, formatter.AsciiFormatterConfig{
+		ShowArrayIndex: true,
+		Coloring:       false, // We will color our way
+	}).Format(dObj)
+
+	return diffString, nil
+}
+
+// DiffKind is the kind of a line of the json diff.
+type DiffKind string
+
+// kinds of the lines of the json diff
+const (
+	DiffSame    DiffKind = "same"
+	DiffChanged DiffKind = "changed"
+	DiffRemoved DiffKind = "removed"
+	DiffAdded   DiffKind = "added"
+)
+
+// DiffLine is a line of the json diff split into its expected and actual side. The sides of the changed,
+// removed and added lines are without their - or + symbol, the side missing from a removed or an added
+// line is empty.
+type DiffLine struct {
+	Expected string
+	Actual   string
+	Kind     DiffKind
+	// Noisy is set when the changed line contains a noisy key, its difference is expected
+	Noisy bool
+}
+
+// SplitJSONDiff separates the lines of the diff returned by CalculateJSONDiffs into the expected (-) and
+// the actual (+) side. A removed line followed by an added one is a changed line.
+func SplitJSONDiff(diff string, noise map[string][]string) []DiffLine {
+	var lines []DiffLine
+	diffLines := strings.Split(diff, "\n")
+	for i := 0; i < len(diffLines); i++ {
+		line := diffLines[i]
+		if len(line) == 0 {
+			continue
+		}
+		noisy := false
+		for key := range noise {
+			if strings.Contains(line, key) {
+				noisy = true
+				break
+			}
+		}
+		switch {
+		case line[0] == '-' && i+1 < len(diffLines) && len(diffLines[i+1]) > 0 && diffLines[i+1][0] == '+':
+			lines = append(lines, DiffLine{Expected: line[1:], Actual: diffLines[i+1][1:], Kind: DiffChanged, Noisy: noisy})
+			i++
+		case line[0] == '-':
+			lines = append(lines, DiffLine{Expected: line[1:], Kind: DiffRemoved, Noisy: noisy})
+		case line[0] == '+':
+			lines = append(lines, DiffLine{Actual: line[1:], Kind: DiffAdded, Noisy: noisy})
+		default:
+			lines = append(lines, DiffLine{Expected: line, Actual: line, Kind: DiffSame})
+		}
+	}
+	return lines
+}